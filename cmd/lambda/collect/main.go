@@ -5,14 +5,28 @@
 // 全ソースから記事を収集し、Notion DBに保存するLambda関数
 //
 // 環境変数:
-//   - NOTION_TOKEN:       Notion API Token (必須)
-//   - NOTION_DATABASE_ID: NotionデータベースID (必須)
+//   - STORE_BACKEND:      保存先 notion / file (デフォルト: notion)
+//   - STORE_PATH:         file の保存先 (デフォルト: headlines_store.json)
+//   - NOTION_TOKEN:       Notion API Token (notion の場合は必須)
+//   - NOTION_DATABASE_ID: NotionデータベースID (notion の場合は必須)
 //   - SOURCES:            収集するソース (デフォルト: all-free)
 //   - PER_SOURCE:         ソースあたりの記事数 (デフォルト: 100)
 //   - HOURS_BACK:         何時間以内の記事を取得するか (デフォルト: 24、0=フィルタなし)
 //   - EMAIL_FROM:         エラー通知メール送信元 (任意)
-//   - EMAIL_PASSWORD:     Gmailアプリパスワード (任意)
-//   - EMAIL_TO:           エラー通知メール送信先 (任意)
+//   - EMAIL_PASSWORD:     SMTP認証のパスワード (任意、SMTP_AUTH=none の場合は不要)
+//   - SMTP_HOST 等:       SMTPサーバーの設定 (任意、デフォルト: Gmail)
+//   - EMAIL_TO:           エラー通知メール送信先 (任意、ERROR_EMAIL_TO で個別指定)
+//   - ERROR_REMIND_HOURS: 継続中の問題を再通知する間隔 (デフォルト: 24、error_notify.go)
+//   - ERROR_STATE_FILE:   エラー通知の状態ファイル (エラー通知を設定した場合は STATE_DIR かこちらが必須)
+//   - SOURCE_FILTERS_FILE: ソース別フィルタ式のJSONファイル (任意)
+//   - NOTION_CLIP_WORKERS: Notionへの並列クリップ数 (デフォルト: 3)
+//   - NOTION_RATE_LIMIT:  Notion APIの平均リクエスト数/秒 (デフォルト: 3)
+//   - ALERT_RULES_FILE:   収集直後に評価するアラート規則のJSON (任意、alerts.go)
+//   - ALERT_WEBHOOK_URL:  アラートのPOST先 (任意、ALERT_WEBHOOK_TOKEN でBearer認証)
+//   - ALERT_EMAIL_TO:     アラートメール送信先 (任意、デフォルト: EMAIL_TO)
+//   - ALERT_SUPPRESS_HOURS: 同じ記事を再び通知しない時間 (デフォルト: 72)
+//   - STATE_DIR:          アラート・エラー通知の状態ファイルの保存先 (EFS等の永続ストレージ)
+//   - ALERT_STATE_FILE:   アラートの状態ファイル (ALERT_RULES_FILE がある場合は STATE_DIR かこちらが必須)
 //
 // =============================================================================
 package main
@@ -33,14 +47,10 @@ import (
 
 // LambdaConfig は環境変数から読み込む設定
 type LambdaConfig struct {
-	Sources          string
-	PerSource        int
-	HoursBack        int // 何時間以内の記事を取得するか（0=フィルタなし）
-	NotionToken      string
-	NotionDatabaseID string
-	EmailFrom        string // エラー通知用（任意）
-	EmailPassword    string // エラー通知用（任意）
-	EmailTo          string // エラー通知用（任意）
+	Sources   string
+	PerSource int
+	HoursBack int                  // 何時間以内の記事を取得するか（0=フィルタなし）
+	Store     pipeline.StoreConfig // 保存先（STORE_BACKEND / STORE_PATH）
 }
 
 // Response はLambdaレスポンス
@@ -58,19 +68,36 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 	// 1. 環境変数から設定を読み込む
 	cfg := loadConfig()
 
-	// 環境変数の検証
-	if cfg.NotionToken == "" {
-		return Response{StatusCode: 400, Message: "NOTION_TOKEN is required"}, fmt.Errorf("NOTION_TOKEN is required")
-	}
-	if cfg.NotionDatabaseID == "" {
-		return Response{StatusCode: 400, Message: "NOTION_DATABASE_ID is required"}, fmt.Errorf("NOTION_DATABASE_ID is required")
+	// 保存先の検証（notion の場合は NOTION_TOKEN / NOTION_DATABASE_ID が必要）
+	store, err := pipeline.OpenHeadlineStore(cfg.Store)
+	if err != nil {
+		return Response{StatusCode: 400, Message: err.Error()}, err
 	}
 
-	log.Printf("Config: sources=%s, perSource=%d, hoursBack=%d", cfg.Sources, cfg.PerSource, cfg.HoursBack)
+	log.Printf("Config: sources=%s, perSource=%d, hoursBack=%d, store=%s", cfg.Sources, cfg.PerSource, cfg.HoursBack, store.Name())
 
 	// 2. 記事を収集
 	sources := parseSources(cfg.Sources)
 	headlineCfg := pipeline.DefaultHeadlineConfig()
+	sourceFilters, err := pipeline.LoadSourceFilters(os.Getenv("SOURCE_FILTERS_FILE"))
+	if err != nil {
+		log.Printf("Error loading source filters: %v", err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	headlineCfg.SourceFilters = sourceFilters
+
+	// アラートの設定（ALERT_RULES_FILE がある場合は状態ファイルの保存先が必須のため、収集前に検証する）
+	alertCfg, err := pipeline.AlertConfigFromEnv("")
+	if err != nil {
+		log.Printf("Error loading alert config: %v", err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	// エラー通知の設定（通知を設定した場合は状態ファイルの保存先が必須のため、同じく収集前に検証する）
+	errorCfg, err := pipeline.ErrorNotifierConfigFromEnv("")
+	if err != nil {
+		log.Printf("Error loading error notification config: %v", err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
 
 	result, err := pipeline.CollectFromSources(sources, cfg.PerSource, headlineCfg)
 	if err != nil {
@@ -79,13 +106,15 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 	}
 	headlines := result.Headlines
 
-	// エラーがあればログに記録し、メールで通知
+	// 収集直後にアラート規則を評価（ALERT_RULES_FILE がある場合のみ、失敗しても収集は続ける）
+	sendAlerts(ctx, alertCfg, headlines)
+
+	// エラーがあればログに記録（メール通知は保存後に notifyErrors でまとめて行う）
 	if len(result.Errors) > 0 {
 		log.Printf("WARNING: %d source(s) failed:", len(result.Errors))
 		for _, e := range result.Errors {
 			log.Printf("  %s", e)
 		}
-		sendErrorNotification(cfg, result.Errors, len(headlines))
 	}
 
 	log.Printf("Collected %d headlines (before time filter)", len(headlines))
@@ -97,6 +126,7 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 	}
 
 	if len(headlines) == 0 {
+		notifyErrors(ctx, errorCfg, result, nil)
 		return Response{
 			StatusCode: 200,
 			Message:    "No headlines collected",
@@ -105,27 +135,22 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 		}, nil
 	}
 
-	// 4. Notionに保存
-	clipper, err := pipeline.NewNotionClipper(cfg.NotionToken, cfg.NotionDatabaseID)
-	if err != nil {
-		log.Printf("Error creating Notion clipper: %v", err)
-		return Response{StatusCode: 500, Message: err.Error(), Collected: len(headlines)}, err
-	}
+	// 4. ストアに保存（Notionの場合は NOTION_CLIP_WORKERS で並列クリップ、レート制限に合わせて送信）
+	clipResult := store.SaveHeadlines(ctx, headlines)
+	clipped := clipResult.Clipped
 
-	clipped := 0
-	for _, h := range headlines {
-		if err := clipper.ClipHeadlineWithRelated(ctx, h); err != nil {
-			log.Printf("Warning: failed to clip headline '%s': %v", h.Title, err)
-			continue
-		}
-		clipped++
+	log.Printf("Saved %d headlines to %s: %s", clipped, store.Name(), clipResult.ThroughputSummary())
+	// 失敗は種類別（認証・入力値・レート制限・一時障害）にまとめてログに残す
+	for _, line := range clipResult.ErrorReport() {
+		log.Printf("  %s", line)
 	}
 
-	log.Printf("Clipped %d headlines to Notion", clipped)
+	// 5. エラー通知（新しい問題・復旧・ERROR_REMIND_HOURS ごとのリマインダーのみ送信）
+	notifyErrors(ctx, errorCfg, result, clipResult)
 
 	return Response{
 		StatusCode: 200,
-		Message:    fmt.Sprintf("Successfully collected %d headlines, clipped %d to Notion", len(headlines), clipped),
+		Message:    fmt.Sprintf("Successfully collected %d headlines, saved %d to %s", len(headlines), clipped, store.Name()),
 		Collected:  len(headlines),
 		Clipped:    clipped,
 	}, nil
@@ -153,14 +178,10 @@ func loadConfig() LambdaConfig {
 	}
 
 	return LambdaConfig{
-		Sources:          sources,
-		PerSource:        perSource,
-		HoursBack:        hoursBack,
-		NotionToken:      os.Getenv("NOTION_TOKEN"),
-		NotionDatabaseID: os.Getenv("NOTION_DATABASE_ID"),
-		EmailFrom:        os.Getenv("EMAIL_FROM"),
-		EmailPassword:    os.Getenv("EMAIL_PASSWORD"),
-		EmailTo:          os.Getenv("EMAIL_TO"),
+		Sources:   sources,
+		PerSource: perSource,
+		HoursBack: hoursBack,
+		Store:     pipeline.StoreConfigFromEnv(),
 	}
 }
 
@@ -181,36 +202,28 @@ func parseSources(sourcesRaw string) []string {
 	return result
 }
 
-// sendErrorNotification はエラー通知メールを送信する
-// EMAIL_FROM, EMAIL_PASSWORD, EMAIL_TO が設定されている場合のみ送信
-func sendErrorNotification(cfg LambdaConfig, errors []string, headlineCount int) {
-	if cfg.EmailFrom == "" || cfg.EmailPassword == "" || cfg.EmailTo == "" {
-		log.Println("Email env vars not set, skipping error notification email")
+// sendAlerts はアラート規則で記事を評価し、まだ通知していない記事を通知する
+func sendAlerts(ctx context.Context, alertCfg pipeline.AlertConfig, headlines []pipeline.Headline) {
+	if alertCfg.RulesFile == "" {
 		return
 	}
-
-	sender, err := pipeline.NewEmailSender(cfg.EmailFrom, cfg.EmailPassword, cfg.EmailTo)
+	result, err := pipeline.SendAlerts(ctx, headlines, alertCfg, time.Now())
 	if err != nil {
-		log.Printf("Failed to create email sender: %v", err)
-		return
+		log.Printf("WARNING: alerts: %v", err)
 	}
+	log.Printf("Alerts: %d matched, %d suppressed, %d sent %v", result.Matched, result.Suppressed, result.Sent, result.Channels)
+}
 
-	subject := fmt.Sprintf("[Carbon Relay] %d source(s) failed - %s",
-		len(errors), time.Now().Format("2006-01-02 15:04"))
-
-	var body strings.Builder
-	body.WriteString("Carbon Relay source collection errors:\n\n")
-	for _, e := range errors {
-		body.WriteString("  " + e + "\n")
+// notifyErrors は収集・保存の問題をエラー通知に渡す（error_notify.go、インシデントは STATE_DIR に記録）
+func notifyErrors(ctx context.Context, errorCfg pipeline.ErrorNotifierConfig, result *pipeline.CollectResult, clipResult *pipeline.NotionClipResult) {
+	report := pipeline.RunReport{
+		Job:     pipeline.ErrorJobCollect,
+		Collect: result,
+		Store:   clipResult,
 	}
-	body.WriteString(fmt.Sprintf("\nSuccessfully collected: %d headlines\n", headlineCount))
-	body.WriteString(fmt.Sprintf("Timestamp: %s\n", time.Now().Format(time.RFC3339)))
-
-	msg := sender.BuildEmailMessage(subject, body.String())
-	if err := sender.SendWithRetry(msg); err != nil {
-		log.Printf("Failed to send error notification email: %v", err)
-	} else {
-		log.Println("Error notification email sent")
+	// 通知に失敗しても収集・保存の結果は返す
+	if err := pipeline.NotifyErrors(ctx, report, errorCfg, time.Now()); err != nil {
+		log.Printf("WARNING: error notification: %v", err)
 	}
 }
 
@@ -2,16 +2,34 @@
 // Lambda: メール送信
 // =============================================================================
 //
-// Notion DBから記事を取得し、メール送信するLambda関数
+// ストア（Notion DBまたはファイル）から記事を取得し、メール送信するLambda関数
 //
 // 環境変数:
-//   - NOTION_TOKEN:       Notion API Token (必須)
-//   - NOTION_DATABASE_ID: NotionデータベースID (必須)
+//   - STORE_BACKEND:      読み込み元 notion / file (デフォルト: notion)
+//   - STORE_PATH:         file の保存先 (デフォルト: headlines_store.json)
+//   - NOTION_TOKEN:       Notion API Token (notion の場合は必須)
+//   - NOTION_DATABASE_ID: NotionデータベースID (notion の場合は必須)
 //   - EMAIL_FROM:         送信元メールアドレス (必須)
-//   - EMAIL_PASSWORD:     Gmailアプリパスワード (必須)
+//   - EMAIL_PASSWORD:     SMTP認証のパスワード (SMTPで SMTP_AUTH=none 以外は必須)
+//   - SMTP_HOST 等:       SMTPサーバーの設定 (任意、デフォルト: Gmail、email_smtp.go)
+//   - MAILER 等:          配送先（smtp/file/maildir/http、デフォルト: smtp、mailer.go）
 //   - EMAIL_TO:           送信先メールアドレス (必須)
 //   - DAYS_BACK:          取得期間（日数、デフォルト: 1）
 //   - EMAIL_TYPE:         メールタイプ（full/short、デフォルト: full）
+//   - EMAIL_LANGUAGE:     ダイジェストに含める言語（ja/en/all、デフォルト: all）
+//   - EMAIL_TEMPLATE_DIR: メールテンプレートの差し替え先ディレクトリ（省略時は埋め込み）
+//   - EMAIL_DIGEST_SECTIONS: セクションの区切り方（language/source/topic/type/jurisdiction、デフォルト: language）
+//   - EMAIL_DIGEST_ORDER 等: 並び順（priority/recency/score）・セクションの上限・トップ記事の件数（digest_layout.go）
+//   - EMAIL_SUBSCRIBERS_FILE: 購読者リストのJSONファイル（任意、subscribers.go）
+//     指定した場合は購読者ごとに1通ずつ送信し、EMAIL_TO・EMAIL_TYPE・EMAIL_LANGUAGE は使わない
+//   - EMAIL_LIST_ID / EMAIL_LIST_UNSUBSCRIBE: ダイジェストの List-Id・List-Unsubscribe（任意、email_mime.go）
+//   - EMAIL_DRY_RUN:      true の場合は送信せず、描画したメッセージを Response の preview に返す
+//
+// 【イベント】
+//   {"dryRun": true} を渡すと EMAIL_DRY_RUN と同じく送信せずにプレビューを返す（email_preview.go）。
+//   {"rollup": "weekly"} / {"rollup": "monthly"} を渡すと、日次ダイジェストの代わりに期間の
+//   まとめメールを EMAIL_TO に送る（rollup.go）。"rollupEnd": "YYYY-MM-DD" で終了日を指定できる。
+//   EventBridge のスケジュールに固定の入力として設定する（例: 毎週月曜 {"rollup": "weekly"}）。
 //
 // =============================================================================
 package main
@@ -22,6 +40,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 
@@ -30,13 +50,22 @@ import (
 
 // LambdaConfig は環境変数から読み込む設定
 type LambdaConfig struct {
-	NotionToken      string
-	NotionDatabaseID string
-	EmailFrom        string
-	EmailPassword    string
-	EmailTo          string
-	DaysBack         int
-	EmailType        string // "full" または "short"
+	Store         pipeline.StoreConfig // 読み込み元（STORE_BACKEND / STORE_PATH）
+	EmailFrom     string
+	EmailPassword string
+	EmailTo       string
+	DaysBack      int
+	EmailType     string // "full" または "short"
+	EmailLanguage string // "ja" / "en"（空の場合は全言語）
+	TemplateDir   string // メールテンプレートの差し替え先（空の場合は埋め込み）
+
+	Layout pipeline.DigestLayout // ダイジェストのレイアウト（EMAIL_DIGEST_SECTIONS 等）
+
+	SubscribersFile string // 購読者リスト（空の場合は EMAIL_TO に1通送信）
+	DryRun          bool   // 送信せずに描画結果を返す（EMAIL_DRY_RUN またはイベントの dryRun）
+
+	Rollup    string // まとめメールの期間（イベントの rollup: weekly / monthly、空の場合は日次ダイジェスト）
+	RollupEnd string // まとめの終了日（イベントの rollupEnd、YYYY-MM-DD、空の場合は今日）
 }
 
 // Response はLambdaレスポンス
@@ -45,6 +74,18 @@ type Response struct {
 	Message    string `json:"message"`
 	Fetched    int    `json:"fetched"`
 	Sent       bool   `json:"sent"`
+
+	// 購読者リスト使用時のみ
+	Subscribers int `json:"subscribers,omitempty"` // 送信した購読者数
+	Skipped     int `json:"skipped,omitempty"`     // 配信日でなかった購読者数
+	Failed      int `json:"failed,omitempty"`      // 送信に失敗した購読者数
+
+	// まとめメールの場合のみ: 集計した期間
+	Period string `json:"period,omitempty"`
+
+	// ドライラン時のみ: 送信する代わりに描画したメッセージ
+	DryRun  bool                     `json:"dryRun,omitempty"`
+	Preview []pipeline.CapturedEmail `json:"preview,omitempty"`
 }
 
 // Handler はLambdaのメインハンドラー
@@ -53,28 +94,40 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 
 	// 1. 環境変数から設定を読み込む
 	cfg := loadConfig()
+	if eventDryRun(event) {
+		cfg.DryRun = true
+	}
+	cfg.Rollup = eventString(event, "rollup")
+	cfg.RollupEnd = eventString(event, "rollupEnd")
 
 	// 環境変数の検証
 	if err := validateConfig(cfg); err != nil {
 		return Response{StatusCode: 400, Message: err.Error()}, err
 	}
 
+	if cfg.Rollup != "" {
+		return handleRollup(ctx, cfg)
+	}
+	if cfg.SubscribersFile != "" {
+		return handleSubscribers(ctx, cfg)
+	}
+
 	log.Printf("Config: daysBack=%d, emailType=%s", cfg.DaysBack, cfg.EmailType)
 
-	// 2. Notionから記事を取得
-	clipper, err := pipeline.NewNotionClipper(cfg.NotionToken, cfg.NotionDatabaseID)
+	// 2. ストアから記事を取得
+	store, err := pipeline.OpenHeadlineStore(cfg.Store)
 	if err != nil {
-		log.Printf("Error creating Notion clipper: %v", err)
-		return Response{StatusCode: 500, Message: err.Error()}, err
+		log.Printf("Error opening headline store: %v", err)
+		return Response{StatusCode: 400, Message: err.Error()}, err
 	}
 
-	headlines, err := clipper.FetchRecentHeadlines(ctx, cfg.DaysBack)
+	headlines, err := store.QueryHeadlines(ctx, pipeline.RecentHeadlinesQuery(cfg.DaysBack))
 	if err != nil {
-		log.Printf("Error fetching headlines from Notion: %v", err)
+		log.Printf("Error fetching headlines from %s: %v", store.Name(), err)
 		return Response{StatusCode: 500, Message: err.Error()}, err
 	}
 
-	log.Printf("Fetched %d headlines from Notion (last %d days)", len(headlines), cfg.DaysBack)
+	log.Printf("Fetched %d headlines from %s (last %d days)", len(headlines), store.Name(), cfg.DaysBack)
 
 	// 3. メール送信（0件でも送信する）
 	sender, err := pipeline.NewEmailSender(cfg.EmailFrom, cfg.EmailPassword, cfg.EmailTo)
@@ -82,6 +135,16 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 		log.Printf("Error creating email sender: %v", err)
 		return Response{StatusCode: 500, Message: err.Error(), Fetched: len(headlines)}, err
 	}
+	if err := sender.SetLanguage(cfg.EmailLanguage); err != nil {
+		log.Printf("Error configuring email language: %v", err)
+		return Response{StatusCode: 500, Message: err.Error(), Fetched: len(headlines)}, err
+	}
+	if err := sender.SetLayout(cfg.Layout); err != nil {
+		log.Printf("Error configuring digest layout: %v", err)
+		return Response{StatusCode: 500, Message: err.Error(), Fetched: len(headlines)}, err
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+	capture := useCaptureMailer(sender, cfg.DryRun)
 
 	var sendErr error
 	if cfg.EmailType == "short" {
@@ -95,6 +158,17 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 		return Response{StatusCode: 500, Message: sendErr.Error(), Fetched: len(headlines)}, sendErr
 	}
 
+	if capture != nil {
+		log.Printf("Dry run: rendered email for %s (not sent)", cfg.EmailTo)
+		return Response{
+			StatusCode: 200,
+			Message:    fmt.Sprintf("Dry run: rendered %d headlines for %s (not sent)", len(headlines), cfg.EmailTo),
+			Fetched:    len(headlines),
+			DryRun:     true,
+			Preview:    capture.Messages(),
+		}, nil
+	}
+
 	log.Printf("Email sent successfully to %s", cfg.EmailTo)
 
 	return Response{
@@ -105,6 +179,160 @@ func Handler(ctx context.Context, event interface{}) (Response, error) {
 	}, nil
 }
 
+// handleSubscribers は購読者リストの各購読者にダイジェストを1通ずつ送信する
+func handleSubscribers(ctx context.Context, cfg LambdaConfig) (Response, error) {
+	subs, err := pipeline.LoadSubscribers(cfg.SubscribersFile)
+	if err != nil {
+		log.Printf("Error loading subscribers: %v", err)
+		return Response{StatusCode: 400, Message: err.Error()}, err
+	}
+	now := time.Now()
+	since := pipeline.SubscribersSince(subs, now, cfg.DaysBack)
+	if since.IsZero() {
+		log.Printf("No subscribers scheduled for %s", now.Format("2006-01-02 Mon"))
+		return Response{StatusCode: 200, Message: "No subscribers scheduled today", Skipped: len(subs)}, nil
+	}
+
+	sender, err := pipeline.NewSubscriberSender(cfg.EmailFrom, cfg.EmailPassword)
+	if err != nil {
+		log.Printf("Error creating email sender: %v", err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	if err := sender.SetLayout(cfg.Layout); err != nil {
+		log.Printf("Error configuring digest layout: %v", err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+	capture := useCaptureMailer(sender, cfg.DryRun)
+
+	store, err := pipeline.OpenHeadlineStore(cfg.Store)
+	if err != nil {
+		log.Printf("Error opening headline store: %v", err)
+		return Response{StatusCode: 400, Message: err.Error()}, err
+	}
+	headlines, err := store.QueryHeadlines(ctx, pipeline.HeadlineQuery{CreatedOnOrAfter: since, Sort: pipeline.HeadlineSortCreatedDesc})
+	if err != nil {
+		log.Printf("Error fetching headlines from %s: %v", store.Name(), err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	log.Printf("Fetched %d headlines from %s (since %s)", len(headlines), store.Name(), since.Format("2006-01-02"))
+
+	result := sender.SendSubscriberDigests(ctx, headlines, subs, now, cfg.DaysBack)
+	resp := Response{
+		StatusCode:  200,
+		Message:     fmt.Sprintf("Sent digests to %d subscriber(s)", result.Sent),
+		Fetched:     len(headlines),
+		Sent:        result.Sent > 0,
+		Subscribers: result.Sent,
+		Skipped:     result.Skipped,
+		Failed:      len(result.Failed),
+	}
+	if capture != nil {
+		resp.Message = fmt.Sprintf("Dry run: rendered digests for %d subscriber(s) (not sent)", result.Sent)
+		resp.Sent = false
+		resp.DryRun = true
+		resp.Preview = capture.Messages()
+	}
+	if err := result.Err(); err != nil {
+		log.Printf("Error sending subscriber digests: %v", err)
+		resp.StatusCode = 500
+		resp.Message = err.Error()
+		return resp, err
+	}
+	log.Printf("Sent digests to %d subscriber(s), %d not scheduled today", result.Sent, result.Skipped)
+	return resp, nil
+}
+
+// handleRollup は期間内の記事を集計したまとめメールを EMAIL_TO に送信する（rollup.go）
+func handleRollup(ctx context.Context, cfg LambdaConfig) (Response, error) {
+	period, err := pipeline.ParseRollupPeriod(cfg.Rollup, cfg.RollupEnd, time.Now())
+	if err != nil {
+		return Response{StatusCode: 400, Message: err.Error()}, err
+	}
+	log.Printf("Config: rollup=%s, period=%s", period.Kind, period.Label())
+
+	store, err := pipeline.OpenHeadlineStore(cfg.Store)
+	if err != nil {
+		log.Printf("Error opening headline store: %v", err)
+		return Response{StatusCode: 400, Message: err.Error()}, err
+	}
+	headlines, err := store.QueryHeadlines(ctx, period.Query())
+	if err != nil {
+		log.Printf("Error fetching headlines from %s: %v", store.Name(), err)
+		return Response{StatusCode: 500, Message: err.Error()}, err
+	}
+	log.Printf("Fetched %d headlines from %s (%s)", len(headlines), store.Name(), period.Label())
+
+	resp := Response{Fetched: len(headlines), Period: period.Label()}
+	sender, err := pipeline.NewEmailSender(cfg.EmailFrom, cfg.EmailPassword, cfg.EmailTo)
+	if err != nil {
+		log.Printf("Error creating email sender: %v", err)
+		resp.StatusCode, resp.Message = 500, err.Error()
+		return resp, err
+	}
+	if err := sender.SetLanguage(cfg.EmailLanguage); err != nil {
+		log.Printf("Error configuring email language: %v", err)
+		resp.StatusCode, resp.Message = 500, err.Error()
+		return resp, err
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+	capture := useCaptureMailer(sender, cfg.DryRun)
+
+	if err := sender.SendRollup(ctx, headlines, period); err != nil {
+		log.Printf("Error sending roll-up: %v", err)
+		resp.StatusCode, resp.Message = 500, err.Error()
+		return resp, err
+	}
+
+	resp.StatusCode = 200
+	if capture != nil {
+		resp.Message = fmt.Sprintf("Dry run: rendered %s roll-up of %d headlines for %s (not sent)", period.Kind, len(headlines), cfg.EmailTo)
+		resp.DryRun = true
+		resp.Preview = capture.Messages()
+		return resp, nil
+	}
+	log.Printf("Roll-up sent successfully to %s", cfg.EmailTo)
+	resp.Message = fmt.Sprintf("Successfully sent %s roll-up of %d headlines to %s", period.Kind, len(headlines), cfg.EmailTo)
+	resp.Sent = true
+	return resp, nil
+}
+
+// useCaptureMailer はドライランの場合に配送先を CaptureMailer に差し替えて返す（それ以外はnil）
+func useCaptureMailer(sender *pipeline.EmailSender, dryRun bool) *pipeline.CaptureMailer {
+	if !dryRun {
+		return nil
+	}
+	capture := &pipeline.CaptureMailer{}
+	sender.SetMailer(capture)
+	return capture
+}
+
+// eventDryRun はイベントに {"dryRun": true} が含まれるかを返す
+func eventDryRun(event interface{}) bool {
+	m, ok := event.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	switch v := m["dryRun"].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// eventString はイベントの文字列の値を返す（ない場合は空）
+func eventString(event interface{}, key string) string {
+	m, ok := event.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	v, _ := m[key].(string)
+	return strings.TrimSpace(v)
+}
+
 // loadConfig は環境変数から設定を読み込む
 func loadConfig() LambdaConfig {
 	daysBack := 1
@@ -114,38 +342,53 @@ func loadConfig() LambdaConfig {
 		}
 	}
 
+	dryRun, _ := strconv.ParseBool(os.Getenv("EMAIL_DRY_RUN"))
+
+	// 件数が数値でない場合はその項目を無視する（DAYS_BACK と同じ扱い）
+	layout, err := pipeline.DigestLayoutFromEnv()
+	if err != nil {
+		log.Printf("Warning: %v (ignored)", err)
+	}
+
 	emailType := os.Getenv("EMAIL_TYPE")
 	if emailType == "" {
 		emailType = "full"
 	}
 
 	return LambdaConfig{
-		NotionToken:      os.Getenv("NOTION_TOKEN"),
-		NotionDatabaseID: os.Getenv("NOTION_DATABASE_ID"),
-		EmailFrom:        os.Getenv("EMAIL_FROM"),
-		EmailPassword:    os.Getenv("EMAIL_PASSWORD"),
-		EmailTo:          os.Getenv("EMAIL_TO"),
-		DaysBack:         daysBack,
-		EmailType:        emailType,
+		Store:         pipeline.StoreConfigFromEnv(),
+		EmailFrom:     os.Getenv("EMAIL_FROM"),
+		EmailPassword: os.Getenv("EMAIL_PASSWORD"),
+		EmailTo:       os.Getenv("EMAIL_TO"),
+		DaysBack:      daysBack,
+		EmailType:     emailType,
+		EmailLanguage: os.Getenv("EMAIL_LANGUAGE"),
+		TemplateDir:   os.Getenv("EMAIL_TEMPLATE_DIR"),
+
+		Layout: layout,
+
+		SubscribersFile: os.Getenv("EMAIL_SUBSCRIBERS_FILE"),
+		DryRun:          dryRun,
 	}
 }
 
 // validateConfig は設定の妥当性を検証する
 func validateConfig(cfg LambdaConfig) error {
-	if cfg.NotionToken == "" {
-		return fmt.Errorf("NOTION_TOKEN is required")
-	}
-	if cfg.NotionDatabaseID == "" {
-		return fmt.Errorf("NOTION_DATABASE_ID is required")
-	}
 	if cfg.EmailFrom == "" {
 		return fmt.Errorf("EMAIL_FROM is required")
 	}
-	if cfg.EmailPassword == "" {
-		return fmt.Errorf("EMAIL_PASSWORD is required")
+	required, err := pipeline.EmailPasswordRequired()
+	if err != nil {
+		return err
+	}
+	if cfg.EmailPassword == "" && required {
+		return fmt.Errorf("EMAIL_PASSWORD is required for SMTP authentication")
+	}
+	if cfg.EmailTo == "" && cfg.SubscribersFile == "" {
+		return fmt.Errorf("EMAIL_TO or EMAIL_SUBSCRIBERS_FILE is required")
 	}
-	if cfg.EmailTo == "" {
-		return fmt.Errorf("EMAIL_TO is required")
+	if cfg.Rollup != "" && cfg.EmailTo == "" {
+		return fmt.Errorf("EMAIL_TO is required for roll-up digests")
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+// =============================================================================
+// main.go - メール配信API代替サーバー（ローカル結合テスト用）
+// =============================================================================
+//
+// internal/mailfake のメモリ上のメール配信APIをHTTPサーバーとして起動します。
+// パイプラインの MAILER=http・MAILER_HTTP_URL にこのサーバーを指定すると、
+// 配信サービスのアカウントなしでダイジェスト・エラー通知の送信を確認できます。
+//
+// 【使用方法】
+//
+//	go run ./cmd/mail-fake -addr 127.0.0.1:8788 -token test -dir out/mail
+//	MAILER=http MAILER_HTTP_URL=http://127.0.0.1:8788/v1/send MAILER_HTTP_TOKEN=test ./pipeline -sendShortEmail ...
+//	curl http://127.0.0.1:8788/_fake/messages
+//
+//	起動後に障害を追加する場合:
+//	curl -X POST http://127.0.0.1:8788/_fake/faults -d '{"status":503,"count":2}'
+//
+// =============================================================================
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"carbon-relay/internal/mailfake"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8788", "listen address")
+	token := flag.String("token", "", "require this Bearer token (empty: no authentication)")
+	dir := flag.String("dir", "", "also write each accepted message to this directory as .eml")
+	inject := flag.Int("inject503", 0, "return 503 for the first N send requests")
+	flag.Parse()
+
+	srv := mailfake.New(*token)
+	if *inject > 0 {
+		srv.Inject(mailfake.Fault{Status: http.StatusServiceUnavailable, Count: *inject})
+	}
+	if *dir != "" {
+		if err := os.MkdirAll(*dir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		srv.OnMessage = func(m mailfake.Message) {
+			path := filepath.Join(*dir, fmt.Sprintf("%04d.eml", m.ID))
+			if err := os.WriteFile(path, m.Raw, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] %v\n", err)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Fake mail API listening on http://%s/v1/send\n", *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,53 @@
+// =============================================================================
+// main.go - Notion API代替サーバー（ローカル結合テスト用）
+// =============================================================================
+//
+// internal/notionfake のメモリ上のNotion APIをHTTPサーバーとして起動します。
+// パイプラインの NOTION_API_BASE_URL にこのサーバーのURLを指定すると、
+// 実際のNotionトークンなしでクリップ・メール用の取得・エクスポートを実行できます。
+//
+// 【使用方法】
+//
+//	go run ./cmd/notion-fake -addr 127.0.0.1:8787 -inject429 2 -injectHTML 1
+//	NOTION_TOKEN=fake NOTION_API_BASE_URL=http://127.0.0.1:8787 ./pipeline -notionInit -notionPageID fake-parent-page
+//	NOTION_TOKEN=fake NOTION_API_BASE_URL=http://127.0.0.1:8787 NOTION_DATABASE_ID=<ID> ./pipeline -notionClip ...
+//
+//	起動後に障害を追加する場合:
+//	curl -X POST http://127.0.0.1:8787/_fake/faults -d '{"status":429,"count":3}'
+//
+// 結合テストの一連の手順は scripts/notion_e2e.sh を参照。
+//
+// =============================================================================
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"carbon-relay/internal/notionfake"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8787", "listen address")
+	inject429 := flag.Int("inject429", 0, "return 429 (Retry-After: 0) for the first N API requests")
+	injectHTML := flag.Int("injectHTML", 0, "return an HTML 502 page for the first N page creations (after the 429s)")
+	parentPage := flag.String("parentPage", "fake-parent-page", "workspace page ID that databases can be created under")
+	flag.Parse()
+
+	srv := notionfake.New()
+	srv.AddParentPage(*parentPage)
+	if *inject429 > 0 {
+		srv.Inject(notionfake.Fault{Status: http.StatusTooManyRequests, Count: *inject429})
+	}
+	if *injectHTML > 0 {
+		srv.Inject(notionfake.Fault{Status: http.StatusBadGateway, HTML: true, Path: "/v1/pages", Count: *injectHTML})
+	}
+
+	fmt.Fprintf(os.Stderr, "Fake Notion API listening on http://%s\n", *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+}
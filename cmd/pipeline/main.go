@@ -16,16 +16,60 @@
 //	-out             出力JSONファイルパス（省略時: stdout）
 //	-sources         収集するソース（カンマ区切り）
 //	-perSource       ソースあたりの最大記事数（デフォルト: 30）
+//	-filters         ソース別フィルタ式のJSONファイル
+//
+// ▼ アラート（alerts.go）
+//
+//	-alertRules      収集直後に評価するアラート規則のJSON（ALERT_RULES_FILE）
+//	-alertState      通知済みの記事を記録する状態ファイル（ALERT_STATE_FILE）
+//
+// ▼ フィルタ診断
+//
+//	-filterExplain   各見出しでどのフィルタ語が一致したかを表示
+//	-filter          -filterExplain で評価するフィルタ式
+//
+// ▼ 価格時系列
+//
+//	-priceSeries       抽出した価格・取引量を時系列として出力（-headlines 省略時はNotionから取得）
+//	-seriesFrom/-seriesTo  期間（YYYY-MM-DD）
+//	-seriesFormat      csv / json
+//	-seriesInstrument  取引対象の絞り込み（EUA 等）
+//
+// ▼ Notion管理
+//
+//	-notionInit      Notion DBを -notionPageID の下に作成（既存なら確認・移行）し、IDを出力
+//	-notionInitWrite -notionInit で NOTION_DATABASE_ID を書き込むファイル（例: .env）
+//	-notionMigrate   Notion DBに不足プロパティ・セレクトオプションを追加し、差分を表示
+//	-notionDryRun    -notionMigrate で変更せず差分のみ表示
+//	-notionExport    Notion DBをエクスポート（-out に出力、markdown はフォルダ）
+//	-exportFormat    jsonl / csv / markdown
+//	-exportFrom/-exportTo  作成日の期間（YYYY-MM-DD）
+//	-exportBody      ページ本文も取得する（デフォルト: true）
 //
 // ▼ メール設定
 //
 //	-sendShortEmail  50文字ヘッドラインダイジェスト送信
+//	-emailLanguage   ダイジェストに含める言語（ja / en、省略時は全言語）
+//	-emailTemplates  メールテンプレートの差し替え先ディレクトリ（EMAIL_TEMPLATE_DIR）
+//	-emailSections   ダイジェストのセクション（language / source / topic / type / jurisdiction）
+//	-emailOrder      セクション内の並び順（priority / recency / score）
+//	-emailGroupLimit 1セクションの最大件数、-emailTopStories 先頭のトップ記事の件数
+//	-emailSubscribers 購読者リスト（購読者ごとに1通ずつ送信、EMAIL_SUBSCRIBERS_FILE）
+//	-emailPreview    送信せずに .eml / .html を書き出すディレクトリ（-emailType・-emailPreviewInput）
+//	-rollup          週次・月次のまとめメール（weekly / monthly、-rollupEnd で終了日）
 //	-notionClip      Notionデータベースに保存
 //
+// ▼ ストア（store.go）
+//
+//	-save            -store で選んだストアに保存
+//	-store           notion / file（メール・価格時系列の読み込み元にもなる、STORE_BACKEND）
+//	-storePath       file の保存先（STORE_PATH、デフォルト: headlines_store.json）
+//
 // =============================================================================
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -51,15 +95,49 @@ func main() {
 	cfg := pipeline.ParseFlags()
 
 	// --- メール専用モードの早期終了 ---
+	if cfg.Email.Preview != "" {
+		pipeline.HandleEmailPreview(&cfg.Email, &cfg.Store)
+		return
+	}
+	if cfg.Email.Rollup != "" {
+		pipeline.HandleRollupSend(&cfg.Email, &cfg.Store)
+		return
+	}
 	if cfg.Email.SendShortEmail {
-		pipeline.HandleShortEmailSend(cfg.Email.DaysBack)
+		pipeline.HandleShortEmailSend(&cfg.Email, &cfg.Store)
 		return
 	}
 	if cfg.Email.ListShortHeadlines {
-		pipeline.HandleListShortHeadlines(cfg.Email.DaysBack)
+		pipeline.HandleListShortHeadlines(cfg.Email.DaysBack, &cfg.Store)
 		return
 	}
 
+	// --- Notion管理モードの早期終了 ---
+	if cfg.Notion.Init {
+		pipeline.HandleNotionInit(&cfg.Notion, &cfg.Output)
+		return
+	}
+	if cfg.Notion.Migrate {
+		pipeline.HandleNotionMigrate(cfg.Notion.DryRun)
+		return
+	}
+	if cfg.Notion.Export {
+		pipeline.HandleNotionExport(&cfg.Notion, cfg.Output.OutFile)
+		return
+	}
+
+	// --- 価格時系列モードの早期終了 ---
+	if cfg.Prices.Enabled {
+		pipeline.HandlePriceSeries(&cfg.Prices, &cfg.Store, cfg.Input.HeadlinesFile, cfg.Output.OutFile)
+		return
+	}
+
+	// ソース別フィルタを読み込み
+	sourceFilters, err := pipeline.LoadSourceFilters(cfg.Filter.FiltersFile)
+	if err != nil {
+		fatalf("loading filters: %v", err)
+	}
+
 	// --- 1) ヘッドラインの収集または読み込み ---
 	var headlines []pipeline.Headline
 	var collectResult *pipeline.CollectResult
@@ -67,19 +145,35 @@ func main() {
 		if err := readJSONFile(cfg.Input.HeadlinesFile, &headlines); err != nil {
 			fatalf("reading headlines: %v", err)
 		}
+		pipeline.EnrichHeadlines(headlines)
 	} else {
 		headlineCfg := pipeline.DefaultHeadlineConfig()
+		// explainモードでは除外された見出しも表示するため、収集時にはフィルタしない
+		if !cfg.Filter.Explain {
+			headlineCfg.SourceFilters = sourceFilters
+		}
 		result, err := pipeline.CollectFromSources(cfg.Input.Sources(), cfg.Input.PerSource, headlineCfg)
 		if err != nil {
 			fatalf("collecting headlines: %v", err)
 		}
 		headlines = result.Headlines
 		collectResult = result
+
+		// 収集直後にアラート規則を評価（-alertRules / ALERT_RULES_FILE がある場合のみ）
+		if !cfg.Filter.Explain {
+			pipeline.HandleAlerts(headlines, &cfg.Alerts)
+		}
+	}
+
+	// --- フィルタ診断モード ---
+	if cfg.Filter.Explain {
+		pipeline.HandleFilterExplain(headlines, cfg.Filter.Expression, sourceFilters)
+		return
 	}
 
 	if len(headlines) == 0 {
 		// fatalf前にエラー通知を送る
-		pipeline.SendErrorNotification(collectResult, nil)
+		pipeline.NotifyRunErrors(context.Background(), pipeline.RunReport{Job: pipeline.ErrorJobPipeline, Collect: collectResult}, ".")
 		fatalf("no headlines collected")
 	}
 
@@ -94,14 +188,17 @@ func main() {
 	// --- 2) 結果の出力 ---
 	pipeline.HandleJSONOutput(headlines, &cfg.Output)
 
-	// --- 3) Notionへのクリップ（有効な場合） ---
+	// --- 3) Notionへのクリップ・ストアへの保存（有効な場合） ---
+	// -notionClip はNotion専用（既存データベースへのクリップのみ、作成は -notionInit）、-save は -store で選んだストア
 	var notionResult *pipeline.NotionClipResult
 	if cfg.Output.NotionClip {
 		notionResult = pipeline.HandleNotionClip(headlines, &cfg.Output)
+	} else if cfg.Output.Save {
+		notionResult = pipeline.HandleStoreSave(headlines, &cfg.Store)
 	}
 
-	// --- 4) エラー通知（全処理完了後） ---
-	pipeline.SendErrorNotification(collectResult, notionResult)
+	// --- 4) エラー通知（全処理完了後、継続中の問題は ERROR_REMIND_HOURS ごとにまとめて再通知） ---
+	pipeline.NotifyRunErrors(context.Background(), pipeline.RunReport{Job: pipeline.ErrorJobPipeline, Collect: collectResult, Store: notionResult}, ".")
 }
 
 // =============================================================================
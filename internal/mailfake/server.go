@@ -0,0 +1,215 @@
+// =============================================================================
+// server.go - メール配信APIのローカル代替サーバー（結合テスト用）
+// =============================================================================
+//
+// このパッケージは HTTPMailer（internal/pipeline/mailer.go）が送るメール配信APIを
+// メモリ上で受け付けます。実際の配信サービスのアカウントなしで MAILER=http を確認するために使います。
+//
+// 【実装しているエンドポイント】
+//
+//	POST /v1/send   メッセージの受け付け（{"from", "to", "raw"}、raw はRFC 5322のbase64）
+//
+//   Token を設定した場合は "Authorization: Bearer {Token}" がないリクエストを401で拒否する。
+//   from・to・raw の欠落やbase64の誤りは400を返す。
+//
+// 【障害の注入】
+//   Inject で指定回数だけエラー（500・503 等）を返せる。SendWithRetry の再送を確認する。
+//
+// 【制御用エンドポイント】（cmd/mail-fake から使用）
+//
+//	GET  /_fake/messages  受け付けたメッセージ（件名・宛先・本文）の一覧
+//	POST /_fake/faults    障害を注入（Fault のJSON）
+//
+// 【使用方法】
+//
+//	fake := mailfake.New("test-token")
+//	srv := httptest.NewServer(fake)
+//	os.Setenv("MAILER", "http")
+//	os.Setenv("MAILER_HTTP_URL", srv.URL+"/v1/send")
+//	os.Setenv("MAILER_HTTP_TOKEN", "test-token")
+//
+// =============================================================================
+package mailfake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"sync"
+	"time"
+)
+
+// Fault は注入する障害
+type Fault struct {
+	Status int `json:"status"` // HTTPステータス（500、503 等）
+	Count  int `json:"count"`  // 返す回数（0以下は1回）
+}
+
+// Message は受け付けたメッセージ
+type Message struct {
+	ID       int       `json:"id"`
+	From     string    `json:"from"`
+	To       []string  `json:"to"`
+	Subject  string    `json:"subject"`
+	Received time.Time `json:"received"`
+	Raw      []byte    `json:"-"`
+}
+
+// sendRequest は POST /v1/send の本文
+type sendRequest struct {
+	From string   `json:"from"`
+	To   []string `json:"to"`
+	Raw  string   `json:"raw"`
+}
+
+// Server はメモリ上のメール配信API
+type Server struct {
+	token string
+
+	mu       sync.Mutex
+	messages []Message
+	faults   []Fault
+
+	// OnMessage は受け付けたメッセージごとに呼ばれる（nil可、ファイルへの書き出し等）
+	OnMessage func(Message)
+}
+
+// New は token で認証する代替サーバーを作成する（token が空の場合は認証しない）
+func New(token string) *Server {
+	return &Server{token: token}
+}
+
+// Inject は障害を追加する
+func (s *Server) Inject(f Fault) {
+	if f.Count <= 0 {
+		f.Count = 1
+	}
+	s.mu.Lock()
+	s.faults = append(s.faults, f)
+	s.mu.Unlock()
+}
+
+// Messages は受け付けたメッセージのコピーを返す
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// ServeHTTP はリクエストを処理する
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/send":
+		s.handleSend(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/_fake/messages":
+		s.handleMessages(w)
+	case r.Method == http.MethodPost && r.URL.Path == "/_fake/faults":
+		var f Fault
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil || f.Status == 0 {
+			writeError(w, http.StatusBadRequest, "invalid fault")
+			return
+		}
+		s.Inject(f)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// handleSend はメッセージを検証して保存する
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(w, http.StatusUnauthorized, "invalid API token")
+		return
+	}
+	if status, ok := s.takeFault(); ok {
+		writeError(w, status, "injected fault")
+		return
+	}
+
+	var req sendRequest
+	body, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.From == "" || len(req.To) == 0 || req.Raw == "" {
+		writeError(w, http.StatusBadRequest, "from, to and raw are required")
+		return
+	}
+	raw, err := base64.StdEncoding.DecodeString(req.Raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "raw is not valid base64")
+		return
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "raw is not an RFC 5322 message: "+err.Error())
+		return
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		subject = parsed.Header.Get("Subject")
+	}
+
+	s.mu.Lock()
+	msg := Message{
+		ID:       len(s.messages) + 1,
+		From:     req.From,
+		To:       req.To,
+		Subject:  subject,
+		Received: time.Now().UTC(),
+		Raw:      raw,
+	}
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+
+	if s.OnMessage != nil {
+		s.OnMessage(msg)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("fake-%d", msg.ID), "status": "queued"})
+}
+
+// handleMessages は受け付けたメッセージの一覧を返す
+func (s *Server) handleMessages(w http.ResponseWriter) {
+	type item struct {
+		Message
+		Body string `json:"raw"`
+	}
+	var items []item
+	for _, m := range s.Messages() {
+		items = append(items, item{Message: m, Body: string(m.Raw)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// takeFault は残っている障害があれば1回分消費してステータスを返す
+func (s *Server) takeFault() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.faults) == 0 {
+		return 0, false
+	}
+	f := &s.faults[0]
+	f.Count--
+	status := f.Status
+	if f.Count <= 0 {
+		s.faults = s.faults[1:]
+	}
+	return status, true
+}
+
+// writeError はJSONのエラーを返す
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"status": status, "message": message})
+}
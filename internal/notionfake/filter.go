@@ -0,0 +1,227 @@
+// =============================================================================
+// filter.go - データベース検索フィルタの評価
+// =============================================================================
+//
+// このファイルは databases/query の filter をメモリ上のページに対して評価します。
+// carbon-relay が送るフィルタ（notion_query.go）に必要な条件のみ実装し、
+// それ以外の条件は validation_error にする（代替サーバーの実装漏れに気付けるように）。
+//
+// 【対応している条件】
+//
+//	and / or                                  複合条件（入れ子可）
+//	timestamp: created_time / last_edited_time 日付条件
+//	property + date                           equals / before / after / on_or_before / on_or_after / is_empty / is_not_empty
+//	property + select                         equals / does_not_equal / is_empty / is_not_empty
+//	property + multi_select                   contains / does_not_contain
+//	property + checkbox                       equals / does_not_equal
+//	property + rich_text / title              equals / contains / is_empty / is_not_empty
+//
+// =============================================================================
+package notionfake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// matchFilter はページがフィルタに一致するかを返す（フィルタがない場合は一致）
+func matchFilter(p *page, filter any) (bool, error) {
+	f, ok := filter.(object)
+	if !ok || len(f) == 0 {
+		return true, nil
+	}
+
+	if list, ok := f["and"].([]any); ok {
+		for _, sub := range list {
+			m, err := matchFilter(p, sub)
+			if err != nil || !m {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	if list, ok := f["or"].([]any); ok {
+		for _, sub := range list {
+			m, err := matchFilter(p, sub)
+			if err != nil {
+				return false, err
+			}
+			if m {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if ts, ok := f["timestamp"].(string); ok {
+		cond, _ := f[ts].(object)
+		if cond == nil {
+			return false, fmt.Errorf("body.filter.%s should be defined.", ts)
+		}
+		return matchDate(p.created, true, cond)
+	}
+
+	name, ok := f["property"].(string)
+	if !ok {
+		return false, fmt.Errorf("body.filter should define property, timestamp, and or or.")
+	}
+	value := propertyValue(p, name)
+	for _, typ := range []string{"date", "select", "multi_select", "checkbox", "rich_text", "title"} {
+		cond, ok := f[typ].(object)
+		if !ok {
+			continue
+		}
+		switch typ {
+		case "date":
+			start, present := dateStart(value)
+			return matchDate(start, present, cond)
+		case "select":
+			return matchSelect(value, cond)
+		case "multi_select":
+			return matchMultiSelect(value, cond)
+		case "checkbox":
+			return matchCheckbox(value, cond)
+		default:
+			return matchText(value, cond)
+		}
+	}
+	return false, fmt.Errorf("filter on property %q uses a condition not supported by the fake server.", name)
+}
+
+// matchDate は日付条件を評価する（present=false は値なし）
+func matchDate(t time.Time, present bool, cond object) (bool, error) {
+	for op, v := range cond {
+		switch op {
+		case "is_empty":
+			return !present, nil
+		case "is_not_empty":
+			return present, nil
+		}
+		if !present {
+			return false, nil
+		}
+		s, _ := v.(string)
+		ref, err := parseDate(s)
+		if err != nil {
+			return false, fmt.Errorf("body.filter.%s should be a valid ISO 8601 date string, instead was %q.", op, s)
+		}
+		switch op {
+		case "equals":
+			return t.Equal(ref), nil
+		case "before":
+			return t.Before(ref), nil
+		case "after":
+			return t.After(ref), nil
+		case "on_or_before":
+			return !t.After(ref), nil
+		case "on_or_after":
+			return !t.Before(ref), nil
+		default:
+			return false, fmt.Errorf("date condition %q is not supported by the fake server.", op)
+		}
+	}
+	return false, fmt.Errorf("date filter has no condition.")
+}
+
+// matchSelect はセレクト条件を評価する
+func matchSelect(value any, cond object) (bool, error) {
+	name := ""
+	if opt, ok := value.(object); ok {
+		name, _ = opt["name"].(string)
+	}
+	for op, v := range cond {
+		switch op {
+		case "equals":
+			return name == v, nil
+		case "does_not_equal":
+			return name != v, nil
+		case "is_empty":
+			return name == "", nil
+		case "is_not_empty":
+			return name != "", nil
+		}
+		return false, fmt.Errorf("select condition %q is not supported by the fake server.", op)
+	}
+	return false, fmt.Errorf("select filter has no condition.")
+}
+
+// matchMultiSelect はマルチセレクト条件を評価する
+func matchMultiSelect(value any, cond object) (bool, error) {
+	has := func(name any) bool {
+		list, _ := value.([]any)
+		for _, o := range list {
+			if opt, ok := o.(object); ok && opt["name"] == name {
+				return true
+			}
+		}
+		return false
+	}
+	for op, v := range cond {
+		switch op {
+		case "contains":
+			return has(v), nil
+		case "does_not_contain":
+			return !has(v), nil
+		}
+		return false, fmt.Errorf("multi_select condition %q is not supported by the fake server.", op)
+	}
+	return false, fmt.Errorf("multi_select filter has no condition.")
+}
+
+// matchCheckbox はチェックボックス条件を評価する
+func matchCheckbox(value any, cond object) (bool, error) {
+	checked, _ := value.(bool)
+	for op, v := range cond {
+		switch op {
+		case "equals":
+			return checked == v, nil
+		case "does_not_equal":
+			return checked != v, nil
+		}
+		return false, fmt.Errorf("checkbox condition %q is not supported by the fake server.", op)
+	}
+	return false, fmt.Errorf("checkbox filter has no condition.")
+}
+
+// matchText はテキスト条件を評価する
+func matchText(value any, cond object) (bool, error) {
+	text := plainText(value)
+	for op, v := range cond {
+		s, _ := v.(string)
+		switch op {
+		case "equals":
+			return text == s, nil
+		case "contains":
+			return strings.Contains(text, s), nil
+		case "is_empty":
+			return text == "", nil
+		case "is_not_empty":
+			return text != "", nil
+		}
+		return false, fmt.Errorf("text condition %q is not supported by the fake server.", op)
+	}
+	return false, fmt.Errorf("text filter has no condition.")
+}
+
+// dateStart は日付プロパティの開始日時を返す
+func dateStart(value any) (time.Time, bool) {
+	d, ok := value.(object)
+	if !ok {
+		return time.Time{}, false
+	}
+	s, _ := d["start"].(string)
+	t, err := parseDate(s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseDate はISO 8601の日時または日付を解釈する
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
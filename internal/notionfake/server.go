@@ -0,0 +1,906 @@
+// =============================================================================
+// server.go - Notion APIのローカル代替サーバー（結合テスト用）
+// =============================================================================
+//
+// このパッケージは carbon-relay が使う Notion API のサブセットをメモリ上で実装します。
+// 実際のトークンなしで NotionClipper（notion.go）を端から端まで動かすために使います。
+//
+// 【実装しているエンドポイント】
+//
+//	POST  /v1/databases                 データベース作成（親ページに child_database ブロックを追加）
+//	GET   /v1/databases/{id}            データベース取得
+//	PATCH /v1/databases/{id}            プロパティ追加・更新（マイグレーション）
+//	POST  /v1/databases/{id}/query      検索（フィルタ・ソート・ページング）
+//	POST  /v1/pages                     ページ作成
+//	PATCH /v1/pages/{id}                プロパティ更新（要約の更新）
+//	PATCH /v1/blocks/{id}/children      ブロック追加
+//	GET   /v1/blocks/{id}/children      ブロック取得（ページング）
+//
+// 【親ページ】
+//   データベースの親になるワークスペースのページは AddParentPage で登録する
+//   （実際のNotionと同様、未登録のページの下には作成できない）。
+//
+// 【障害の注入】
+//   Inject で429（Retry-After付き）やHTMLのエラーページ（ロードバランサの502等）を
+//   指定回数だけ返せる。notion_batch.go の429再送と notion_errors.go の分類を確認する。
+//
+// 【制御用エンドポイント】（cmd/notion-fake・scripts/notion_e2e.sh から使用）
+//
+//	GET  /_fake/pages     作成済みページ（プロパティ付き）の一覧
+//	GET  /_fake/requests  受け付けたリクエスト（"METHOD /path"）の一覧
+//	POST /_fake/faults    障害を注入（Fault のJSON）
+//
+// 【使用方法】
+//
+//	fake := notionfake.New()
+//	fake.AddParentPage("fake-parent-page")
+//	srv := httptest.NewServer(fake)
+//	os.Setenv("NOTION_API_BASE_URL", srv.URL)  // notion_batch.go がリクエスト先を差し替える
+//
+// =============================================================================
+package notionfake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAppendChildren は1リクエストで追加できるブロック数の上限（Notion APIと同じ）
+const maxAppendChildren = 100
+
+// object はNotion APIのJSONオブジェクト
+type object = map[string]any
+
+// Fault は注入する障害
+//
+// Path が空でない場合、パスがこの文字列で始まるリクエストにのみ適用する。
+type Fault struct {
+	Status     int    `json:"status"`                // HTTPステータス（429、502 等）
+	HTML       bool   `json:"html,omitempty"`        // trueの場合はJSONではなくHTMLのエラーページを返す
+	RetryAfter string `json:"retry_after,omitempty"` // 429の Retry-After ヘッダー（デフォルト "0"）
+	Path       string `json:"path,omitempty"`        // 対象パスの接頭辞（例: "/v1/pages"）
+	Count      int    `json:"count"`                 // 返す回数（0以下は1回）
+}
+
+// page はデータベースに作成されたページ
+type page struct {
+	obj     object
+	dbID    string
+	created time.Time
+}
+
+// Server はメモリ上のNotion API
+type Server struct {
+	mu        sync.Mutex
+	databases map[string]object
+	pages     []*page
+	blocks    map[string][]object // 親ブロック（ページ）ID → 子ブロック
+	faults    []*Fault
+	requests  []string
+	nextID    int
+	now       func() time.Time
+}
+
+// New は空のサーバーを作成する
+func New() *Server {
+	return &Server{
+		databases: map[string]object{},
+		blocks:    map[string][]object{},
+		now:       time.Now,
+	}
+}
+
+// AddParentPage はデータベースの親にできるワークスペースのページを登録する
+func (s *Server) AddParentPage(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id = normalizeID(id)
+	if _, ok := s.blocks[id]; !ok {
+		s.blocks[id] = []object{}
+	}
+}
+
+// SetNow はページ作成日時に使う時計を差し替える（期間フィルタの確認用）
+func (s *Server) SetNow(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Inject は障害を注入する（注入順に消費される）
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Count <= 0 {
+		f.Count = 1
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// Pages はデータベースに作成されたページを作成順に返す（dbIDが空の場合は全て）
+func (s *Server) Pages(dbID string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []map[string]any
+	for _, p := range s.pages {
+		if dbID == "" || p.dbID == dbID {
+			out = append(out, p.obj)
+		}
+	}
+	return out
+}
+
+// Children はブロック（ページ）の子ブロックを返す
+func (s *Server) Children(blockID string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.blocks[normalizeID(blockID)]...)
+}
+
+// Database はデータベースオブジェクトを返す（存在しない場合はnil）
+func (s *Server) Database(id string) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.databases[normalizeID(id)]
+}
+
+// Requests は受け付けたリクエスト（"METHOD /path"）を順に返す（制御用エンドポイントを除く）
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// =============================================================================
+// ルーティング
+// =============================================================================
+
+// ServeHTTP はリクエストを各エンドポイントに振り分ける
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/_fake/") {
+		s.serveControl(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if f := s.takeFault(r.URL.Path); f != nil {
+		writeFault(w, f)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || r.Header.Get("Authorization") == "Bearer " {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "API token is invalid.")
+		return
+	}
+
+	var body object
+	if r.Body != nil && r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid_json", "Error parsing JSON body.")
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, http.StatusNotFound, "invalid_request_url", "Invalid request URL.")
+		return
+	}
+	parts = parts[1:]
+
+	switch {
+	case len(parts) == 1 && parts[0] == "databases" && r.Method == http.MethodPost:
+		s.createDatabase(w, body)
+	case len(parts) == 2 && parts[0] == "databases" && r.Method == http.MethodGet:
+		s.getDatabase(w, parts[1])
+	case len(parts) == 2 && parts[0] == "databases" && r.Method == http.MethodPatch:
+		s.updateDatabase(w, parts[1], body)
+	case len(parts) == 3 && parts[0] == "databases" && parts[2] == "query" && r.Method == http.MethodPost:
+		s.queryDatabase(w, parts[1], body)
+	case len(parts) == 1 && parts[0] == "pages" && r.Method == http.MethodPost:
+		s.createPage(w, body)
+	case len(parts) == 2 && parts[0] == "pages" && r.Method == http.MethodPatch:
+		s.updatePage(w, parts[1], body)
+	case len(parts) == 3 && parts[0] == "blocks" && parts[2] == "children" && r.Method == http.MethodPatch:
+		s.appendChildren(w, parts[1], body)
+	case len(parts) == 3 && parts[0] == "blocks" && parts[2] == "children" && r.Method == http.MethodGet:
+		s.getChildren(w, r, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_url", fmt.Sprintf("%s %s is not supported by the fake server.", r.Method, r.URL.Path))
+	}
+}
+
+// serveControl は制御用エンドポイントを処理する
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/_fake/pages" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Pages(r.URL.Query().Get("database_id")))
+	case r.URL.Path == "/_fake/requests" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Requests())
+	case r.URL.Path == "/_fake/faults" && r.Method == http.MethodPost:
+		var f Fault
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil || f.Status == 0 {
+			http.Error(w, "fault must be JSON with a status", http.StatusBadRequest)
+			return
+		}
+		s.Inject(f)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// takeFault はパスに該当する最初の障害を1回分消費して返す
+func (s *Server) takeFault(path string) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+		f.Count--
+		if f.Count <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+// =============================================================================
+// データベース
+// =============================================================================
+
+// createDatabase はデータベースを作成する
+func (s *Server) createDatabase(w http.ResponseWriter, body object) {
+	parent, _ := body["parent"].(object)
+	if parent == nil || parent["page_id"] == nil {
+		writeError(w, http.StatusBadRequest, "validation_error", "body.parent.page_id should be defined.")
+		return
+	}
+	parentID := normalizeID(fmt.Sprint(parent["page_id"]))
+	if !s.blockExists(parentID) {
+		writeNotFound(w, "block", parentID)
+		return
+	}
+	props, _ := body["properties"].(object)
+	if countTitle(props) != 1 {
+		writeError(w, http.StatusBadRequest, "validation_error", "Database must have exactly one title property.")
+		return
+	}
+
+	id := s.newID()
+	now := s.timestamp()
+	db := object{
+		"object":           "database",
+		"id":               id,
+		"created_time":     now,
+		"last_edited_time": now,
+		"title":            withPlainText(body["title"]),
+		"parent":           parent,
+		"properties":       object{},
+		"url":              "https://www.notion.so/" + strings.ReplaceAll(id, "-", ""),
+		"archived":         false,
+	}
+	s.mergeProperties(db, props)
+	s.databases[id] = db
+
+	// 親ページには child_database ブロックとして現れる（notion_init.go の再利用判定で使用）
+	s.blocks[parentID] = append(s.blocks[parentID], object{
+		"object":           "block",
+		"id":               id,
+		"type":             "child_database",
+		"child_database":   object{"title": plainText(db["title"])},
+		"created_time":     now,
+		"last_edited_time": now,
+		"has_children":     false,
+		"archived":         false,
+		"parent":           object{"type": "page_id", "page_id": parentID},
+	})
+	writeJSON(w, http.StatusOK, db)
+}
+
+// getDatabase はデータベースを返す
+func (s *Server) getDatabase(w http.ResponseWriter, id string) {
+	db := s.databases[normalizeID(id)]
+	if db == nil {
+		writeNotFound(w, "database", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+// updateDatabase はプロパティを追加・更新する（値がnullのプロパティは削除）
+func (s *Server) updateDatabase(w http.ResponseWriter, id string, body object) {
+	db := s.databases[normalizeID(id)]
+	if db == nil {
+		writeNotFound(w, "database", id)
+		return
+	}
+	if props, ok := body["properties"].(object); ok {
+		s.mergeProperties(db, props)
+	}
+	if title, ok := body["title"]; ok {
+		db["title"] = withPlainText(title)
+	}
+	db["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, db)
+}
+
+// mergeProperties はプロパティ設定をデータベースに反映する
+//
+// 既存プロパティのIDは保持し、セレクトオプションにはIDを付ける。
+func (s *Server) mergeProperties(db object, props object) {
+	existing := db["properties"].(object)
+	for name, v := range props {
+		if v == nil {
+			delete(existing, name)
+			continue
+		}
+		cfg, ok := v.(object)
+		if !ok {
+			continue
+		}
+		typ, _ := cfg["type"].(string)
+		if typ == "" {
+			// {"name": {"rich_text": {}}} のように type を省略した形式
+			for k := range cfg {
+				if k != "name" && k != "id" {
+					typ = k
+				}
+			}
+		}
+		id := s.propertyID(existing, name)
+		if typ == "title" {
+			id = "title" // Notion APIではタイトルプロパティのIDは常に "title"
+		}
+		prop := object{"id": id, "name": name, "type": typ}
+		conf, _ := cfg[typ].(object)
+		if conf == nil {
+			conf = object{}
+		}
+		if sel, ok := conf["options"].([]any); ok {
+			for _, o := range sel {
+				if opt, ok := o.(object); ok && opt["id"] == nil {
+					opt["id"] = s.newShortID()
+				}
+			}
+		}
+		prop[typ] = conf
+		existing[name] = prop
+	}
+}
+
+// propertyID は既存プロパティのIDを返す（新規の場合は採番）
+func (s *Server) propertyID(existing object, name string) string {
+	if p, ok := existing[name].(object); ok {
+		if id, ok := p["id"].(string); ok {
+			return id
+		}
+	}
+	return s.newShortID()
+}
+
+// queryDatabase はフィルタ・ソート・ページングを適用してページを返す
+func (s *Server) queryDatabase(w http.ResponseWriter, id string, body object) {
+	id = normalizeID(id)
+	if s.databases[id] == nil {
+		writeNotFound(w, "database", id)
+		return
+	}
+
+	var matched []*page
+	for _, p := range s.pages {
+		if p.dbID != id {
+			continue
+		}
+		ok, err := matchFilter(p, body["filter"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+			return
+		}
+		if ok {
+			matched = append(matched, p)
+		}
+	}
+	if err := sortPages(matched, body["sorts"]); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	results := make([]any, 0, len(matched))
+	for _, p := range matched {
+		results = append(results, p.obj)
+	}
+	writeList(w, results, body["start_cursor"], body["page_size"])
+}
+
+// =============================================================================
+// ページ・ブロック
+// =============================================================================
+
+// createPage はデータベースにページを作成する
+//
+// セレクトの未知の値はNotion APIと同じくオプションとして自動追加する。
+func (s *Server) createPage(w http.ResponseWriter, body object) {
+	parent, _ := body["parent"].(object)
+	dbID, _ := parent["database_id"].(string)
+	dbID = normalizeID(dbID)
+	db := s.databases[dbID]
+	if db == nil {
+		writeNotFound(w, "database", dbID)
+		return
+	}
+
+	schema := db["properties"].(object)
+	props, _ := body["properties"].(object)
+	out, err := s.propertyValues(schema, props)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	// 値を指定しなかったプロパティは空の値で返す（Notion APIと同じ）
+	for name, v := range schema {
+		if _, ok := out[name]; ok {
+			continue
+		}
+		spec := v.(object)
+		typ := spec["type"].(string)
+		out[name] = object{"id": spec["id"], "type": typ, typ: emptyValue(typ)}
+	}
+
+	id := s.newID()
+	created := s.now().UTC().Truncate(time.Minute)
+	p := &page{
+		dbID:    dbID,
+		created: created,
+		obj: object{
+			"object":           "page",
+			"id":               id,
+			"created_time":     created.Format(time.RFC3339),
+			"last_edited_time": created.Format(time.RFC3339),
+			"parent":           object{"type": "database_id", "database_id": dbID},
+			"properties":       out,
+			"url":              "https://www.notion.so/" + strings.ReplaceAll(id, "-", ""),
+			"archived":         false,
+		},
+	}
+	s.pages = append(s.pages, p)
+
+	if children, ok := body["children"].([]any); ok {
+		if _, err := s.addChildren(id, children); err != nil {
+			writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, p.obj)
+}
+
+// updatePage はページのプロパティを更新する（指定したプロパティのみ）
+func (s *Server) updatePage(w http.ResponseWriter, id string, body object) {
+	id = normalizeID(id)
+	var p *page
+	for _, candidate := range s.pages {
+		if candidate.obj["id"] == id {
+			p = candidate
+		}
+	}
+	if p == nil {
+		writeNotFound(w, "page", id)
+		return
+	}
+
+	props, _ := body["properties"].(object)
+	updated, err := s.propertyValues(s.databases[p.dbID]["properties"].(object), props)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	current := p.obj["properties"].(object)
+	for name, v := range updated {
+		current[name] = v
+	}
+	if archived, ok := body["archived"].(bool); ok {
+		p.obj["archived"] = archived
+	}
+	p.obj["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, p.obj)
+}
+
+// propertyValues はリクエストのプロパティ値をスキーマに照らしてレスポンスの形に変換する
+//
+// スキーマにないプロパティ・型の異なる値はNotion APIと同じく validation_error にする。
+func (s *Server) propertyValues(schema, props object) (object, error) {
+	out := object{}
+	for name, v := range props {
+		spec, ok := schema[name].(object)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a property that exists.", name)
+		}
+		value, _ := v.(object)
+		typ := spec["type"].(string)
+		if t, _ := value["type"].(string); t != "" && t != typ {
+			return nil, fmt.Errorf("%s is expected to be %s.", name, typ)
+		}
+		prop := object{"id": spec["id"], "type": typ}
+		switch typ {
+		case "title", "rich_text":
+			prop[typ] = withPlainText(value[typ])
+		case "select":
+			if opt, ok := value["select"].(object); ok {
+				prop["select"] = s.ensureOption(spec, "select", opt)
+			}
+		case "multi_select":
+			var opts []any
+			list, _ := value["multi_select"].([]any)
+			for _, o := range list {
+				if opt, ok := o.(object); ok {
+					opts = append(opts, s.ensureOption(spec, "multi_select", opt))
+				}
+			}
+			prop["multi_select"] = opts
+		default:
+			prop[typ] = value[typ]
+		}
+		out[name] = prop
+	}
+	return out, nil
+}
+
+// ensureOption はセレクトの値をスキーマのオプションに追加し、IDと色を付けて返す
+func (s *Server) ensureOption(spec object, typ string, opt object) object {
+	name, _ := opt["name"].(string)
+	conf, _ := spec[typ].(object)
+	if conf == nil {
+		conf = object{}
+		spec[typ] = conf
+	}
+	options, _ := conf["options"].([]any)
+	for _, o := range options {
+		if existing, ok := o.(object); ok && existing["name"] == name {
+			return existing
+		}
+	}
+	added := object{"id": s.newShortID(), "name": name, "color": "default"}
+	conf["options"] = append(options, added)
+	return added
+}
+
+// appendChildren はブロックを追加する
+func (s *Server) appendChildren(w http.ResponseWriter, parentID string, body object) {
+	parentID = normalizeID(parentID)
+	if !s.blockExists(parentID) {
+		writeNotFound(w, "block", parentID)
+		return
+	}
+	children, _ := body["children"].([]any)
+	added, err := s.addChildren(parentID, children)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, object{"object": "list", "results": added, "has_more": false, "next_cursor": nil})
+}
+
+// addChildren はブロックにIDを付けて親の子として保存する
+func (s *Server) addChildren(parentID string, children []any) ([]any, error) {
+	if len(children) > maxAppendChildren {
+		return nil, fmt.Errorf("body.children.length should be ≤ `%d`, instead was `%d`.", maxAppendChildren, len(children))
+	}
+	now := s.timestamp()
+	added := make([]any, 0, len(children))
+	for i, c := range children {
+		block, ok := c.(object)
+		if !ok {
+			return nil, fmt.Errorf("body.children[%d] should be an object.", i)
+		}
+		typ, _ := block["type"].(string)
+		payload, _ := block[typ].(object)
+		if typ == "" || payload == nil {
+			return nil, fmt.Errorf("body.children[%d].%s should be defined.", i, typ)
+		}
+
+		id := s.newID()
+		// 入れ子の子ブロック（トグル等）は別に保存し、has_children で示す
+		nested, _ := payload["children"].([]any)
+		delete(payload, "children")
+		if rt, ok := payload["rich_text"]; ok {
+			payload["rich_text"] = withPlainText(rt)
+		}
+		b := object{
+			"object":           "block",
+			"id":               id,
+			"type":             typ,
+			typ:                payload,
+			"created_time":     now,
+			"last_edited_time": now,
+			"has_children":     len(nested) > 0,
+			"archived":         false,
+			"parent":           object{"type": "block_id", "block_id": parentID},
+		}
+		s.blocks[parentID] = append(s.blocks[parentID], b)
+		if len(nested) > 0 {
+			if _, err := s.addChildren(id, nested); err != nil {
+				return nil, err
+			}
+		}
+		added = append(added, b)
+	}
+	return added, nil
+}
+
+// getChildren は子ブロックをページングして返す
+func (s *Server) getChildren(w http.ResponseWriter, r *http.Request, parentID string) {
+	parentID = normalizeID(parentID)
+	if !s.blockExists(parentID) {
+		writeNotFound(w, "block", parentID)
+		return
+	}
+	results := make([]any, 0, len(s.blocks[parentID]))
+	for _, b := range s.blocks[parentID] {
+		results = append(results, b)
+	}
+	q := r.URL.Query()
+	var cursor, size any
+	if v := q.Get("start_cursor"); v != "" {
+		cursor = v
+	}
+	if v, err := strconv.Atoi(q.Get("page_size")); err == nil {
+		size = float64(v)
+	}
+	writeList(w, results, cursor, size)
+}
+
+// blockExists はページ（親ページを含む）またはブロックが存在するかを返す
+func (s *Server) blockExists(id string) bool {
+	if _, ok := s.blocks[id]; ok {
+		return true
+	}
+	for _, p := range s.pages {
+		if p.obj["id"] == id {
+			return true
+		}
+	}
+	for _, children := range s.blocks {
+		for _, b := range children {
+			if b["id"] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// レスポンス
+// =============================================================================
+
+// writeList は結果をカーソル（開始位置）とページサイズでページングして返す
+func writeList(w http.ResponseWriter, results []any, cursor, pageSize any) {
+	start := 0
+	if c, ok := cursor.(string); ok && c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 0 || n > len(results) {
+			writeError(w, http.StatusBadRequest, "validation_error", "start_cursor is invalid.")
+			return
+		}
+		start = n
+	}
+	size := 100
+	if n, ok := pageSize.(float64); ok && n > 0 && n <= 100 {
+		size = int(n)
+	}
+	end := start + size
+	if end > len(results) {
+		end = len(results)
+	}
+
+	resp := object{"object": "list", "results": results[start:end], "has_more": end < len(results), "next_cursor": nil}
+	if end < len(results) {
+		resp["next_cursor"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeFault は注入された障害のレスポンスを返す
+func writeFault(w http.ResponseWriter, f *Fault) {
+	if f.Status == http.StatusTooManyRequests {
+		retryAfter := f.RetryAfter
+		if retryAfter == "" {
+			retryAfter = "0"
+		}
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	if f.HTML {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(f.Status)
+		fmt.Fprintf(w, "<html><head><title>%d %s</title></head><body><h1>%d %s</h1></body></html>\n",
+			f.Status, http.StatusText(f.Status), f.Status, http.StatusText(f.Status))
+		return
+	}
+	code := "internal_server_error"
+	switch f.Status {
+	case http.StatusTooManyRequests:
+		code = "rate_limited"
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		code = "service_unavailable"
+	case http.StatusConflict:
+		code = "conflict_error"
+	case http.StatusBadRequest:
+		code = "validation_error"
+	}
+	writeError(w, f.Status, code, "Injected by the fake Notion server.")
+}
+
+// writeNotFound はNotion APIと同じ object_not_found エラーを返す
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "object_not_found",
+		fmt.Sprintf("Could not find %s with ID: %s.", kind, id))
+}
+
+// writeError はNotion API形式のエラーを返す
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, object{"object": "error", "status": status, "code": code, "message": message})
+}
+
+// writeJSON はJSONレスポンスを返す
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// =============================================================================
+// 補助関数
+// =============================================================================
+
+// newID はUUID形式のIDを採番する
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID)
+}
+
+// newShortID はプロパティ・オプション用の短いIDを採番する
+func (s *Server) newShortID() string {
+	s.nextID++
+	return fmt.Sprintf("p%d", s.nextID)
+}
+
+// timestamp は現在時刻をNotion APIの形式で返す
+func (s *Server) timestamp() string {
+	return s.now().UTC().Truncate(time.Minute).Format(time.RFC3339)
+}
+
+// normalizeID はハイフンなしのIDをUUID形式に揃える
+func normalizeID(id string) string {
+	if len(id) == 32 && !strings.Contains(id, "-") {
+		return id[0:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+	}
+	return id
+}
+
+// countTitle はタイトル型のプロパティ数を返す
+func countTitle(props object) int {
+	n := 0
+	for _, v := range props {
+		if p, ok := v.(object); ok && (p["type"] == "title" || p["title"] != nil) {
+			n++
+		}
+	}
+	return n
+}
+
+// withPlainText はリッチテキストの配列に plain_text を補う（Notion APIのレスポンスと同じ）
+func withPlainText(v any) []any {
+	list, _ := v.([]any)
+	out := make([]any, 0, len(list))
+	for _, item := range list {
+		rt, ok := item.(object)
+		if !ok {
+			continue
+		}
+		if rt["type"] == nil {
+			rt["type"] = "text"
+		}
+		if rt["plain_text"] == nil {
+			if text, ok := rt["text"].(object); ok {
+				rt["plain_text"] = text["content"]
+			}
+		}
+		out = append(out, rt)
+	}
+	return out
+}
+
+// plainText はリッチテキストの配列をプレーンテキストにする
+func plainText(v any) string {
+	var sb strings.Builder
+	list, _ := v.([]any)
+	for _, item := range list {
+		if rt, ok := item.(object); ok {
+			s, _ := rt["plain_text"].(string)
+			sb.WriteString(s)
+		}
+	}
+	return sb.String()
+}
+
+// emptyValue は値のないプロパティの値を返す
+func emptyValue(typ string) any {
+	switch typ {
+	case "title", "rich_text", "multi_select":
+		return []any{}
+	case "checkbox":
+		return false
+	}
+	return nil
+}
+
+// sortPages はNotion APIの sorts 指定でページを並べ替える（指定がない場合は作成日時の新しい順）
+func sortPages(pages []*page, sorts any) error {
+	list, _ := sorts.([]any)
+	if len(list) == 0 {
+		sort.SliceStable(pages, func(i, j int) bool { return pages[i].created.After(pages[j].created) })
+		return nil
+	}
+	for k := len(list) - 1; k >= 0; k-- {
+		spec, _ := list[k].(object)
+		desc := spec["direction"] == "descending"
+		var key func(*page) string
+		switch {
+		case spec["timestamp"] == "created_time" || spec["timestamp"] == "last_edited_time":
+			key = func(p *page) string { return p.created.Format(time.RFC3339) }
+		case spec["property"] != nil:
+			name, _ := spec["property"].(string)
+			key = func(p *page) string { return sortKey(propertyValue(p, name)) }
+		default:
+			return fmt.Errorf("body.sorts[%d] should define property or timestamp.", k)
+		}
+		sort.SliceStable(pages, func(i, j int) bool {
+			a, b := key(pages[i]), key(pages[j])
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	}
+	return nil
+}
+
+// sortKey はプロパティ値を文字列の比較キーに変換する（日付は開始日時）
+func sortKey(v any) string {
+	switch x := v.(type) {
+	case object:
+		if start, ok := x["start"].(string); ok {
+			return start
+		}
+		if name, ok := x["name"].(string); ok {
+			return name
+		}
+	case string:
+		return x
+	case float64:
+		return fmt.Sprintf("%020.6f", x)
+	}
+	return ""
+}
+
+// propertyValue はページのプロパティ値（型ごとのフィールド）を返す
+func propertyValue(p *page, name string) any {
+	props, _ := p.obj["properties"].(object)
+	prop, _ := props[name].(object)
+	if prop == nil {
+		return nil
+	}
+	typ, _ := prop["type"].(string)
+	return prop[typ]
+}
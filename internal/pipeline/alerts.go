@@ -0,0 +1,618 @@
+// =============================================================================
+// alerts.go - 重要語のリアルタイムアラート
+// =============================================================================
+//
+// このファイルは収集直後（CollectFromSources の後）に記事をアラート規則で評価し、
+// 翌日のダイジェストを待たずにメール・Webhookで通知する機能を提供します。
+// EU ETS の MSR の決定、CORSIA の適格性の更新、Verra の方法論の停止などを想定しています。
+//
+// 【アラート規則】（ALERT_RULES_FILE / -alertRules）
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "EU ETS MSR",
+//	      "match": "\"market stability reserve\" OR MSR",
+//	      "topics": ["Compliance Markets"],
+//	      "severity": "critical"
+//	    },
+//	    {
+//	      "name": "CORSIA eligibility",
+//	      "keywords": ["CORSIA eligible", "CORSIA eligibility"],
+//	      "severity": "warning"
+//	    },
+//	    {
+//	      "name": "Verra suspension",
+//	      "match": "Verra AND (suspend* OR suspension)",
+//	      "sources": ["Carbon Herald", "Carbon Brief"],
+//	      "severity": "critical"
+//	    }
+//	  ]
+//	}
+//
+//	name     - 規則の名前（必須、通知に表示）
+//	match    - フィルタ式（filter.go の文法）。タイトルと本文で評価する
+//	keywords - いずれかに一致すればよいキーワード（フレーズとして扱う）。match と同時には使えない
+//	sources  - ソース名（"Carbon Pulse" 等、Notionの Source と同じ表記）のいずれか
+//	topics   - トピック（topics.go の名前）のいずれか
+//	severity - info / warning（デフォルト）/ critical
+//	  指定した条件はすべて満たす必要がある（AND）。少なくとも1つの条件が必要。
+//	  1つの記事が複数の規則に一致した場合は1件のアラートにまとめ、最も高い重要度を使う。
+//
+// 【通知先】
+//
+//	メール   - ALERT_EMAIL_TO（未設定なら EMAIL_TO）に1通にまとめて送る。
+//	           送信元・配送先は EMAIL_FROM / EMAIL_PASSWORD / MAILER 等（mailer.go）のとおり
+//	Webhook  - ALERT_WEBHOOK_URL にJSONでPOSTする（ALERT_WEBHOOK_TOKEN は Bearer トークン）
+//	           {"text": "<Slack等で表示する本文>", "alerts": [{"rules": [...], "severity": ..., "title": ..., "url": ...}]}
+//	  両方を設定した場合は両方に送る。どちらもない場合はログに出すだけで、通知済みとしては記録しない。
+//
+// 【抑制】（ALERT_SUPPRESS_HOURS、デフォルト: 72）
+//   通知した記事はURLと正規化したタイトルを状態ファイル（ALERT_STATE_FILE、state.go）に記録し、
+//   同じ記事（転載でURLが違ってもタイトルが同じもの）は再び通知しない。
+//   記録は最後に収集で見かけてから抑制時間が過ぎると消えるため、フィードに残り続ける記事は
+//   通知し直さない。公開日が抑制時間より古い記事は最初から通知しない。
+//   どの通知先にも送れなかった場合は通知済みとして記録せず、次回の実行で送り直す
+//   （既に記録のある記事の最後に見かけた日時は、送信の成否にかかわらず更新する）。
+//   Lambdaでは状態ファイルの保存先（ALERT_STATE_FILE または STATE_DIR）が必須（state.go）。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// アラートの重要度
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// alertSeverityRank は重要度の順位（大きいほど重要）
+var alertSeverityRank = map[string]int{
+	AlertSeverityInfo:     1,
+	AlertSeverityWarning:  2,
+	AlertSeverityCritical: 3,
+}
+
+// DefaultAlertSuppressHours は同じ記事を再び通知しない時間のデフォルト
+const DefaultAlertSuppressHours = 72
+
+// alertStateName は状態ファイルのデフォルトのファイル名
+const alertStateName = "alerts_state.json"
+
+// alertStateVersion は状態ファイルの保存形式のバージョン
+const alertStateVersion = 1
+
+// =============================================================================
+// 規則
+// =============================================================================
+
+// AlertRule はアラート規則の1エントリ
+type AlertRule struct {
+	Name     string   `json:"name"`
+	Match    string   `json:"match,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+
+	filter *FilterExpr
+}
+
+// alertRuleFile はアラート規則のファイルの形式
+type alertRuleFile struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// LoadAlertRules はアラート規則のファイルを読み込み、検証する
+func LoadAlertRules(path string) ([]AlertRule, error) {
+	var file alertRuleFile
+	if err := readJSONFile(path, &file); err != nil {
+		return nil, fmt.Errorf("reading alert rules: %w", err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("alert rules file %s has no rules", path)
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		if err := r.normalize(); err != nil {
+			return nil, fmt.Errorf("alert rule %d (%s): %w", i+1, r.Name, err)
+		}
+		key := strings.ToLower(r.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("alert rule %q is listed more than once", r.Name)
+		}
+		seen[key] = true
+	}
+	return file.Rules, nil
+}
+
+// normalize はデフォルト値を補い、規則を検証する
+func (r *AlertRule) normalize() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	r.Severity = strings.ToLower(strings.TrimSpace(r.Severity))
+	if r.Severity == "" {
+		r.Severity = AlertSeverityWarning
+	}
+	if alertSeverityRank[r.Severity] == 0 {
+		return fmt.Errorf("unsupported severity %q (use info, warning or critical)", r.Severity)
+	}
+
+	match := strings.TrimSpace(r.Match)
+	switch {
+	case match != "" && len(r.Keywords) > 0:
+		return fmt.Errorf("use either match or keywords, not both")
+	case match != "":
+		f, err := ParseFilter(match)
+		if err != nil {
+			return fmt.Errorf("match: %w", err)
+		}
+		r.filter = f
+	case len(r.Keywords) > 0:
+		r.filter = NewKeywordFilter(r.Keywords)
+	}
+
+	for _, t := range r.Topics {
+		if !knownTopic(t) {
+			return fmt.Errorf("unknown topic %q", t)
+		}
+	}
+	if r.filter == nil && len(r.Sources) == 0 && len(r.Topics) == 0 {
+		return fmt.Errorf("at least one of match, keywords, sources or topics is required")
+	}
+	return nil
+}
+
+// matches は記事が規則のすべての条件を満たすかと、一致した語（match / keywords の場合）を返す
+func (r *AlertRule) matches(h Headline) (bool, string) {
+	if len(r.Sources) > 0 && !containsFold(r.Sources, h.Source) {
+		return false, ""
+	}
+	if len(r.Topics) > 0 {
+		found := false
+		for _, t := range h.Topics {
+			if containsFold(r.Topics, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, ""
+		}
+	}
+	if r.filter == nil {
+		return true, ""
+	}
+	m := r.filter.Explain(h.Title, h.Excerpt)
+	if !m.Matched {
+		return false, ""
+	}
+	return true, m.String()
+}
+
+// =============================================================================
+// 評価
+// =============================================================================
+
+// Alert は通知する1件の記事
+type Alert struct {
+	Headline Headline
+	Severity string   // 一致した規則のうち最も高い重要度
+	Rules    []string // 一致した規則の名前（規則の順）
+	Terms    []string // 一致した語（規則ごと、match / keywords の場合のみ）
+}
+
+// EvaluateAlerts は記事を規則で評価し、一致した記事を重要度の高い順に返す（同じ重要度は収集の順）
+func EvaluateAlerts(headlines []Headline, rules []AlertRule) []Alert {
+	var alerts []Alert
+	for _, h := range headlines {
+		var a *Alert
+		for i := range rules {
+			r := &rules[i]
+			ok, terms := r.matches(h)
+			if !ok {
+				continue
+			}
+			if a == nil {
+				a = &Alert{Headline: h, Severity: r.Severity}
+			}
+			if alertSeverityRank[r.Severity] > alertSeverityRank[a.Severity] {
+				a.Severity = r.Severity
+			}
+			a.Rules = append(a.Rules, r.Name)
+			if terms != "" {
+				a.Terms = append(a.Terms, terms)
+			}
+		}
+		if a != nil {
+			alerts = append(alerts, *a)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alertSeverityRank[alerts[i].Severity] > alertSeverityRank[alerts[j].Severity]
+	})
+	return alerts
+}
+
+// =============================================================================
+// 抑制
+// =============================================================================
+
+// alertState は通知済みの記事の記録（状態ファイルの内容）
+type alertState struct {
+	Version int                        `json:"version"`
+	Stories map[string]alertStateEntry `json:"stories"`
+}
+
+// alertStateEntry は通知済みの記事1件の記録
+type alertStateEntry struct {
+	Title    string    `json:"title"`
+	Alerted  time.Time `json:"alerted"`  // 通知した日時
+	LastSeen time.Time `json:"lastSeen"` // 最後に収集で見かけた日時
+}
+
+// alertKeys は記事を識別するキー（URLと正規化したタイトル）を返す
+func alertKeys(h Headline) []string {
+	var keys []string
+	if u := strings.TrimRight(strings.TrimSpace(h.URL), "/"); u != "" {
+		if i := strings.IndexAny(u, "?#"); i >= 0 {
+			u = u[:i]
+		}
+		keys = append(keys, "url:"+strings.ToLower(u))
+	}
+	if t := normalizeFilterText(normalizeWhitespace(h.Title)); t != "" {
+		keys = append(keys, "title:"+t)
+	}
+	return keys
+}
+
+// suppress は抑制中の記事と公開日が古すぎる記事を除いたアラートを返す
+//
+// 抑制中の記事は最後に見かけた日時を更新する。
+func (s *alertState) suppress(alerts []Alert, now time.Time, window time.Duration) (fresh []Alert, suppressed int) {
+	for _, a := range alerts {
+		if published := a.Headline.PublishedAt; published != "" {
+			if t, err := parsePublishedDate(published); err == nil && now.Sub(t) > window {
+				suppressed++
+				continue
+			}
+		}
+		seen := false
+		for _, k := range alertKeys(a.Headline) {
+			if e, ok := s.Stories[k]; ok {
+				e.LastSeen = now
+				s.Stories[k] = e
+				seen = true
+			}
+		}
+		if seen {
+			suppressed++
+			continue
+		}
+		fresh = append(fresh, a)
+	}
+	return fresh, suppressed
+}
+
+// record は通知した記事を記録し、抑制時間を過ぎた記録を消す
+func (s *alertState) record(alerts []Alert, now time.Time, window time.Duration) {
+	for k, e := range s.Stories {
+		if now.Sub(e.LastSeen) > window {
+			delete(s.Stories, k)
+		}
+	}
+	for _, a := range alerts {
+		for _, k := range alertKeys(a.Headline) {
+			s.Stories[k] = alertStateEntry{Title: a.Headline.Title, Alerted: now, LastSeen: now}
+		}
+	}
+}
+
+// =============================================================================
+// 設定
+// =============================================================================
+
+// AlertConfig はアラートの設定
+type AlertConfig struct {
+	// RulesFile はアラート規則のJSONファイル（ALERT_RULES_FILE、空の場合はアラートなし）
+	RulesFile string
+
+	// StateFile は通知済みの記事を記録する状態ファイル（ALERT_STATE_FILE、空の場合は STATE_DIR/alerts_state.json）
+	StateFile string
+
+	// Suppress は同じ記事を再び通知しない時間（ALERT_SUPPRESS_HOURS）
+	Suppress time.Duration
+
+	// EmailTo はアラートメールの宛先（ALERT_EMAIL_TO、未設定なら EMAIL_TO）
+	EmailTo string
+
+	// WebhookURL / WebhookToken はアラートをPOSTするWebhook（ALERT_WEBHOOK_URL / ALERT_WEBHOOK_TOKEN）
+	WebhookURL   string
+	WebhookToken string
+}
+
+// AlertConfigFromEnv は環境変数からアラートの設定を読み込む
+//
+// 状態ファイルは ALERT_STATE_FILE、未設定なら STATE_DIR（未設定なら stateDir）の下に置く。
+// stateDir が空（Lambda）の場合、ALERT_RULES_FILE があるのに保存先がなければエラーを返す
+// （抑制の記録が残らず、同じ記事を繰り返し通知してしまうため）。
+func AlertConfigFromEnv(stateDir string) (AlertConfig, error) {
+	cfg := AlertConfig{
+		RulesFile:    strings.TrimSpace(os.Getenv("ALERT_RULES_FILE")),
+		Suppress:     DefaultAlertSuppressHours * time.Hour,
+		EmailTo:      strings.TrimSpace(os.Getenv("ALERT_EMAIL_TO")),
+		WebhookURL:   strings.TrimSpace(os.Getenv("ALERT_WEBHOOK_URL")),
+		WebhookToken: os.Getenv("ALERT_WEBHOOK_TOKEN"),
+	}
+	if cfg.EmailTo == "" {
+		cfg.EmailTo = os.Getenv("EMAIL_TO")
+	}
+	if s := strings.TrimSpace(os.Getenv("ALERT_SUPPRESS_HOURS")); s != "" {
+		hours, err := strconv.Atoi(s)
+		if err != nil || hours <= 0 {
+			return cfg, fmt.Errorf("ALERT_SUPPRESS_HOURS must be a positive integer, got %q", s)
+		}
+		cfg.Suppress = time.Duration(hours) * time.Hour
+	}
+	if cfg.RulesFile != "" || stateDir != "" {
+		path, err := StatePath("ALERT_STATE_FILE", stateDir, alertStateName)
+		if err != nil {
+			return cfg, fmt.Errorf("alerts need a persistent state file: %w", err)
+		}
+		cfg.StateFile = path
+	}
+	return cfg, nil
+}
+
+// =============================================================================
+// 実行
+// =============================================================================
+
+// AlertResult はアラートの評価・通知の結果
+type AlertResult struct {
+	Matched    int      // 規則に一致した記事数
+	Suppressed int      // 抑制した記事数（通知済み・公開日が古い）
+	Sent       int      // 通知した記事数
+	Channels   []string // 通知できた通知先（email / webhook）
+}
+
+// SendAlerts は収集した記事をアラート規則で評価し、新しい記事を通知する
+//
+// cfg.RulesFile が空の場合は何もしない。通知先への送信に失敗した場合はエラーを返すが、
+// いずれかの通知先に送れた記事は通知済みとして記録する。
+func SendAlerts(ctx context.Context, headlines []Headline, cfg AlertConfig, now time.Time) (*AlertResult, error) {
+	result := &AlertResult{}
+	if cfg.RulesFile == "" {
+		return result, nil
+	}
+	rules, err := LoadAlertRules(cfg.RulesFile)
+	if err != nil {
+		return result, err
+	}
+
+	alerts := EvaluateAlerts(headlines, rules)
+	result.Matched = len(alerts)
+	if len(alerts) == 0 {
+		return result, nil
+	}
+
+	state := &alertState{Version: alertStateVersion, Stories: map[string]alertStateEntry{}}
+	if err := loadState(cfg.StateFile, state); err != nil {
+		return result, err
+	}
+	if state.Stories == nil {
+		state.Stories = map[string]alertStateEntry{}
+	}
+	fresh, suppressed := state.suppress(alerts, now, cfg.Suppress)
+	result.Suppressed = suppressed
+
+	for _, a := range fresh {
+		infof("Alert [%s] %s: %s", strings.ToUpper(a.Severity), strings.Join(a.Rules, ", "), a.Headline.Title)
+	}
+
+	// 新しい記事がなくても最後に見かけた日時は残す
+	if len(fresh) == 0 {
+		state.record(nil, now, cfg.Suppress)
+		return result, saveState(cfg.StateFile, state)
+	}
+
+	var errs []string
+	emailSet := EmailCredentialsSet(os.Getenv("EMAIL_FROM"), os.Getenv("EMAIL_PASSWORD"), cfg.EmailTo)
+	if emailSet {
+		if err := sendAlertEmail(ctx, fresh, cfg.EmailTo, now); err != nil {
+			errs = append(errs, "email: "+err.Error())
+		} else {
+			result.Channels = append(result.Channels, "email")
+		}
+	}
+	if cfg.WebhookURL != "" {
+		if err := sendAlertWebhook(ctx, fresh, cfg.WebhookURL, cfg.WebhookToken); err != nil {
+			errs = append(errs, "webhook: "+err.Error())
+		} else {
+			result.Channels = append(result.Channels, "webhook")
+		}
+	}
+	if !emailSet && cfg.WebhookURL == "" {
+		warnf("No alert channel configured (set ALERT_WEBHOOK_URL or EMAIL_FROM/ALERT_EMAIL_TO), %d alert(s) only logged", len(fresh))
+	}
+
+	// どの通知先にも送れなかった記事は記録せず次回送り直す（見かけた日時の更新は残す）
+	var sent []Alert
+	if len(result.Channels) > 0 {
+		result.Sent = len(fresh)
+		sent = fresh
+	}
+	state.record(sent, now, cfg.Suppress)
+	if err := saveState(cfg.StateFile, state); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return result, fmt.Errorf("sending alerts failed: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// HandleAlerts はCLIの収集直後にアラートを評価・通知する
+//
+// アラートの失敗で収集・保存を止めないよう、エラーは警告として表示する。
+func HandleAlerts(headlines []Headline, cfg *AlertConfig) {
+	if cfg.RulesFile == "" {
+		return
+	}
+	result, err := SendAlerts(context.Background(), headlines, *cfg, time.Now())
+	if err != nil {
+		warnf("Alerts: %v", err)
+	}
+	infof("Alerts: %d matched, %d suppressed, %d sent%s", result.Matched, result.Suppressed, result.Sent, alertChannelSuffix(result.Channels))
+}
+
+// alertChannelSuffix はログ用に通知先を " via email, webhook" の形で返す
+func alertChannelSuffix(channels []string) string {
+	if len(channels) == 0 {
+		return ""
+	}
+	return " via " + strings.Join(channels, ", ")
+}
+
+// =============================================================================
+// 通知
+// =============================================================================
+
+// alertSubject はアラートメールの件名を返す
+//
+// 例: [Carbon Relay] [CRITICAL] EU ETS MSR: Commission proposes MSR review (+2)
+func alertSubject(alerts []Alert) string {
+	first := alerts[0]
+	subject := fmt.Sprintf("[Carbon Relay] [%s] %s: %s",
+		strings.ToUpper(first.Severity), first.Rules[0], truncateString(first.Headline.Title, 80))
+	if len(alerts) > 1 {
+		subject += fmt.Sprintf(" (+%d)", len(alerts)-1)
+	}
+	return subject
+}
+
+// formatAlerts はアラートの本文（メール・Webhookの text）を返す
+//
+// 【形式】
+//
+//	[CRITICAL] EU ETS MSR
+//	  Commission proposes MSR review
+//	  Carbon Pulse / 2026-10-18
+//	  https://...
+//	  一致: "market stability reserve"@title
+func formatAlerts(alerts []Alert) string {
+	var b strings.Builder
+	for i, a := range alerts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		h := a.Headline
+		fmt.Fprintf(&b, "[%s] %s\n", strings.ToUpper(a.Severity), strings.Join(a.Rules, ", "))
+		fmt.Fprintf(&b, "  %s\n", h.Title)
+		meta := h.Source
+		if h.PublishedAt != "" {
+			if t, err := parsePublishedDate(h.PublishedAt); err == nil {
+				meta += " / " + t.Format("2006-01-02")
+			}
+		}
+		fmt.Fprintf(&b, "  %s\n", meta)
+		if h.URL != "" {
+			fmt.Fprintf(&b, "  %s\n", h.URL)
+		}
+		if len(a.Terms) > 0 {
+			fmt.Fprintf(&b, "  一致: %s\n", strings.Join(a.Terms, " / "))
+		}
+	}
+	return b.String()
+}
+
+// sendAlertEmail はアラートを1通のメールにまとめて送る
+func sendAlertEmail(ctx context.Context, alerts []Alert, to string, now time.Time) error {
+	sender, err := NewEmailSender(os.Getenv("EMAIL_FROM"), os.Getenv("EMAIL_PASSWORD"), to)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Carbon Relay アラート（%d件）\n\n%s\nTimestamp: %s\n",
+		len(alerts), formatAlerts(alerts), now.Format(time.RFC3339))
+	return sender.SendWithRetry(ctx, sender.BuildEmailMessage(alertSubject(alerts), body))
+}
+
+// alertWebhookPayload はWebhookに送るJSON
+type alertWebhookPayload struct {
+	Text   string              `json:"text"`
+	Alerts []alertWebhookEntry `json:"alerts"`
+}
+
+// alertWebhookEntry はWebhookに送るアラート1件
+type alertWebhookEntry struct {
+	Rules       []string `json:"rules"`
+	Severity    string   `json:"severity"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Source      string   `json:"source"`
+	PublishedAt string   `json:"publishedAt,omitempty"`
+	Topics      []string `json:"topics,omitempty"`
+	Matched     []string `json:"matched,omitempty"`
+}
+
+// sendAlertWebhook はアラートをWebhookにJSONでPOSTする（2xx 以外の応答はエラー）
+func sendAlertWebhook(ctx context.Context, alerts []Alert, url, token string) error {
+	payload := alertWebhookPayload{Text: alertSubject(alerts) + "\n\n" + formatAlerts(alerts)}
+	for _, a := range alerts {
+		payload.Alerts = append(payload.Alerts, alertWebhookEntry{
+			Rules:       a.Rules,
+			Severity:    a.Severity,
+			Title:       a.Headline.Title,
+			URL:         a.Headline.URL,
+			Source:      a.Headline.Source,
+			PublishedAt: a.Headline.PublishedAt,
+			Topics:      a.Headline.Topics,
+			Matched:     a.Terms,
+		})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid ALERT_WEBHOOK_URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
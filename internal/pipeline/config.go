@@ -8,14 +8,21 @@
 //   - InputConfig:    入力ソース設定
 //   - OutputConfig:   出力設定
 //   - EmailConfig:    メール設定
+//   - FilterConfig:   キーワードフィルタ設定
+//   - PriceSeriesConfig: 価格時系列出力設定
+//   - NotionAdminConfig: Notionデータベース管理設定
+//   - StoreConfig:    見出しストアの選択（store.go）
+//   - AlertConfig:    収集直後のアラート（alerts.go）
 //
 // =============================================================================
 package pipeline
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
 // =============================================================================
@@ -27,6 +34,11 @@ type PipelineConfig struct {
 	Input  InputConfig
 	Output OutputConfig
 	Email  EmailModeConfig
+	Filter FilterConfig
+	Prices PriceSeriesConfig
+	Notion NotionAdminConfig
+	Store  StoreConfig
+	Alerts AlertConfig
 }
 
 // InputConfig は入力ソースに関する設定
@@ -70,7 +82,10 @@ type OutputConfig struct {
 	// NotionClip がtrueの場合、Notionに保存
 	NotionClip bool
 
-	// NotionPageID は新規データベース作成時の親ページID
+	// Save がtrueの場合、-store で選んだストアに保存（store.go）
+	Save bool
+
+	// NotionPageID は -notionInit でデータベースを作成する親ページID
 	NotionPageID string
 
 	// NotionDatabaseID は既存のデータベースID
@@ -89,6 +104,114 @@ type EmailModeConfig struct {
 
 	// DaysBack はメール用の取得期間（日数）
 	DaysBack int
+
+	// Language はダイジェストに含める言語（ja / en、空の場合は全言語）
+	Language string
+
+	// TemplateDir はメールテンプレートの差し替え先（email_template.go、空の場合は埋め込みのみ）
+	TemplateDir string
+
+	// Layout はダイジェストのセクション・並び順・件数の上限・トップ記事（digest_layout.go）
+	Layout DigestLayout
+
+	// SubscribersFile は購読者リストのJSONファイル（subscribers.go、空の場合は EMAIL_TO に1通送信）
+	SubscribersFile string
+
+	// Preview が空でない場合、送信せずにこのディレクトリへ .eml / .html を書き出す（email_preview.go）
+	Preview string
+
+	// PreviewInput はプレビューに使う記事のJSONファイル（空の場合はストアから取得）
+	PreviewInput string
+
+	// Type はプレビューするダイジェストの種類（short / full、EMAIL_TYPE）
+	Type string
+
+	// Rollup が空でない場合、期間（weekly / monthly）のまとめメールを EMAIL_TO に送信（rollup.go）
+	Rollup string
+
+	// RollupEnd はまとめの終了日（YYYY-MM-DD、この日の前日までを集計。空の場合は今日）
+	RollupEnd string
+}
+
+// FilterConfig はキーワードフィルタ（filter.go）に関する設定
+type FilterConfig struct {
+	// FiltersFile はソース別フィルタ式のJSONファイル（SOURCE_FILTERS_FILE）
+	FiltersFile string
+
+	// Expression は -filterExplain で全見出しに適用するフィルタ式（空の場合はソース別設定）
+	Expression string
+
+	// Explain がtrueの場合、各見出しでどの語が一致したかを表示する
+	Explain bool
+}
+
+// PriceSeriesConfig は価格・取引量の時系列出力（prices.go）に関する設定
+type PriceSeriesConfig struct {
+	// Enabled がtrueの場合、時系列を出力して終了する
+	Enabled bool
+
+	// From / To は期間（YYYY-MM-DD、空の場合は無制限）
+	From string
+	To   string
+
+	// Format は出力形式（csv / json）
+	Format string
+
+	// Instrument は取引対象の絞り込み（EUA, UKA 等。空の場合は全て）
+	Instrument string
+}
+
+// Range は From / To を日付に変換する（未指定はゼロ値）
+func (c *PriceSeriesConfig) Range() (from, to time.Time, err error) {
+	return parseDateRange(c.From, c.To, "-seriesFrom", "-seriesTo")
+}
+
+// parseDateRange は YYYY-MM-DD の期間を日付に変換する（未指定はゼロ値）
+func parseDateRange(fromStr, toStr, fromFlag, toFlag string) (from, to time.Time, err error) {
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return from, to, fmt.Errorf("invalid %s %q (want YYYY-MM-DD)", fromFlag, fromStr)
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return from, to, fmt.Errorf("invalid %s %q (want YYYY-MM-DD)", toFlag, toStr)
+		}
+	}
+	return from, to, nil
+}
+
+// NotionAdminConfig はNotionデータベースの管理コマンド（notion_schema.go）に関する設定
+type NotionAdminConfig struct {
+	// Init がtrueの場合、データベースを作成または確認して終了する（notion_init.go）
+	Init bool
+
+	// InitWrite は -notionInit で NOTION_DATABASE_ID を書き込む設定ファイル（空の場合は書き込まない）
+	InitWrite string
+
+	// Migrate がtrueの場合、スキーママイグレーションを実行して終了する
+	Migrate bool
+
+	// DryRun がtrueの場合、変更を行わず差分のみ表示する
+	DryRun bool
+
+	// Export がtrueの場合、データベースをエクスポートして終了する（notion_export.go）
+	Export bool
+
+	// ExportFormat はエクスポート形式（jsonl / csv / markdown）
+	ExportFormat string
+
+	// ExportFrom / ExportTo はページの作成日の期間（YYYY-MM-DD、空の場合は無制限）
+	ExportFrom string
+	ExportTo   string
+
+	// ExportBody がtrueの場合、ページ本文のブロックも取得する
+	ExportBody bool
+}
+
+// ExportRange は ExportFrom / ExportTo を日付に変換する（未指定はゼロ値）
+func (c *NotionAdminConfig) ExportRange() (from, to time.Time, err error) {
+	return parseDateRange(c.ExportFrom, c.ExportTo, "-exportFrom", "-exportTo")
 }
 
 // =============================================================================
@@ -123,13 +246,65 @@ func ParseFlags() *PipelineConfig {
 	// 出力フラグ
 	flag.StringVar(&cfg.Output.OutFile, "out", "", "optional: write output JSON to this path (default: stdout)")
 	flag.BoolVar(&cfg.Output.NotionClip, "notionClip", false, "clip articles to Notion database")
-	flag.StringVar(&cfg.Output.NotionPageID, "notionPageID", os.Getenv("NOTION_PAGE_ID"), "parent page ID for creating new Notion database")
+	flag.StringVar(&cfg.Output.NotionPageID, "notionPageID", os.Getenv("NOTION_PAGE_ID"), "parent page ID for -notionInit")
 	flag.StringVar(&cfg.Output.NotionDatabaseID, "notionDatabaseID", os.Getenv("NOTION_DATABASE_ID"), "existing Notion database ID")
+	flag.BoolVar(&cfg.Output.Save, "save", false, "save articles to the headline store selected with -store")
+
+	// ストアフラグ（保存先と、メール・価格時系列の読み込み元）
+	flag.StringVar(&cfg.Store.Backend, "store", os.Getenv("STORE_BACKEND"), "headline store: notion or file (default: notion)")
+	flag.StringVar(&cfg.Store.Path, "storePath", os.Getenv("STORE_PATH"), "file store path for -store file (default: "+DefaultStorePath+")")
 
-	// メールフラグ
+	// メールフラグ（ダイジェストのレイアウトは環境変数の値をデフォルトにする）
+	layout, err := DigestLayoutFromEnv()
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
 	flag.BoolVar(&cfg.Email.SendShortEmail, "sendShortEmail", false, "send 50-char short headlines digest via email")
 	flag.BoolVar(&cfg.Email.ListShortHeadlines, "listShortHeadlines", false, "list Article Summary 300 values from NotionDB (diagnostic)")
 	flag.IntVar(&cfg.Email.DaysBack, "emailDaysBack", 1, "fetch headlines from last N days for email")
+	flag.StringVar(&cfg.Email.Language, "emailLanguage", os.Getenv("EMAIL_LANGUAGE"), "only include articles in this language in the digest (ja, en or all)")
+	flag.StringVar(&cfg.Email.TemplateDir, "emailTemplates", os.Getenv("EMAIL_TEMPLATE_DIR"), "directory with email templates overriding the built-in ones (digest_short.html.tmpl etc.)")
+	flag.StringVar(&cfg.Email.SubscribersFile, "emailSubscribers", os.Getenv("EMAIL_SUBSCRIBERS_FILE"), "JSON subscriber list: send one filtered digest per subscriber instead of one mail to EMAIL_TO")
+	flag.StringVar(&cfg.Email.Layout.Sections, "emailSections", layout.Sections, "digest sections: language, source, topic, type or jurisdiction (default: language)")
+	flag.StringVar(&cfg.Email.Layout.Order, "emailOrder", layout.Order, "order within digest sections: priority, recency or score (default: priority)")
+	flag.IntVar(&cfg.Email.Layout.GroupLimit, "emailGroupLimit", layout.GroupLimit, "max articles per digest section, 0 for no limit")
+	flag.IntVar(&cfg.Email.Layout.TopStories, "emailTopStories", layout.TopStories, "number of highest-scoring articles to show as top stories, 0 for none")
+	flag.StringVar(&cfg.Email.Preview, "emailPreview", "", "render the digest without sending and write .eml/.html files to this directory")
+	flag.StringVar(&cfg.Email.PreviewInput, "emailPreviewInput", "", "with -emailPreview: read headlines from this JSON file instead of the store")
+	flag.StringVar(&cfg.Email.Rollup, "rollup", "", "send a weekly or monthly roll-up digest with statistics to EMAIL_TO (weekly or monthly)")
+	flag.StringVar(&cfg.Email.RollupEnd, "rollupEnd", "", "with -rollup: end date YYYY-MM-DD, the roll-up covers the period before this day (default: today)")
+	flag.StringVar(&cfg.Email.Type, "emailType", os.Getenv("EMAIL_TYPE"), "with -emailPreview: digest to render, short or full (default: short)")
+
+	// アラートフラグ（抑制時間・通知先は環境変数のみ、alerts.go）
+	cfg.Alerts, err = AlertConfigFromEnv(".")
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	flag.StringVar(&cfg.Alerts.RulesFile, "alertRules", cfg.Alerts.RulesFile, "JSON alert rules evaluated right after collecting; matches are sent to ALERT_WEBHOOK_URL and/or ALERT_EMAIL_TO")
+	flag.StringVar(&cfg.Alerts.StateFile, "alertState", cfg.Alerts.StateFile, "state file recording alerted stories for suppression")
+
+	// フィルタフラグ
+	flag.StringVar(&cfg.Filter.FiltersFile, "filters", os.Getenv("SOURCE_FILTERS_FILE"), "optional: JSON file with per-source filter expressions")
+	flag.StringVar(&cfg.Filter.Expression, "filter", "", "filter expression to evaluate with -filterExplain (default: per-source filters)")
+	flag.BoolVar(&cfg.Filter.Explain, "filterExplain", false, "show which filter terms matched each headline instead of writing output")
+
+	// 価格時系列フラグ
+	flag.BoolVar(&cfg.Prices.Enabled, "priceSeries", false, "output extracted carbon prices/volumes as a time series and exit")
+	flag.StringVar(&cfg.Prices.From, "seriesFrom", "", "price series start date (YYYY-MM-DD)")
+	flag.StringVar(&cfg.Prices.To, "seriesTo", "", "price series end date (YYYY-MM-DD)")
+	flag.StringVar(&cfg.Prices.Format, "seriesFormat", "csv", "price series output format: csv or json")
+	flag.StringVar(&cfg.Prices.Instrument, "seriesInstrument", "", "only include this instrument (EUA, UKA, CCA, RGGI, ACCU, VCU, NZU, KAU)")
+
+	// Notion管理フラグ
+	flag.BoolVar(&cfg.Notion.Init, "notionInit", false, "create the Notion database under -notionPageID (or verify the existing one), print its ID and exit")
+	flag.StringVar(&cfg.Notion.InitWrite, "notionInitWrite", "", "with -notionInit: also write NOTION_DATABASE_ID to this file (e.g. .env)")
+	flag.BoolVar(&cfg.Notion.Migrate, "notionMigrate", false, "add missing Notion database properties/select options and report schema drift")
+	flag.BoolVar(&cfg.Notion.DryRun, "notionDryRun", false, "with -notionMigrate: only report the changes")
+	flag.BoolVar(&cfg.Notion.Export, "notionExport", false, "export the Notion database (properties and page content) and exit")
+	flag.StringVar(&cfg.Notion.ExportFormat, "exportFormat", "jsonl", "export format: jsonl, csv or markdown (markdown writes one file per page into -out)")
+	flag.StringVar(&cfg.Notion.ExportFrom, "exportFrom", "", "export pages created on or after this date (YYYY-MM-DD)")
+	flag.StringVar(&cfg.Notion.ExportTo, "exportTo", "", "export pages created on or before this date (YYYY-MM-DD)")
+	flag.BoolVar(&cfg.Notion.ExportBody, "exportBody", true, "include page content blocks in the export (one extra request per page)")
 
 	flag.Parse()
 	return cfg
@@ -0,0 +1,221 @@
+// =============================================================================
+// digest_layout.go - ダイジェストのレイアウト（並び順・件数の上限・トップ記事）
+// =============================================================================
+//
+// このファイルはダイジェストの記事の並べ方を決めます。
+// レイアウトは DigestView（email_template.go）を作る段階で適用するため、
+// プレーンテキストとHTMLの本文は同じ並び・同じ件数になります。
+//
+// 【設定】
+//
+//	EMAIL_DIGEST_SECTIONS    (-emailSections)    セクションの区切り方
+//	                                              language / source / topic / type / jurisdiction
+//	EMAIL_DIGEST_ORDER       (-emailOrder)       セクション内の並び順
+//	                                              priority / recency / score
+//	EMAIL_DIGEST_GROUP_LIMIT (-emailGroupLimit)  1セクションに載せる最大件数（0 = 無制限）
+//	EMAIL_DIGEST_TOP         (-emailTopStories)  先頭の「トップ記事」の件数（0 = なし）
+//
+// 【並び順】
+//
+//	priority - 編集者の Priority 順、同じ Priority は作成日時の新しい順（デフォルト、editorial.go）
+//	recency  - 公開日（なければ作成日時）の新しい順
+//	score    - digestScore の高い順
+//
+// 【スコア】（digestScore）
+//   Notion の "Score" は旧モードのマッチングスコアで、現在の記事には入っていないため、
+//   ダイジェスト用に次の合計で計算する:
+//
+//	Priority      High +2 / Low -1
+//	トピック       1つにつき +0.5（最大 +1.5）
+//	エンティティ   レジストリ・プロジェクトID・ETS・6条ペア1つにつき +0.25（最大 +1）
+//	価格・取引量   抽出されていれば +0.5
+//	新しさ         公開から0日で +1、7日で 0 まで直線的に減る
+//
+// 【トップ記事】
+//   スコアの高い順に選んだ記事を先頭に載せ、セクションには重複して載せない。
+//   上限（EMAIL_DIGEST_GROUP_LIMIT）を超えた記事は「他 N 件」として件数だけ表示する。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ダイジェストの並び順
+const (
+	DigestOrderPriority = "priority"
+	DigestOrderRecency  = "recency"
+	DigestOrderScore    = "score"
+)
+
+// DigestLayout はダイジェストのレイアウト
+type DigestLayout struct {
+	Sections   string // セクションの区切り方（language / source / topic / type / jurisdiction）
+	Order      string // セクション内の並び順（priority / recency / score）
+	GroupLimit int    // 1セクションの最大件数（0 = 無制限）
+	TopStories int    // トップ記事の件数（0 = なし）
+}
+
+// DigestLayoutFromEnv は環境変数からレイアウトを読み込む
+//
+// 件数が数値でない場合はその項目を0としてエラーも返す。
+// 区切り方・並び順の検証は EmailSender.SetLayout で行う。
+func DigestLayoutFromEnv() (DigestLayout, error) {
+	groupLimit, errLimit := envCount("EMAIL_DIGEST_GROUP_LIMIT")
+	topStories, errTop := envCount("EMAIL_DIGEST_TOP")
+	return DigestLayout{
+		Sections:   os.Getenv("EMAIL_DIGEST_SECTIONS"),
+		Order:      os.Getenv("EMAIL_DIGEST_ORDER"),
+		GroupLimit: groupLimit,
+		TopStories: topStories,
+	}, errors.Join(errLimit, errTop)
+}
+
+// envCount は0以上の整数の環境変数を読み込む（空の場合は0）
+func envCount(name string) (int, error) {
+	s := strings.TrimSpace(os.Getenv(name))
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, s)
+	}
+	return n, nil
+}
+
+// normalize はレイアウトを検証し、空の項目をデフォルトにする
+func (l DigestLayout) normalize() (DigestLayout, error) {
+	sections, err := parseDigestSections(l.Sections)
+	if err != nil {
+		return l, err
+	}
+	l.Sections = sections
+
+	l.Order = strings.ToLower(strings.TrimSpace(l.Order))
+	switch l.Order {
+	case "":
+		l.Order = DigestOrderPriority
+	case DigestOrderPriority, DigestOrderRecency, DigestOrderScore:
+	default:
+		return l, fmt.Errorf("unsupported EMAIL_DIGEST_ORDER %q (use priority, recency or score)", l.Order)
+	}
+
+	if l.GroupLimit < 0 {
+		return l, fmt.Errorf("EMAIL_DIGEST_GROUP_LIMIT must not be negative")
+	}
+	if l.TopStories < 0 {
+		return l, fmt.Errorf("EMAIL_DIGEST_TOP must not be negative")
+	}
+	return l, nil
+}
+
+// orderHeadlines は並び順に従って記事を並べ替えたコピーを返す
+//
+// priority の場合は入力の順序（applyEditorialStatus で並べ替え済み）をそのまま使う。
+func orderHeadlines(headlines []NotionHeadline, order string, now time.Time) []NotionHeadline {
+	sorted := append([]NotionHeadline(nil), headlines...)
+	switch order {
+	case DigestOrderRecency:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return headlineTime(sorted[i]).After(headlineTime(sorted[j]))
+		})
+	case DigestOrderScore:
+		sortByScore(sorted, now)
+	}
+	return sorted
+}
+
+// sortByScore はスコアの高い順に並べ替える（同点は元の順序）
+func sortByScore(headlines []NotionHeadline, now time.Time) {
+	ranked := make([]NotionHeadline, len(headlines))
+	for i, k := range rankByScore(headlines, now) {
+		ranked[i] = headlines[k]
+	}
+	copy(headlines, ranked)
+}
+
+// rankByScore は記事のインデックスをスコアの高い順に返す（同点は元の順序）
+func rankByScore(headlines []NotionHeadline, now time.Time) []int {
+	scores := make([]float64, len(headlines))
+	idx := make([]int, len(headlines))
+	for i, h := range headlines {
+		scores[i] = digestScore(h, now)
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return scores[idx[a]] > scores[idx[b]]
+	})
+	return idx
+}
+
+// splitTopStories はスコアの高い n 件をトップ記事として取り出し、残りを元の順序で返す
+func splitTopStories(headlines []NotionHeadline, n int, now time.Time) (top, rest []NotionHeadline) {
+	if n <= 0 || len(headlines) == 0 {
+		return nil, headlines
+	}
+	ranked := rankByScore(headlines, now)
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	picked := make(map[int]bool, n)
+	for _, k := range ranked[:n] {
+		top = append(top, headlines[k])
+		picked[k] = true
+	}
+	for i, h := range headlines {
+		if !picked[i] {
+			rest = append(rest, h)
+		}
+	}
+	return top, rest
+}
+
+// digestScore はダイジェスト上の重要度を返す（ファイル冒頭の【スコア】参照）
+func digestScore(h NotionHeadline, now time.Time) float64 {
+	score := 0.0
+	switch h.Priority {
+	case PriorityHigh:
+		score += 2
+	case PriorityLow:
+		score--
+	}
+
+	score += math.Min(float64(len(h.Topics))*0.5, 1.5)
+
+	if e := h.Entities; e != nil {
+		n := len(e.Registries) + len(e.ProjectIDs) + len(e.ETS) + len(e.Article6Pairs)
+		score += math.Min(float64(n)*0.25, 1)
+	}
+
+	if len(h.Prices) > 0 {
+		score += 0.5
+	}
+
+	if t := headlineTime(h); !t.IsZero() {
+		ageDays := now.Sub(t).Hours() / 24
+		score += math.Max(0, 1-math.Max(ageDays, 0)/7)
+	}
+	return score
+}
+
+// headlineTime は記事の公開日（なければ作成日時）を返す（解釈できない場合はゼロ値）
+func headlineTime(h NotionHeadline) time.Time {
+	for _, s := range []string{h.PublishedDate, h.CreatedAt} {
+		if s == "" {
+			continue
+		}
+		if t, err := parsePublishedDate(s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,131 @@
+// =============================================================================
+// editorial.go - Notionの編集ステータス（人手によるキュレーション）
+// =============================================================================
+//
+// このファイルは編集者がNotion上で設定した編集プロパティを、メールダイジェストに反映します。
+//
+// 【背景】
+//   パイプラインはNotionから Title・URL・Source・Type・Article Summary 300・Published Date
+//   しか読んでおらず、編集者が記事を除外したり重要度を付けてもメールに反映されなかった。
+//   （Article Summary 300 の編集は従来どおりそのまま反映される）
+//
+// 【編集プロパティ】
+//
+//	Include in digest（チェックボックス）→ チェックを外した記事はメールに載せない
+//	Priority（セレクト: High / Normal / Low）→ High を先頭、Low を末尾に並べる
+//	Editor note（テキスト）                → 記事の下に「📝 」付きで表示
+//
+//	未設定の記事はダイジェストに含める。ただしNotionのチェックボックスには「未設定」がなく
+//	false として返るため、未設定の状態を作らないようにしている。
+//	  - クリップ時は "Include in digest" にチェックを入れる
+//	  - マイグレーションでプロパティを追加したときは、その時点の全ページにチェックを入れる
+//	    （includeExistingPages。追加前からあるページが除外されないように）
+//	  - プロパティ自体がない（マイグレーション前の）データベース・ページでは全記事を含める
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// 編集プロパティ名
+const (
+	EditorialIncludeProperty  = "Include in digest"
+	EditorialPriorityProperty = "Priority"
+	EditorialNoteProperty     = "Editor note"
+)
+
+// Priority の値
+const (
+	PriorityHigh   = "High"
+	PriorityNormal = "Normal"
+	PriorityLow    = "Low"
+)
+
+// EditorNoteLabel はメール上で編集者のメモであることを示すラベル
+const EditorNoteLabel = "📝 "
+
+// priorityRank は並び順（小さいほど先頭、未設定・不明な値は Normal 扱い）
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+	return 1
+}
+
+// editorialFromProperties はページのプロパティから編集ステータスを読み取る
+func editorialFromProperties(props notionapi.Properties) (excluded bool, priority, note string) {
+	if prop, ok := props[EditorialIncludeProperty].(*notionapi.CheckboxProperty); ok {
+		excluded = !prop.Checkbox
+	}
+	if prop, ok := props[EditorialPriorityProperty].(*notionapi.SelectProperty); ok {
+		priority = prop.Select.Name
+	}
+	if prop, ok := props[EditorialNoteProperty].(*notionapi.RichTextProperty); ok {
+		note = strings.TrimSpace(plainText(prop.RichText))
+	}
+	return excluded, priority, note
+}
+
+// includeExistingPages はデータベースの全ページの "Include in digest" にチェックを入れる
+//
+// マイグレーションでプロパティを追加した直後に呼ぶ（MigrateSchema）。追加前からあるページは
+// チェックボックスが false のため、そのままでは編集者が除外した記事と区別できない。
+// ページング中の更新で順序が変わらないよう、先にIDを集めてから更新する。戻り値は更新したページ数。
+func (nc *NotionClipper) includeExistingPages(ctx context.Context) (int, error) {
+	var ids []notionapi.PageID
+	err := nc.queryPages(ctx, HeadlineQuery{}, func(page notionapi.Page) error {
+		ids = append(ids, notionapi.PageID(page.ID))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req := &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{
+			EditorialIncludeProperty: notionapi.CheckboxProperty{
+				Type:     notionapi.PropertyTypeCheckbox,
+				Checkbox: true,
+			},
+		},
+	}
+	for i, id := range ids {
+		err := notionRetry(ctx, "Page.Update", func() error {
+			_, updateErr := nc.client.Page.Update(ctx, id, req)
+			return updateErr
+		})
+		if err != nil {
+			return i, fmt.Errorf("checked %q on %d/%d existing pages; check the rest in Notion or they stay out of the digest: %w",
+				EditorialIncludeProperty, i, len(ids), err)
+		}
+	}
+	return len(ids), nil
+}
+
+// applyEditorialStatus は編集者が除外した記事を取り除き、Priority順に並べ替える
+//
+// 同じ Priority の記事は元の順序（作成日時の新しい順）を保つ。
+// 戻り値は残った記事と除外した件数。
+func applyEditorialStatus(headlines []NotionHeadline) ([]NotionHeadline, int) {
+	kept := make([]NotionHeadline, 0, len(headlines))
+	for _, h := range headlines {
+		if h.Excluded {
+			continue
+		}
+		kept = append(kept, h)
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return priorityRank(kept[i].Priority) < priorityRank(kept[j].Priority)
+	})
+	return kept, len(headlines) - len(kept)
+}
@@ -2,7 +2,8 @@
 // email.go - メール送信モジュール
 // =============================================================================
 //
-// このファイルはGmail SMTPを使用したメール送信機能を提供します。
+// このファイルはダイジェスト・通知メールの生成と送信機能を提供します。
+// 配送はMailer（mailer.go、デフォルトはSMTP、email_smtp.go で変更可能）が行います。
 // Carbon Relayのモード1（無料記事収集）で、ニュースレター配信に使用されます。
 //
 // =============================================================================
@@ -10,17 +11,24 @@
 // =============================================================================
 //
 // 1. Notionデータベースから最近の記事を取得
-// 2. プレーンテキスト形式のメール本文を生成
-// 3. RFC 5322準拠のメールメッセージを構築
-// 4. Gmail SMTP経由で送信（リトライ付き）
+// 2. テンプレートからHTMLとプレーンテキストの本文を生成（email_template.go）
+// 3. multipart/alternative のメールメッセージを構築
+// 4. Mailer（SMTP / ファイル / HTTP API）で送信（リトライ付き）
 //
 // =============================================================================
 // 【必要な環境変数】
 // =============================================================================
 //
-//   EMAIL_FROM     - 送信元メールアドレス（Gmail）
-//   EMAIL_PASSWORD - Gmailアプリパスワード（通常のパスワードではない！）
+//   EMAIL_FROM     - 送信元メールアドレス
+//   EMAIL_PASSWORD - SMTP認証のパスワード（Gmailの場合はアプリパスワード、SMTP_AUTH=none の場合は不要）
 //   EMAIL_TO       - 送信先メールアドレス（カンマ区切りで複数可）
+//   EMAIL_LANGUAGE - ダイジェストに含める言語（ja / en、省略時は全言語）
+//   EMAIL_TEMPLATE_DIR    - メールテンプレートの差し替え先ディレクトリ（省略時は埋め込み）
+//   EMAIL_DIGEST_SECTIONS - セクションの区切り方（language / source / topic / type / jurisdiction）
+//   EMAIL_DIGEST_ORDER / EMAIL_DIGEST_GROUP_LIMIT / EMAIL_DIGEST_TOP - 並び順・上限・トップ記事（digest_layout.go）
+//   EMAIL_LIST_ID / EMAIL_LIST_UNSUBSCRIBE - ダイジェストの List-* ヘッダー（email_mime.go）
+//   SMTP_HOST / SMTP_PORT / SMTP_SECURITY / SMTP_AUTH 等 - SMTPサーバーの設定（email_smtp.go）
+//   MAILER / MAILER_DIR / MAILER_HTTP_URL 等 - 配送先の切り替え（mailer.go）
 //
 // =============================================================================
 // 【Gmailアプリパスワードについて】
@@ -41,7 +49,7 @@
 // =============================================================================
 //
 // - SMTPはメール送信のための標準プロトコル
-// - Gmail SMTPはポート587（TLS）を使用
+// - デフォルトはGmail SMTP（ポート587、STARTTLS）
 // - 指数バックオフ: 失敗時に2秒→4秒→8秒と待機時間を増やしてリトライ
 // - RFC 5322: メールフォーマットの標準規格
 //
@@ -52,7 +60,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"net/smtp"
 	"os"
 	"strings"
 	"time"
@@ -67,13 +74,18 @@ type EmailConfig struct {
 	From     string   // 送信元メールアドレス
 	Password string   // Gmailアプリパスワード
 	To       []string // 送信先メールアドレス（複数可）
-	SMTPHost string   // SMTPサーバーホスト（"smtp.gmail.com"）
-	SMTPPort string   // SMTPポート（"587"）
+	ToName   string   // 送信先の表示名（購読者ごとに1人へ送る場合のみ、subscribers.go）
+	Language string   // ダイジェストに含める言語（"ja" / "en"、空の場合は全言語）
+
+	TemplateDir string       // メールテンプレートの差し替え先（空の場合は埋め込みのみ）
+	Layout      DigestLayout // セクション・並び順・件数の上限・トップ記事（digest_layout.go）
+	List        ListHeaders  // ダイジェストの List-Id・List-Unsubscribe（email_mime.go）
 }
 
 // EmailSender はメール送信を担当する
 type EmailSender struct {
 	config EmailConfig
+	mailer Mailer // 配送先（mailer.go）
 }
 
 // =============================================================================
@@ -85,79 +97,126 @@ type EmailSender struct {
 // 引数:
 //
 //	from:     送信元メールアドレス
-//	password: Gmailアプリパスワード
+//	password: SMTP認証のパスワード（SMTP_AUTH=none の場合は空でよい）
 //	to:       送信先メールアドレス（カンマ区切りで複数可）
 //
-// 【注意】通常のGmailパスワードは使用できません。
-// 必ずアプリパスワードを使用してください。
+// 配送先は環境変数（MAILER 等、mailer.go）から、SMTPサーバーの設定は
+// 環境変数（SMTP_HOST 等、email_smtp.go）から読み込む。
+// 未設定の場合はGmail（smtp.gmail.com:587、STARTTLS、PLAIN認証）を使う。
+// 【注意】Gmailでは通常のパスワードは使用できません。アプリパスワードを使用してください。
 func NewEmailSender(from, password, to string) (*EmailSender, error) {
-	// 必須パラメータのチェック
-	if from == "" {
-		return nil, fmt.Errorf("EMAIL_FROM is required")
-	}
-	if password == "" {
-		return nil, fmt.Errorf("EMAIL_PASSWORD is required (use Gmail App Password)")
-	}
 	if to == "" {
 		return nil, fmt.Errorf("EMAIL_TO is required")
 	}
+	es, err := NewSubscriberSender(from, password)
+	if err != nil {
+		return nil, err
+	}
 
 	// カンマ区切りのメールアドレスを分割
 	toList := strings.Split(to, ",")
 	for i, addr := range toList {
 		toList[i] = strings.TrimSpace(addr)
 	}
+	es.config.To = toList
+	return es, nil
+}
+
+// NewSubscriberSender は宛先を持たないメール送信者を作成する
+//
+// 宛先は購読者リスト（subscribers.go）から1人ずつ設定するため、EMAIL_TO は不要。
+func NewSubscriberSender(from, password string) (*EmailSender, error) {
+	mailerCfg, err := MailerConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	mailer, err := NewMailer(mailerCfg, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 必須パラメータのチェック
+	if from == "" {
+		return nil, fmt.Errorf("EMAIL_FROM is required")
+	}
+	if sm, ok := mailer.(*SMTPMailer); ok && password == "" && sm.Config.RequiresPassword() {
+		return nil, fmt.Errorf("EMAIL_PASSWORD is required for SMTP_AUTH=%s (set SMTP_AUTH=none for relays without authentication)", sm.Config.Auth)
+	}
+	list, err := ListHeadersFromEnv(from)
+	if err != nil {
+		return nil, err
+	}
 
 	return &EmailSender{
 		config: EmailConfig{
 			From:     from,
 			Password: password,
-			To:       toList,
-			SMTPHost: "smtp.gmail.com",
-			SMTPPort: "587", // TLSポート
+			Layout:   DigestLayout{Sections: DigestSectionsLanguage, Order: DigestOrderPriority},
+			List:     list,
 		},
+		mailer: mailer,
 	}, nil
 }
 
-// =============================================================================
-// メール送信
-// =============================================================================
+// SetMailer は配送先を差し替える（プレビューやテストでファイルに書き出す場合など）
+func (es *EmailSender) SetMailer(m Mailer) {
+	es.mailer = m
+}
 
-// SendHeadlinesSummary は見出しサマリーメールを送信する
-//
-// 【処理の流れ】
-//  1. メール本文を生成
-//  2. 件名を生成（日付と記事数を含む）
-//  3. RFC 5322準拠のメッセージを構築
-//  4. リトライ付きで送信
-func (es *EmailSender) SendHeadlinesSummary(ctx context.Context, headlines []NotionHeadline) error {
-	var body, subject string
-	if len(headlines) == 0 {
-		subject = fmt.Sprintf("Carbon News Headlines - %s (0 articles)",
-			time.Now().Format("2006-01-02"))
-		body = fmt.Sprintf("Carbon News Headlines Summary\nGenerated: %s\n\n========================================\nNo headlines found for this period.\n========================================\n",
-			time.Now().Format("2006-01-02 15:04:05"))
-	} else {
-		body = es.generateEmailBody(headlines)
-		subject = fmt.Sprintf("Carbon News Headlines - %s (%d articles)",
-			time.Now().Format("2006-01-02"),
-			len(headlines))
+// Mailer は現在の配送先を返す
+func (es *EmailSender) Mailer() Mailer {
+	return es.mailer
+}
+
+// SetLanguage はダイジェストに含める記事の言語を設定する（EMAIL_LANGUAGE）
+//
+// 空文字または "all" の場合は全言語を含める。
+func (es *EmailSender) SetLanguage(lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch lang {
+	case "", "all":
+		es.config.Language = ""
+	case LangJapanese, LangEnglish:
+		es.config.Language = lang
+	default:
+		return fmt.Errorf("unsupported EMAIL_LANGUAGE %q (use ja, en or all)", lang)
 	}
+	return nil
+}
 
-	// RFC 5322準拠のメッセージを構築
-	msg := es.BuildEmailMessage(subject, body)
+// SetTemplateDir はメールテンプレートの差し替え先を設定する（EMAIL_TEMPLATE_DIR）
+//
+// ディレクトリに置かれたテンプレートだけが埋め込みのテンプレートの代わりに使われる。
+func (es *EmailSender) SetTemplateDir(dir string) {
+	es.config.TemplateDir = strings.TrimSpace(dir)
+}
 
-	// リトライ付きで送信
-	return es.SendWithRetry(msg)
+// SetLayout はダイジェストのレイアウトを設定する（EMAIL_DIGEST_SECTIONS / EMAIL_DIGEST_ORDER 等）
+//
+// 空の項目はデフォルト（言語別、Priority順、上限なし、トップ記事なし）。
+func (es *EmailSender) SetLayout(layout DigestLayout) error {
+	layout, err := layout.normalize()
+	if err != nil {
+		return err
+	}
+	es.config.Layout = layout
+	return nil
 }
 
 // =============================================================================
-// メール本文生成
+// メール送信
 // =============================================================================
 
-// generateEmailBody はプレーンテキストのメール本文を生成する
+// SendHeadlinesSummary は見出しサマリーメールを送信する
 //
-// 【出力フォーマット】
+// 【処理の流れ】
+//  1. 編集者が除外した記事を取り除き、Priority順に並べ替え（editorial.go）
+//  2. テンプレート（digest_full）からHTMLとプレーンテキストの本文を生成
+//  3. 件名を生成（日付と記事数を含む）
+//  4. multipart/alternative のメッセージを構築
+//  5. リトライ付きで送信
+//
+// 【プレーンテキストの形式】（templates/digest_full.txt.tmpl）
 //
 //	Carbon News Headlines Summary
 //	Generated: 2026-01-05 12:00:00
@@ -169,74 +228,105 @@ func (es *EmailSender) SendHeadlinesSummary(ctx context.Context, headlines []Not
 //	[1] Title: "記事タイトル"
 //	    Source: Carbon Pulse
 //	    URL: https://...
+//	    Priority: High            ← 編集者が設定した場合のみ
 //
-//	    Summary:
-//	    記事の要約テキスト...
+//	    Summary: 記事の要約テキスト...
+//	    Editor note: ...          ← 編集者が設定した場合のみ
 //
 //	----------------------------------------
-func (es *EmailSender) generateEmailBody(headlines []NotionHeadline) string {
-	var sb strings.Builder
-
-	// ヘッダー
-	sb.WriteString("Carbon News Headlines Summary\n")
-	sb.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
-	sb.WriteString("========================================\n")
-	sb.WriteString(fmt.Sprintf("Total Headlines: %d\n", len(headlines)))
-	sb.WriteString("========================================\n\n")
-
-	// 各記事
-	for i, h := range headlines {
-		sb.WriteString(fmt.Sprintf("[%d] Title: \"%s\"\n", i+1, h.Title))
-		sb.WriteString(fmt.Sprintf("    Source: %s\n", h.Source))
-		sb.WriteString(fmt.Sprintf("    URL: %s\n", h.URL))
-		sb.WriteString("\n")
-
-		// ShortHeadlineがある場合は表示
-		if h.ShortHeadline != "" {
-			sb.WriteString(fmt.Sprintf("    Summary: %s\n", h.ShortHeadline))
-		} else {
-			sb.WriteString("    Summary: (No summary available)\n")
-		}
-
-		sb.WriteString("\n")
-		sb.WriteString("----------------------------------------\n\n")
+func (es *EmailSender) SendHeadlinesSummary(ctx context.Context, headlines []NotionHeadline) error {
+	digest, err := es.renderFullDigest(headlines)
+	if err != nil {
+		return err
 	}
 
-	// フッター
-	sb.WriteString("\n")
-	sb.WriteString("Generated by carbon-relay\n")
-	sb.WriteString("https://github.com/FuseKota/curbon-search\n")
+	// HTMLとプレーンテキストの multipart/alternative メッセージを構築
+	msg := es.BuildMultipartMessage(digest.Subject, digest.Text, digest.HTML)
+
+	// リトライ付きで送信
+	return es.SendWithRetry(ctx, msg)
+}
+
+// renderedDigest は件名と本文を生成済みのダイジェスト
+type renderedDigest struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// renderFullDigest は見出しサマリー（digest_full）の件名と本文を生成する
+func (es *EmailSender) renderFullDigest(headlines []NotionHeadline) (*renderedDigest, error) {
+	headlines, excluded := applyEditorialStatus(headlines)
+	if excluded > 0 {
+		fmt.Fprintf(os.Stderr, "Excluded %d articles unchecked in \"%s\"\n", excluded, EditorialIncludeProperty)
+	}
+	// 受信者の言語設定（EMAIL_LANGUAGE）と異なる記事は除外（50文字ダイジェストと同じ）
+	headlines, skippedLanguage := es.filterLanguage(headlines)
+	if skippedLanguage > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d articles not in language %q (EMAIL_LANGUAGE)\n", skippedLanguage, es.config.Language)
+	}
 
-	return sb.String()
+	subject := fmt.Sprintf("Carbon News Headlines - %s (%d articles)",
+		time.Now().Format("2006-01-02"),
+		len(headlines))
+	view := newDigestView("Carbon News Headlines Summary", headlines, es.config.Layout)
+	text, html, err := renderDigest(es.config.TemplateDir, DigestTemplateFull, view)
+	if err != nil {
+		return nil, err
+	}
+	return &renderedDigest{Subject: subject, Text: text, HTML: html}, nil
 }
 
 // =============================================================================
 // メールメッセージ構築
 // =============================================================================
 
-// buildEmailMessage はRFC 5322準拠のメールメッセージを構築する
+// BuildEmailMessage はプレーンテキストのメールメッセージを構築する（エラー通知用）
 //
-// 【RFC 5322フォーマット】
+// 【RFC 5322フォーマット】（email_mime.go）
 //
 //	From: sender@example.com\r\n
 //	To: recipient@example.com\r\n
-//	Subject: メール件名\r\n
+//	Subject: =?UTF-8?b?...?=            ← ASCII以外はRFC 2047でエンコード
+//	Date / Message-ID / MIME-Version
 //	Content-Type: text/plain; charset=UTF-8\r\n
+//	Content-Transfer-Encoding: quoted-printable（日本語中心の場合は base64）\r\n
 //	\r\n
 //	メール本文...
 //
 // 注意: ヘッダーと本文は空行（\r\n）で区切る
 func (es *EmailSender) BuildEmailMessage(subject, body string) []byte {
-	var msg strings.Builder
+	return es.buildMessage(subject, body, "", false)
+}
 
-	msg.WriteString(fmt.Sprintf("From: %s\r\n", es.config.From))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(es.config.To, ", ")))
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-	msg.WriteString("\r\n") // ヘッダーと本文の区切り
-	msg.WriteString(body)
+// BuildMultipartMessage はHTMLとプレーンテキストの本文を持つダイジェストのメッセージを構築する
+//
+// 【構造】
+//
+//	Content-Type: multipart/alternative; boundary=...
+//	  ├─ text/plain（HTMLを表示しないクライアント用）
+//	  └─ text/html
+//
+// メールクライアントは対応している最後のパート（通常はHTML）を表示する。
+// ダイジェストなので List-Id・List-Unsubscribe も付ける。
+func (es *EmailSender) BuildMultipartMessage(subject, text, html string) []byte {
+	return es.buildMessage(subject, text, html, true)
+}
 
-	return []byte(msg.String())
+// buildMessage はメッセージを組み立てる（html が空の場合は text/plain のみ、digest の場合は List-* ヘッダー付き）
+func (es *EmailSender) buildMessage(subject, text, html string, digest bool) []byte {
+	msg := &emailMessage{
+		From:    es.config.From,
+		To:      es.config.To,
+		ToName:  es.config.ToName,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+	}
+	if digest {
+		msg.List = &es.config.List
+	}
+	return msg.Bytes()
 }
 
 // =============================================================================
@@ -253,7 +343,8 @@ func (es *EmailSender) BuildEmailMessage(subject, body string) []byte {
 //	3回目失敗: 8秒待機
 //
 // これにより、一時的なネットワーク障害やサーバー過負荷に対応できる
-func (es *EmailSender) SendWithRetry(msg []byte) error {
+// ctx がキャンセルされた場合は待機を中断し、それまでのエラーを返す。
+func (es *EmailSender) SendWithRetry(ctx context.Context, msg []byte) error {
 	maxRetries := 3 // 最大リトライ回数
 	var lastErr error
 
@@ -262,11 +353,15 @@ func (es *EmailSender) SendWithRetry(msg []byte) error {
 			// 指数バックオフ: 2^i 秒待機
 			wait := time.Duration(math.Pow(2, float64(i))) * time.Second
 			fmt.Fprintf(os.Stderr, "Retrying email send in %v...\n", wait)
-			time.Sleep(wait)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("email send canceled after %d attempt(s): %w (last error: %v)", i, ctx.Err(), lastErr)
+			case <-time.After(wait):
+			}
 		}
 
 		// 送信を試行
-		err := es.send(msg)
+		err := es.send(ctx, msg)
 		if err == nil {
 			return nil // 成功
 		}
@@ -278,25 +373,16 @@ func (es *EmailSender) SendWithRetry(msg []byte) error {
 	return fmt.Errorf("failed to send email after %d retries: %w", maxRetries, lastErr)
 }
 
-// send はGmail SMTPを使用してメールを送信する
+// send はMailerでメールを1回送信する
 //
-// 【SMTP認証】
+// 【配送先】
 //
-//	PLAIN認証を使用（ユーザー名とパスワードを送信）
-//	TLS（ポート587）で暗号化されるため安全
-func (es *EmailSender) send(msg []byte) error {
-	// PLAIN認証を設定
-	auth := smtp.PlainAuth("", es.config.From, es.config.Password, es.config.SMTPHost)
-
-	// SMTPサーバーアドレス
-	addr := es.config.SMTPHost + ":" + es.config.SMTPPort
-
-	// メール送信
-	err := smtp.SendMail(addr, auth, es.config.From, es.config.To, msg)
-	if err != nil {
-		return fmt.Errorf("SMTP send failed: %w (check EMAIL_PASSWORD is a Gmail App Password)", err)
+//	MAILER（mailer.go）で選んだ Mailer に渡す。デフォルトはSMTPで、
+//	接続方式・認証方式は SMTP_SECURITY / SMTP_AUTH（email_smtp.go）に従う
+func (es *EmailSender) send(ctx context.Context, msg []byte) error {
+	if err := es.mailer.Send(ctx, es.config.From, es.config.To, msg); err != nil {
+		return fmt.Errorf("send via %s failed: %w", es.mailer.Name(), err)
 	}
-
 	return nil
 }
 
@@ -307,25 +393,68 @@ func (es *EmailSender) send(msg []byte) error {
 // SendShortHeadlinesDigest は50文字ヘッドラインのダイジェストメールを送信する
 //
 // 【処理の流れ】
-//  1. Article Summary 300が"-"の記事を除外
-//  2. 番号付きリスト + URL形式で本文生成
-//  3. リトライ付きで送信
+//  1. 編集者が除外した記事を取り除き、Priority順に並べ替え（editorial.go）
+//  2. Article Summary 300が未生成の記事は抽出型要約で代替（summarize.go）
+//  3. Article Summary 300が"-"の記事、対象言語以外の記事を除外
+//  4. テンプレート（digest_short）からHTMLとプレーンテキストの本文を生成
+//     （レイアウトは EMAIL_DIGEST_SECTIONS 等（digest_layout.go）、Priority: High は "★"、Editor note は "📝" 付き）
+//  5. リトライ付きで送信
 //
-// 【メール形式】
+// 【プレーンテキストの形式】（templates/digest_short.txt.tmpl）
 //
-//	Carbon Headlines Digest - 2026-01-06
-//	Total: 25 articles
+//	炭素関連記事一覧 - 2026-01-06
+//	合計: 25 記事
 //
-//	1. EU carbon prices hit record high...
-//	   https://carbonherald.com/...
+//	■ 日本語 (10)                ← セクションが2つ以上の場合のみ
 //
-//	2. Japan launches new GX initiative...
+//	1. [News] GX-ETSの制度詳細が公表...
 //	   https://carboncredits.jp/...
+//
+// 番号は全体の通し番号。HTML版は記事タイトルのリンク・ソースのバッジ・種別・要約を表示する。
 func (es *EmailSender) SendShortHeadlinesDigest(ctx context.Context, headlines []NotionHeadline) error {
+	digest, err := es.renderShortDigest(headlines)
+	if err != nil {
+		return err
+	}
+
+	// HTMLとプレーンテキストの multipart/alternative メッセージを構築
+	msg := es.BuildMultipartMessage(digest.Subject, digest.Text, digest.HTML)
+
+	// リトライ付きで送信
+	return es.SendWithRetry(ctx, msg)
+}
+
+// filterLanguage は受信者の言語設定（EMAIL_LANGUAGE）の記事だけを返す（未設定の場合はそのまま）
+func (es *EmailSender) filterLanguage(headlines []NotionHeadline) ([]NotionHeadline, int) {
+	if es.config.Language == "" {
+		return headlines, 0
+	}
+	kept := make([]NotionHeadline, 0, len(headlines))
+	for _, h := range headlines {
+		if h.Language == es.config.Language {
+			kept = append(kept, h)
+		}
+	}
+	return kept, len(headlines) - len(kept)
+}
+
+// renderShortDigest は50文字ダイジェスト（digest_short）の件名と本文を生成する
+func (es *EmailSender) renderShortDigest(headlines []NotionHeadline) (*renderedDigest, error) {
+	// 編集者が除外した記事を取り除き、Priority順に並べ替え（editorial.go）
+	total := len(headlines)
+	headlines, skippedEditor := applyEditorialStatus(headlines)
+
+	// Notion AIが要約していない記事は抽出型要約で代替（"-"は代替しない）
+	if n := applyExtractedSummaries(headlines); n > 0 {
+		fmt.Fprintf(os.Stderr, "Using extracted summaries for %d articles without Article Summary 300\n", n)
+	}
+
 	// Article Summary 300が空または"-"、Published Dateが空の記事を除外
 	filtered := make([]NotionHeadline, 0, len(headlines))
 	skippedNoSummary := 0
 	skippedNoDate := 0
+	// 受信者の言語設定（EMAIL_LANGUAGE）と異なる記事は除外
+	headlines, skippedLanguage := es.filterLanguage(headlines)
 	for _, h := range headlines {
 		// Article Summary 300が空・"-"系の場合は除外
 		if h.ShortHeadline == "" || h.ShortHeadline == "-" || h.ShortHeadline == "−" || h.ShortHeadline == "—" {
@@ -339,61 +468,17 @@ func (es *EmailSender) SendShortHeadlinesDigest(ctx context.Context, headlines [
 		}
 		filtered = append(filtered, h)
 	}
-	fmt.Fprintf(os.Stderr, "Filtered: %d → %d articles (skipped: %d no summary, %d no date)\n",
-		len(headlines), len(filtered), skippedNoSummary, skippedNoDate)
-
-	var body, subject string
-	if len(filtered) == 0 {
-		subject = fmt.Sprintf("炭素関連記事一覧 - %s (0 記事)",
-			time.Now().Format("2006-01-02"))
-		body = fmt.Sprintf("炭素関連記事一覧 - %s\n合計: 0 記事\n\nこの期間にカーボン関連の記事は見つかりませんでした。\n",
-			time.Now().Format("2006-01-02"))
-	} else {
-		body = es.generateShortHeadlinesBody(filtered)
-		subject = fmt.Sprintf("炭素関連記事一覧 - %s (%d 記事)",
-			time.Now().Format("2006-01-02"),
-			len(filtered))
-	}
-
-	// RFC 5322準拠のメッセージを構築
-	msg := es.BuildEmailMessage(subject, body)
-
-	// リトライ付きで送信
-	return es.SendWithRetry(msg)
-}
-
-// generateShortHeadlinesBody はArticle Summary 300のメール本文を生成する
-//
-// 【出力フォーマット】
-//
-//	炭素関連記事一覧 - 2026-01-06
-//	合計: 25 記事
-//
-//	1. EU carbon prices hit record high...
-//	   https://carbonherald.com/...
-func (es *EmailSender) generateShortHeadlinesBody(headlines []NotionHeadline) string {
-	var sb strings.Builder
-
-	// ヘッダー
-	sb.WriteString(fmt.Sprintf("炭素関連記事一覧 - %s\n", time.Now().Format("2006-01-02")))
-	sb.WriteString(fmt.Sprintf("合計: %d 記事\n\n", len(headlines)))
-
-	// 各記事（フィルタ済みのためShortHeadlineは必ず存在する）
-	for i, h := range headlines {
-		displayText := h.ShortHeadline
-
-		typeLabel := ""
-		if h.Type != "" {
-			typeLabel = fmt.Sprintf("[%s] ", h.Type)
-		}
-		sb.WriteString(fmt.Sprintf("%d. %s%s\n", i+1, typeLabel, displayText))
-		sb.WriteString(fmt.Sprintf("   %s\n\n", h.URL))
+	fmt.Fprintf(os.Stderr, "Filtered: %d → %d articles (skipped: %d excluded by editor, %d no summary, %d no date, %d other language)\n",
+		total, len(filtered), skippedEditor, skippedNoSummary, skippedNoDate, skippedLanguage)
+
+	subject := fmt.Sprintf("炭素関連記事一覧 - %s (%d 記事)",
+		time.Now().Format("2006-01-02"),
+		len(filtered))
+	heading := fmt.Sprintf("炭素関連記事一覧 - %s", time.Now().Format("2006-01-02"))
+	view := newDigestView(heading, filtered, es.config.Layout)
+	text, html, err := renderDigest(es.config.TemplateDir, DigestTemplateShort, view)
+	if err != nil {
+		return nil, err
 	}
-
-	// フッター
-	sb.WriteString("---\n")
-	sb.WriteString("Generated by carbon-relay\n")
-	sb.WriteString("https://github.com/FuseKota/curbon-search\n")
-
-	return sb.String()
+	return &renderedDigest{Subject: subject, Text: text, HTML: html}, nil
 }
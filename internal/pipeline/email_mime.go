@@ -0,0 +1,324 @@
+// =============================================================================
+// email_mime.go - RFC準拠のメールメッセージの組み立て
+// =============================================================================
+//
+// このファイルはメールのヘッダーと本文をRFCに沿って組み立てます。
+// 以前は日本語の件名（"炭素関連記事一覧 - ..."）を生のUTF-8で書き、Date・Message-ID も
+// 付けず、本文も8bitのまま送っていたため、一部のクライアントで文字化けし、
+// スパムフィルタの評価も下がっていた。
+//
+// 【ヘッダー】
+//
+//	From / To        - 表示名はRFC 2047でエンコード（net/mail.Address）
+//	Subject          - ASCII以外を含む場合はRFC 2047でエンコード
+//	                   （日本語が多い場合はBエンコード、それ以外はQエンコード）
+//	Date             - RFC 5322 の日時
+//	Message-ID       - <ランダム値.時刻@送信元ドメイン>
+//	MIME-Version     - 1.0
+//	List-Id          - ダイジェストのみ（EMAIL_LIST_ID、デフォルト: Carbon Relay <carbon-relay.送信元ドメイン>）
+//	List-Unsubscribe - ダイジェストのみ（EMAIL_LIST_UNSUBSCRIBE を設定した場合）
+//
+//   78文字を超えるヘッダーは空白の位置で折り返す（RFC 5322 2.2.3）。
+//
+// 【本文】
+//   各パートはUTF-8で、改行をCRLFにそろえてから転送エンコードする:
+//     ASCII以外が多い（日本語中心）→ base64、それ以外 → quoted-printable。
+//   どちらも1行76文字以内になるため、SMTPの行長制限（998文字）を超えない。
+//
+// 【配信停止】（EMAIL_LIST_UNSUBSCRIBE）
+//   カンマ区切りの mailto: / https: のURL。{email} は宛先のアドレス（URLエンコード）に置き換える。
+//   https: のURLがある場合は List-Unsubscribe-Post（RFC 8058 のワンクリック配信停止）も付ける。
+//
+//	EMAIL_LIST_UNSUBSCRIBE=mailto:unsubscribe@example.com?subject=unsubscribe,https://example.com/unsub?u={email}
+//
+// =============================================================================
+package pipeline
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxHeaderLineLen は折り返し後のヘッダー1行の目安の長さ（RFC 5322 の推奨は78文字）
+const maxHeaderLineLen = 76
+
+// base64LineLen はbase64の1行の長さ（RFC 2045）
+const base64LineLen = 76
+
+// ListHeaders はダイジェストに付けるメーリングリストのヘッダーの設定
+type ListHeaders struct {
+	ID          string   // List-Id（例: "Carbon Relay <carbon-relay.example.com>"）
+	Unsubscribe []string // List-Unsubscribe のURL（mailto: / https:、{email} は宛先に置換）
+}
+
+// ListHeadersFromEnv は EMAIL_LIST_ID・EMAIL_LIST_UNSUBSCRIBE を読み込み、検証する
+//
+// EMAIL_LIST_ID が空の場合は from のドメインから作る。
+func ListHeadersFromEnv(from string) (ListHeaders, error) {
+	h := ListHeaders{ID: strings.TrimSpace(os.Getenv("EMAIL_LIST_ID"))}
+	if h.ID == "" {
+		h.ID = fmt.Sprintf("Carbon Relay <carbon-relay.%s>", addressDomain(from))
+	}
+	for _, raw := range strings.Split(os.Getenv("EMAIL_LIST_UNSUBSCRIBE"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		lower := strings.ToLower(raw)
+		if !strings.HasPrefix(lower, "mailto:") && !strings.HasPrefix(lower, "https:") && !strings.HasPrefix(lower, "http:") {
+			return h, fmt.Errorf("EMAIL_LIST_UNSUBSCRIBE entry %q must be a mailto: or https: URL", raw)
+		}
+		h.Unsubscribe = append(h.Unsubscribe, raw)
+	}
+	return h, nil
+}
+
+// emailMessage は組み立てるメールの内容
+type emailMessage struct {
+	From    string
+	To      []string
+	ToName  string // To が1人の場合の表示名
+	Subject string
+	Text    string
+	HTML    string       // 空の場合は text/plain のみ
+	List    *ListHeaders // ダイジェストの場合のみ（nilの場合はList-*ヘッダーなし）
+	Date    time.Time
+}
+
+// Bytes はRFC 5322 / MIME 形式のメッセージを返す
+func (m *emailMessage) Bytes() []byte {
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var b strings.Builder
+	writeHeader(&b, "From", formatAddress("", m.From))
+	writeHeader(&b, "To", m.toHeader())
+	writeHeader(&b, "Subject", encodeHeaderText(m.Subject))
+	writeHeader(&b, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&b, "Message-ID", newMessageID(m.From, date))
+	writeHeader(&b, "MIME-Version", "1.0")
+	if m.List != nil {
+		writeListHeaders(&b, m.List, m.To)
+	}
+
+	if m.HTML == "" {
+		encoding, body := encodeBody(m.Text)
+		writeHeader(&b, "Content-Type", "text/plain; charset=UTF-8")
+		writeHeader(&b, "Content-Transfer-Encoding", encoding)
+		b.WriteString("\r\n")
+		b.WriteString(body)
+		return []byte(b.String())
+	}
+
+	// multipart/alternative（テキスト → HTML の順、クライアントは対応する最後のパートを表示）
+	var body strings.Builder
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", m.Text},
+		{"text/html; charset=UTF-8", m.HTML},
+	} {
+		encoding, encoded := encodeBody(part.content)
+		w, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {encoding},
+		})
+		w.Write([]byte(encoded))
+	}
+	mw.Close()
+
+	writeHeader(&b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=\"%s\"", mw.Boundary()))
+	b.WriteString("\r\n")
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
+
+// toHeader はToヘッダーの値を返す（表示名がある場合は "名前 <アドレス>"）
+func (m *emailMessage) toHeader() string {
+	if m.ToName != "" && len(m.To) == 1 {
+		return formatAddress(m.ToName, m.To[0])
+	}
+	addrs := make([]string, len(m.To))
+	for i, a := range m.To {
+		addrs[i] = formatAddress("", a)
+	}
+	return strings.Join(addrs, ", ")
+}
+
+// writeListHeaders は List-Id・List-Unsubscribe を書き込む
+func writeListHeaders(b *strings.Builder, list *ListHeaders, to []string) {
+	if list.ID != "" {
+		writeHeader(b, "List-Id", list.ID)
+	}
+	if len(list.Unsubscribe) == 0 {
+		return
+	}
+	recipient := ""
+	if len(to) == 1 {
+		recipient = to[0]
+		if parsed, err := mail.ParseAddress(recipient); err == nil {
+			recipient = parsed.Address
+		}
+	}
+	urls := make([]string, 0, len(list.Unsubscribe))
+	oneClick := false
+	for _, u := range list.Unsubscribe {
+		u = strings.ReplaceAll(u, "{email}", url.QueryEscape(recipient))
+		urls = append(urls, "<"+u+">")
+		if strings.HasPrefix(strings.ToLower(u), "https:") {
+			oneClick = true
+		}
+	}
+	writeHeader(b, "List-Unsubscribe", strings.Join(urls, ", "))
+	if oneClick {
+		writeHeader(b, "List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+}
+
+// =============================================================================
+// ヘッダーのエンコードと折り返し
+// =============================================================================
+
+// writeHeader は "Name: value" を長さに応じて折り返して書き込む
+func writeHeader(b *strings.Builder, name, value string) {
+	b.WriteString(foldHeader(name+": "+value, maxHeaderLineLen))
+	b.WriteString("\r\n")
+}
+
+// foldHeader は空白の位置で改行（CRLF + 空白）を入れ、各行を limit 文字程度に収める
+//
+// ヘッダー名の直後では折り返さない。空白のない長い語（エンコード済みの語・URL等）は
+// そのままにする（RFC 5322 の上限998文字は超えない前提）。
+func foldHeader(line string, limit int) string {
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	lineLen := 0
+	for i, word := range strings.Split(line, " ") {
+		switch {
+		case i == 0:
+			b.WriteString(word)
+			lineLen = len(word)
+		case i > 1 && lineLen+1+len(word) > limit:
+			b.WriteString("\r\n ")
+			b.WriteString(word)
+			lineLen = 1 + len(word)
+		default:
+			b.WriteString(" ")
+			b.WriteString(word)
+			lineLen += 1 + len(word)
+		}
+	}
+	return b.String()
+}
+
+// encodeHeaderText はASCII以外を含むヘッダーの値をRFC 2047でエンコードする
+func encodeHeaderText(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	if mostlyNonASCII(s) {
+		return mime.BEncoding.Encode("UTF-8", s)
+	}
+	return mime.QEncoding.Encode("UTF-8", s)
+}
+
+// formatAddress はアドレスを "表示名 <アドレス>" 形式にする（表示名はRFC 2047でエンコード）
+//
+// addr が "名前 <アドレス>" 形式の場合はその表示名を使う。解釈できない場合はそのまま返す。
+func formatAddress(name, addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	if name != "" {
+		parsed.Name = name
+	}
+	if parsed.Name == "" {
+		return parsed.Address
+	}
+	return parsed.String()
+}
+
+// newMessageID は一意な Message-ID を作る（ドメインは送信元アドレスのもの）
+func newMessageID(from string, t time.Time) string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return fmt.Sprintf("<%s.%d@%s>", hex.EncodeToString(buf), t.UnixNano(), addressDomain(from))
+}
+
+// addressDomain はメールアドレスのドメインを返す（取れない場合は localhost）
+func addressDomain(addr string) string {
+	if parsed, err := mail.ParseAddress(addr); err == nil {
+		addr = parsed.Address
+	}
+	if i := strings.LastIndex(addr, "@"); i >= 0 && i < len(addr)-1 {
+		return strings.TrimSuffix(addr[i+1:], ">")
+	}
+	return "localhost"
+}
+
+// =============================================================================
+// 本文の転送エンコード
+// =============================================================================
+
+// encodeBody は本文を改行をCRLFにそろえてエンコードし、Content-Transfer-Encoding と共に返す
+func encodeBody(s string) (encoding, body string) {
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+
+	if mostlyNonASCII(s) {
+		encoded := base64.StdEncoding.EncodeToString([]byte(s))
+		var b strings.Builder
+		for len(encoded) > base64LineLen {
+			b.WriteString(encoded[:base64LineLen])
+			b.WriteString("\r\n")
+			encoded = encoded[base64LineLen:]
+		}
+		b.WriteString(encoded)
+		b.WriteString("\r\n")
+		return "base64", b.String()
+	}
+
+	var b strings.Builder
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return "quoted-printable", b.String()
+}
+
+// isASCII は文字列がASCIIのみかを返す
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// mostlyNonASCII はASCII以外のバイトが3分の1を超えるかを返す
+//
+// 日本語はUTF-8で1文字3バイトのため、quoted-printable では約9倍、base64 では約1.3倍になる。
+func mostlyNonASCII(s string) bool {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			n++
+		}
+	}
+	return n*3 > len(s)
+}
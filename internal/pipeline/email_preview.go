@@ -0,0 +1,378 @@
+// =============================================================================
+// email_preview.go - 送信せずにメールを確認する（email preview）
+// =============================================================================
+//
+// このファイルはダイジェストメールを実際には送らずに、送信されるものと同じ
+// 件名・ヘッダー・本文をファイルに書き出す -emailPreview コマンドを提供します。
+//
+// 【仕組み】
+//   送信処理（SendShortHeadlinesDigest 等）をそのまま実行し、配送先だけを CaptureMailer に
+//   差し替える（mailer.go）。そのためプレビューは送信されるメッセージとバイト単位で同じになる。
+//   購読者リスト（-emailSubscribers）を指定した場合は、今日が配信日の購読者ごとに1通ずつ書き出す。
+//   -rollup を指定した場合はまとめメール（rollup.go）を書き出す。
+//
+// 【出力】（-emailPreview のディレクトリ）
+//
+//	01-recipient@example.com.eml   メッセージ全体（メールクライアントで開ける）
+//	01-recipient@example.com.html  HTML本文（ブラウザで確認、テキストのみのメールは .txt）
+//
+// 【入力】
+//   デフォルトは送信時と同じくストアから -emailDaysBack 日分を取得する。
+//   -emailPreviewInput を指定すると、ローカルのJSONファイルの記事を全件使う
+//   （NotionHeadline の配列、またはファイルストア（store_file.go）の保存ファイル）。
+//
+// 【使用方法】
+//
+//	./pipeline -emailPreview out/preview
+//	./pipeline -emailPreview out/preview -emailType full -emailPreviewInput headlines_store.json
+//	./pipeline -emailPreview out/preview -emailSubscribers subscribers.json
+//
+// メール送信Lambdaでは EMAIL_DRY_RUN=true またはイベントの {"dryRun": true} で、
+// 送信せずに同じ内容を Response の preview に返す。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 送信するダイジェストの種類（EMAIL_TYPE / -emailType）
+const (
+	EmailTypeShort = "short"
+	EmailTypeFull  = "full"
+)
+
+// プレビューで EMAIL_FROM / EMAIL_TO が未設定の場合に使うアドレス
+const (
+	previewFrom = "carbon-relay@localhost"
+	previewTo   = "recipient@example.com"
+)
+
+// CapturedEmail は送信の代わりに記録したメッセージ
+type CapturedEmail struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text,omitempty"`
+	HTML    string   `json:"html,omitempty"`
+	Raw     string   `json:"raw"` // RFC 5322 メッセージ全体（.eml）
+}
+
+// CaptureMailer は送信せずにメッセージを記録する Mailer
+type CaptureMailer struct {
+	mu       sync.Mutex
+	messages []CapturedEmail
+}
+
+// Name は配送先の名前を返す
+func (m *CaptureMailer) Name() string {
+	return "preview (not sent)"
+}
+
+// Send はメッセージを解析して記録する
+func (m *CaptureMailer) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	captured, err := parseCapturedEmail(from, to, msg)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.messages = append(m.messages, captured)
+	m.mu.Unlock()
+	return nil
+}
+
+// Messages は記録したメッセージを返す
+func (m *CaptureMailer) Messages() []CapturedEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CapturedEmail(nil), m.messages...)
+}
+
+// parseCapturedEmail はメッセージから件名とテキスト・HTMLの本文を取り出す
+func parseCapturedEmail(from string, to []string, msg []byte) (CapturedEmail, error) {
+	captured := CapturedEmail{From: from, To: to, Raw: string(msg)}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return captured, fmt.Errorf("rendered message is not valid RFC 5322: %w", err)
+	}
+	subject := parsed.Header.Get("Subject")
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+		subject = decoded
+	}
+	captured.Subject = subject
+
+	if err := collectEmailParts(&captured, parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body); err != nil {
+		return captured, fmt.Errorf("failed to parse rendered message: %w", err)
+	}
+	return captured, nil
+}
+
+// collectEmailParts はパートを再帰的にたどり、text/plain と text/html の本文を記録する
+func collectEmailParts(captured *CapturedEmail, contentType, encoding string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := collectEmailParts(captured, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	switch mediaType {
+	case "text/plain":
+		captured.Text = string(content)
+	case "text/html":
+		captured.HTML = string(content)
+	}
+	return nil
+}
+
+// =============================================================================
+// プレビュー用の送信者
+// =============================================================================
+
+// NewPreviewSender は送信の代わりにメッセージを記録する送信者を作成する
+//
+// 認証情報は不要。from・to が空の場合は仮のアドレスを使う（ヘッダーだけに現れる）。
+func NewPreviewSender(from, to string) (*EmailSender, *CaptureMailer) {
+	if from == "" {
+		from = previewFrom
+	}
+	if to == "" {
+		to = previewTo
+	}
+	toList := strings.Split(to, ",")
+	for i, addr := range toList {
+		toList[i] = strings.TrimSpace(addr)
+	}
+	list, err := ListHeadersFromEnv(from)
+	if err != nil {
+		warnf("%v (List-Unsubscribe omitted from the preview)", err)
+	}
+	capture := &CaptureMailer{}
+	return &EmailSender{
+		config: EmailConfig{
+			From:     from,
+			To:       toList,
+			Layout:   DigestLayout{Sections: DigestSectionsLanguage, Order: DigestOrderPriority},
+			List:     list,
+		},
+		mailer: capture,
+	}, capture
+}
+
+// ParseEmailType はダイジェストの種類を検証する（空の場合は defaultType）
+func ParseEmailType(s, defaultType string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return defaultType, nil
+	case EmailTypeShort, EmailTypeFull:
+		return s, nil
+	}
+	return "", fmt.Errorf("unsupported EMAIL_TYPE %q (use short or full)", s)
+}
+
+// LoadPreviewHeadlines はローカルのJSONファイルから記事を読み込む
+//
+// NotionHeadline の配列と、ファイルストアの保存ファイル（{"headlines": [...]}）の両方を受け付ける。
+func LoadPreviewHeadlines(path string) ([]NotionHeadline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading headlines: %w", err)
+	}
+	var headlines []NotionHeadline
+	if err := json.Unmarshal(b, &headlines); err == nil {
+		return headlines, nil
+	}
+	var stored fileStoreData
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, fmt.Errorf("parsing %s: expected a JSON array of headlines or a file store: %w", path, err)
+	}
+	return stored.Headlines, nil
+}
+
+// WriteEmailPreview はメッセージを .eml と .html（テキストのみの場合は .txt）として書き出す
+func WriteEmailPreview(dir string, messages []CapturedEmail) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+
+	var written []string
+	for i, m := range messages {
+		recipient := "message"
+		if len(m.To) > 0 {
+			recipient = m.To[0]
+		}
+		base := filepath.Join(dir, fmt.Sprintf("%02d-%s", i+1, previewFileName(recipient)))
+
+		files := []struct{ path, content string }{{base + ".eml", m.Raw}}
+		if m.HTML != "" {
+			files = append(files, struct{ path, content string }{base + ".html", m.HTML})
+		} else {
+			files = append(files, struct{ path, content string }{base + ".txt", m.Text})
+		}
+		for _, f := range files {
+			if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", f.path, err)
+			}
+			written = append(written, f.path)
+		}
+	}
+	return written, nil
+}
+
+// previewFileName はアドレスをファイル名に使える文字だけにする
+func previewFileName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// =============================================================================
+// email preview ハンドラ
+// =============================================================================
+
+// HandleEmailPreview は送信されるメールを送信せずにファイルへ書き出す
+func HandleEmailPreview(cfg *EmailModeConfig, storeCfg *StoreConfig) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "👀 Email Preview (not sent)")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	emailType, err := ParseEmailType(cfg.Type, EmailTypeShort)
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+
+	var subs []Subscriber
+	now := time.Now()
+	since := time.Time{}
+
+	// -rollup の場合は購読者リストを使わず、EMAIL_TO へのまとめメールを描画する（rollup.go）
+	var period RollupPeriod
+	if cfg.Rollup != "" {
+		if period, err = ParseRollupPeriod(cfg.Rollup, cfg.RollupEnd, now); err != nil {
+			fatalf("ERROR: %v", err)
+		}
+	} else if cfg.SubscribersFile != "" {
+		if subs, err = LoadSubscribers(cfg.SubscribersFile); err != nil {
+			fatalf("ERROR: %v", err)
+		}
+		if since = SubscribersSince(subs, now, cfg.DaysBack); since.IsZero() {
+			fmt.Fprintf(os.Stderr, "No subscribers are scheduled for %s; nothing to preview\n", now.Format("2006-01-02 Mon"))
+			return
+		}
+	}
+
+	// 記事の取得（ローカルファイルまたはストア）
+	var headlines []NotionHeadline
+	if cfg.PreviewInput != "" {
+		if headlines, err = LoadPreviewHeadlines(cfg.PreviewInput); err != nil {
+			fatalf("ERROR: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Loaded %d headlines from %s\n", len(headlines), cfg.PreviewInput)
+	} else if cfg.Rollup != "" {
+		store := openHeadlineStore(storeCfg)
+		headlines, err = store.QueryHeadlines(context.Background(), period.Query())
+		if err != nil {
+			fatalf("ERROR fetching headlines from %s: %v", store.Name(), err)
+		}
+		fmt.Fprintf(os.Stderr, "Fetched %d headlines from %s (%s)\n", len(headlines), store.Name(), period.Label())
+	} else if !since.IsZero() {
+		store := openHeadlineStore(storeCfg)
+		headlines, err = store.QueryHeadlines(context.Background(), HeadlineQuery{CreatedOnOrAfter: since, Sort: HeadlineSortCreatedDesc})
+		if err != nil {
+			fatalf("ERROR fetching headlines from %s: %v", store.Name(), err)
+		}
+		fmt.Fprintf(os.Stderr, "Fetched %d headlines from %s (since %s)\n", len(headlines), store.Name(), since.Format("2006-01-02"))
+	} else {
+		headlines = fetchStoredHeadlines(openHeadlineStore(storeCfg), cfg.DaysBack)
+	}
+
+	from, to := os.Getenv("EMAIL_FROM"), os.Getenv("EMAIL_TO")
+	if from == "" || (to == "" && len(subs) == 0) {
+		warnf("EMAIL_FROM / EMAIL_TO not set, using placeholder addresses in the preview")
+	}
+	sender, capture := NewPreviewSender(from, to)
+	if err := sender.SetLanguage(cfg.Language); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	if err := sender.SetLayout(cfg.Layout); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+
+	// 送信と同じ処理で描画する（配送先だけが CaptureMailer）
+	ctx := context.Background()
+	switch {
+	case cfg.Rollup != "":
+		err = sender.SendRollup(ctx, headlines, period)
+	case len(subs) > 0:
+		err = sender.SendSubscriberDigests(ctx, headlines, subs, now, cfg.DaysBack).Err()
+	case emailType == EmailTypeFull:
+		err = sender.SendHeadlinesSummary(ctx, headlines)
+	default:
+		err = sender.SendShortHeadlinesDigest(ctx, headlines)
+	}
+	if err != nil {
+		fatalf("ERROR rendering email: %v", err)
+	}
+
+	files, err := WriteEmailPreview(cfg.Preview, capture.Messages())
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	for _, m := range capture.Messages() {
+		fmt.Fprintf(os.Stderr, "  To: %s\n  Subject: %s\n", strings.Join(m.To, ", "), m.Subject)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	for _, f := range files {
+		fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", f)
+	}
+	fmt.Fprintln(os.Stderr, "========================================")
+}
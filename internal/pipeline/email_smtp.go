@@ -0,0 +1,319 @@
+// =============================================================================
+// email_smtp.go - SMTP送信の設定
+// =============================================================================
+//
+// このファイルはメール送信に使うSMTPサーバーの接続・認証設定と、送信処理を提供します。
+// 以前は smtp.gmail.com:587 と PLAIN認証（Gmailアプリパスワード）に固定されていたため、
+// 社内リレーやローカルのテスト用サーバー（MailHog等）を使えなかった。
+//
+// 【環境変数】
+//
+//	SMTP_HOST      - SMTPサーバー（デフォルト: smtp.gmail.com）
+//	SMTP_PORT      - ポート（デフォルト: starttls=587 / tls=465 / none=25）
+//	SMTP_SECURITY  - starttls（デフォルト）/ tls（暗黙のTLS、SMTPS）/ none（平文）
+//	SMTP_AUTH      - plain（デフォルト）/ login / cram-md5 / none
+//	SMTP_USERNAME  - 認証ユーザー名（デフォルト: EMAIL_FROM）
+//	EMAIL_PASSWORD - 認証パスワード（SMTP_AUTH=none の場合は不要）
+//	SMTP_CA_FILE   - 追加で信頼するCA証明書（PEM、社内CA等）
+//	SMTP_HELO      - EHLO/HELOで名乗るホスト名（デフォルト: localhost）
+//
+// 【例】
+//
+//	Gmail:        （設定不要）EMAIL_PASSWORD にアプリパスワード
+//	社内リレー:   SMTP_HOST=relay.example.com SMTP_PORT=25 SMTP_SECURITY=none SMTP_AUTH=none
+//	MailHog:      SMTP_HOST=localhost SMTP_PORT=1025 SMTP_SECURITY=none SMTP_AUTH=none
+//
+// 【注意】
+//   plain / login 認証はパスワードを平文で送るため、SMTP_SECURITY=none の場合は
+//   localhost 以外への接続を拒否する（net/smtp と同じ安全策）。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTP接続の暗号化方式
+const (
+	SMTPSecuritySTARTTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+	SMTPSecurityNone     = "none"
+)
+
+// SMTP認証方式
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+	SMTPAuthNone    = "none"
+)
+
+// DefaultSMTPHost はSMTP_HOSTが未設定の場合のSMTPサーバー
+const DefaultSMTPHost = "smtp.gmail.com"
+
+// smtpTimeout は接続から送信完了までの制限時間（ctx の期限の方が早い場合はそちら、sendSMTP）
+const smtpTimeout = 60 * time.Second
+
+// SMTPConfig はSMTPサーバーへの接続・認証の設定
+type SMTPConfig struct {
+	Host     string // SMTPサーバーホスト
+	Port     string // SMTPポート
+	Security string // starttls / tls / none
+	Auth     string // plain / login / cram-md5 / none
+	Username string // 認証ユーザー名（空の場合は送信元アドレス）
+	CAFile   string // 追加で信頼するCA証明書（PEM）
+	HELO     string // EHLO/HELOのホスト名（空の場合は localhost）
+}
+
+// SMTPConfigFromEnv は環境変数からSMTPの設定を読み込み、デフォルト値を補う
+func SMTPConfigFromEnv() (SMTPConfig, error) {
+	cfg := SMTPConfig{
+		Host:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
+		Security: strings.ToLower(strings.TrimSpace(os.Getenv("SMTP_SECURITY"))),
+		Auth:     strings.ToLower(strings.TrimSpace(os.Getenv("SMTP_AUTH"))),
+		Username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		CAFile:   strings.TrimSpace(os.Getenv("SMTP_CA_FILE")),
+		HELO:     strings.TrimSpace(os.Getenv("SMTP_HELO")),
+	}
+	return cfg, cfg.normalize()
+}
+
+// normalize はデフォルト値を補い、設定を検証する
+func (c *SMTPConfig) normalize() error {
+	if c.Host == "" {
+		c.Host = DefaultSMTPHost
+	}
+	switch c.Security {
+	case "":
+		c.Security = SMTPSecuritySTARTTLS
+	case SMTPSecuritySTARTTLS, SMTPSecurityTLS, SMTPSecurityNone:
+	default:
+		return fmt.Errorf("unsupported SMTP_SECURITY %q (use starttls, tls or none)", c.Security)
+	}
+	switch c.Auth {
+	case "":
+		c.Auth = SMTPAuthPlain
+	case SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5, SMTPAuthNone:
+	default:
+		return fmt.Errorf("unsupported SMTP_AUTH %q (use plain, login, cram-md5 or none)", c.Auth)
+	}
+	if c.Port == "" {
+		switch c.Security {
+		case SMTPSecurityTLS:
+			c.Port = "465"
+		case SMTPSecurityNone:
+			c.Port = "25"
+		default:
+			c.Port = "587"
+		}
+	}
+	return nil
+}
+
+// RequiresPassword は認証にパスワード（EMAIL_PASSWORD）が必要かを返す
+func (c SMTPConfig) RequiresPassword() bool {
+	return c.Auth != SMTPAuthNone
+}
+
+// Addr は "host:port" を返す
+func (c SMTPConfig) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// =============================================================================
+// 送信
+// =============================================================================
+
+// sendSMTP はSMTPサーバーに接続してメッセージを送信する
+//
+// 【手順】 接続（tls は最初からTLS）→ EHLO → STARTTLS（starttls）→ 認証 → MAIL/RCPT/DATA → QUIT
+//
+// 接続から QUIT までを ctx の期限（Lambdaの残り時間等）と smtpTimeout の早い方で打ち切り、
+// ctx がキャンセルされた場合は接続を閉じてその時点で中断する。
+func sendSMTP(ctx context.Context, cfg SMTPConfig, password, from string, to []string, msg []byte) (err error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	var conn net.Conn
+	if cfg.Security == SMTPSecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", cfg.Addr())
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", cfg.Addr())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", cfg.Addr(), err)
+	}
+
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// キャンセル時は接続を閉じて読み書きを中断し、エラーにはキャンセル理由を含める
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("SMTP session with %s aborted: %w (%v)", cfg.Addr(), ctx.Err(), err)
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP handshake with %s failed: %w", cfg.Addr(), err)
+	}
+	defer c.Close()
+
+	if cfg.HELO != "" {
+		if err := c.Hello(cfg.HELO); err != nil {
+			return fmt.Errorf("SMTP HELO failed: %w", err)
+		}
+	}
+
+	if cfg.Security == SMTPSecuritySTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS (set SMTP_SECURITY=tls or none)", cfg.Addr())
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+
+	if auth := cfg.auth(from, password); auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP server %s does not support authentication (set SMTP_AUTH=none)", cfg.Addr())
+		}
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP %s authentication failed: %w%s", strings.ToUpper(cfg.Auth), err, cfg.authHint(err))
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s rejected: %w", addr, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the message: %w", err)
+	}
+	return c.Quit()
+}
+
+// tlsConfig はTLS接続の設定を返す（SMTP_CA_FILE のCAをシステムのCAに追加）
+func (c SMTPConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: c.Host, MinVersion: tls.VersionTLS12}
+	if c.CAFile == "" {
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SMTP_CA_FILE: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("SMTP_CA_FILE %s contains no PEM certificates", c.CAFile)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+// auth は認証方式に応じた smtp.Auth を返す（none の場合はnil）
+func (c SMTPConfig) auth(from, password string) smtp.Auth {
+	username := c.Username
+	if username == "" {
+		username = from
+	}
+	switch c.Auth {
+	case SMTPAuthPlain:
+		return smtp.PlainAuth("", username, password, c.Host)
+	case SMTPAuthLogin:
+		return &loginAuth{username: username, password: password, host: c.Host}
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(username, password)
+	}
+	return nil
+}
+
+// authHint は認証エラーに添える設定のヒントを返す
+func (c SMTPConfig) authHint(err error) string {
+	var tpErr *textproto.Error
+	if !errors.As(err, &tpErr) || tpErr.Code != 535 {
+		return ""
+	}
+	if c.Host == DefaultSMTPHost {
+		return " (Gmail requires an App Password in EMAIL_PASSWORD)"
+	}
+	return " (check SMTP_USERNAME and EMAIL_PASSWORD)"
+}
+
+// =============================================================================
+// LOGIN認証
+// =============================================================================
+
+// loginAuth はLOGIN認証（net/smtp にない、Microsoft 365 や一部のリレーが使用）
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// Start はLOGIN認証を開始する（平文の接続では localhost のみ許可）
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next はサーバーの "Username:" / "Password:" に応答する
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
+
+// isLocalhost はホスト名がローカルホストかを返す
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,335 @@
+// =============================================================================
+// email_template.go - メール本文のテンプレート
+// =============================================================================
+//
+// このファイルはダイジェストメールの本文をテンプレートから生成します。
+// 各メールはHTML（html/template）とプレーンテキスト（text/template）の2つの本文を持ち、
+// multipart/alternative として送信されます（BuildMultipartMessage、email.go）。
+//
+// 【テンプレート】（templates/ に埋め込み）
+//
+//	digest_short.txt.tmpl / digest_short.html.tmpl  炭素関連記事一覧（SendShortHeadlinesDigest）
+//	digest_full.txt.tmpl  / digest_full.html.tmpl   Carbon News Headlines（SendHeadlinesSummary）
+//	rollup.txt.tmpl       / rollup.html.tmpl        週次・月次まとめ（SendRollup、rollup.go）
+//
+// 【差し替え】
+//   EMAIL_TEMPLATE_DIR（-emailTemplates）のディレクトリに同名のファイルを置くと、
+//   埋め込みのテンプレートの代わりに使われる。置いたファイルだけが差し替わる。
+//   テンプレートには DigestView が渡される。
+//
+// 【セクション】
+//   EMAIL_DIGEST_SECTIONS（-emailSections）で記事の区切り方を選ぶ:
+//
+//	language     - 日本語 → English → その他（デフォルト）
+//	source       - ソース別（記事数の多い順）
+//	topic        - トピック別（topics.go の規則の順、トピックなしは最後）
+//	type         - 記事タイプ別（News → Academic）
+//	jurisdiction - 国・地域別（抽出した最初の国、記事数の多い順、国なしは最後）
+//
+//   セクションが1つだけでトップ記事もない場合は見出しを表示しない（ShowSections）。
+//   並び順・件数の上限・トップ記事は digest_layout.go。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var emailTemplateFS embed.FS
+
+// メールテンプレートの種類
+const (
+	DigestTemplateShort = "digest_short"
+	DigestTemplateFull  = "digest_full"
+)
+
+// ダイジェストのセクションの区切り方
+const (
+	DigestSectionsLanguage     = "language"
+	DigestSectionsSource       = "source"
+	DigestSectionsTopic        = "topic"
+	DigestSectionsType         = "type"
+	DigestSectionsJurisdiction = "jurisdiction"
+)
+
+// noTopicLabel はトピック・国に分類されなかった記事のセクション名
+const noTopicLabel = "その他"
+
+// DigestView はメールテンプレートに渡すデータ
+type DigestView struct {
+	Heading      string          // 件名と同じ見出し（例: "炭素関連記事一覧 - 2026-01-06"）
+	Date         string          // 送信日（YYYY-MM-DD）
+	Generated    string          // 生成日時（YYYY-MM-DD HH:MM:SS）
+	Total        int             // 記事数
+	Omitted      int             // セクションの上限を超えて載せなかった記事数
+	Empty        bool            // 記事が0件の場合にtrue
+	ShowSections bool            // セクションが2つ以上、またはトップ記事がある場合にtrue
+	TopStories   []DigestItem    // トップ記事（EMAIL_DIGEST_TOP、なしの場合は空）
+	Sections     []DigestSection // セクション（記事は全体の通し番号付き）
+}
+
+// DigestSection はダイジェストの1セクション
+type DigestSection struct {
+	Label string
+	Count int // 上限で省略した記事も含めた件数
+	More  int // 上限を超えて載せなかった件数
+	Items []DigestItem
+}
+
+// DigestItem はダイジェストの1記事
+type DigestItem struct {
+	Number       int
+	Title        string
+	URL          string
+	Source       string
+	Type         string
+	Language     string
+	Topics       []string
+	Summary      string // Article Summary 300（短いダイジェストでは抽出型要約で代替済み）
+	Extracted    bool   // Summary が抽出型要約の場合にtrue
+	Priority     string
+	HighPriority bool
+	EditorNote   string
+}
+
+// emailTemplateFuncs はテンプレートで使えるラベル（メール上の表記を1か所で管理するため）と書式の関数
+var emailTemplateFuncs = map[string]any{
+	"extractedLabel":  func() string { return ExtractedSummaryLabel },
+	"editorNoteLabel": func() string { return EditorNoteLabel },
+	"inc":             func(i int) int { return i + 1 },
+	"price":           func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) },
+}
+
+// newDigestView はヘッドラインからテンプレート用のデータを作成する
+//
+// layout は検証済み（DigestLayout.normalize）であること。
+func newDigestView(heading string, headlines []NotionHeadline, layout DigestLayout) *DigestView {
+	now := time.Now()
+	view := &DigestView{
+		Heading:   heading,
+		Date:      now.Format("2006-01-02"),
+		Generated: now.Format("2006-01-02 15:04:05"),
+		Total:     len(headlines),
+		Empty:     len(headlines) == 0,
+	}
+
+	top, rest := splitTopStories(headlines, layout.TopStories, now)
+	for i, h := range top {
+		view.TopStories = append(view.TopStories, newDigestItem(i+1, h))
+	}
+	view.Sections = buildDigestSections(orderHeadlines(rest, layout.Order, now), layout.Sections, layout.GroupLimit, len(top))
+	for _, section := range view.Sections {
+		view.Omitted += section.More
+	}
+	view.ShowSections = len(view.Sections) > 1 || len(view.TopStories) > 0
+	return view
+}
+
+// buildDigestSections は記事をセクションに分け、全体の通し番号（offset の次から）を付ける
+//
+// limit が正の場合は各セクションの先頭 limit 件だけを載せる。
+func buildDigestSections(headlines []NotionHeadline, sectionsBy string, limit, offset int) []DigestSection {
+	keyOf := func(h NotionHeadline) string { return h.Language }
+	switch sectionsBy {
+	case DigestSectionsSource:
+		keyOf = func(h NotionHeadline) string { return h.Source }
+	case DigestSectionsTopic:
+		keyOf = func(h NotionHeadline) string {
+			if len(h.Topics) == 0 {
+				return ""
+			}
+			return h.Topics[0]
+		}
+	case DigestSectionsType:
+		keyOf = func(h NotionHeadline) string { return h.Type }
+	case DigestSectionsJurisdiction:
+		keyOf = func(h NotionHeadline) string {
+			if h.Entities == nil || len(h.Entities.Countries) == 0 {
+				return ""
+			}
+			return h.Entities.Countries[0]
+		}
+	}
+
+	groups := map[string][]NotionHeadline{}
+	for _, h := range headlines {
+		key := keyOf(h)
+		groups[key] = append(groups[key], h)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		ri, rj := digestSectionRank(sectionsBy, keys[i], len(groups[keys[i]])), digestSectionRank(sectionsBy, keys[j], len(groups[keys[j]]))
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	sections := make([]DigestSection, 0, len(keys))
+	n := offset
+	for _, key := range keys {
+		items := groups[key]
+		section := DigestSection{Label: digestSectionLabel(sectionsBy, key), Count: len(items)}
+		if limit > 0 && len(items) > limit {
+			section.More = len(items) - limit
+			items = items[:limit]
+		}
+		for _, h := range items {
+			n++
+			section.Items = append(section.Items, newDigestItem(n, h))
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// digestSectionRank はセクションの表示順（小さいほど先）を返す
+func digestSectionRank(sectionsBy, key string, count int) int {
+	switch sectionsBy {
+	case DigestSectionsSource:
+		return -count
+	case DigestSectionsTopic:
+		for i, r := range topicRules {
+			if r.Name == key {
+				return i
+			}
+		}
+		return len(topicRules)
+	case DigestSectionsType:
+		switch key {
+		case "News":
+			return 0
+		case "Academic":
+			return 1
+		}
+		return 2
+	case DigestSectionsJurisdiction:
+		if key == "" {
+			return 1
+		}
+		return -count
+	}
+	switch key {
+	case LangJapanese:
+		return 0
+	case LangEnglish:
+		return 1
+	}
+	return 2
+}
+
+// digestSectionLabel はセクションの見出しを返す
+func digestSectionLabel(sectionsBy, key string) string {
+	switch sectionsBy {
+	case DigestSectionsSource:
+		return key
+	case DigestSectionsTopic, DigestSectionsType, DigestSectionsJurisdiction:
+		if key == "" {
+			return noTopicLabel
+		}
+		return key
+	}
+	return languageLabel(key)
+}
+
+// newDigestItem はヘッドラインをテンプレート用の記事に変換する
+func newDigestItem(n int, h NotionHeadline) DigestItem {
+	return DigestItem{
+		Number:       n,
+		Title:        h.Title,
+		URL:          h.URL,
+		Source:       h.Source,
+		Type:         h.Type,
+		Language:     h.Language,
+		Topics:       h.Topics,
+		Summary:      h.ShortHeadline,
+		Extracted:    h.SummaryExtracted,
+		Priority:     h.Priority,
+		HighPriority: h.Priority == PriorityHigh,
+		EditorNote:   h.EditorNote,
+	}
+}
+
+// =============================================================================
+// テンプレートの読み込みと描画
+// =============================================================================
+
+// parseDigestSections はセクションの区切り方を検証する（空の場合は language）
+func parseDigestSections(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return DigestSectionsLanguage, nil
+	case DigestSectionsLanguage, DigestSectionsSource, DigestSectionsTopic, DigestSectionsType, DigestSectionsJurisdiction:
+		return s, nil
+	}
+	return "", fmt.Errorf("unsupported EMAIL_DIGEST_SECTIONS %q (use language, source, topic, type or jurisdiction)", s)
+}
+
+// readEmailTemplate はテンプレートを読み込む（dir に同名のファイルがあればそちらを使う）
+func readEmailTemplate(dir, file string) (string, error) {
+	if dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err == nil {
+			return string(b), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read email template: %w", err)
+		}
+	}
+	b, err := emailTemplateFS.ReadFile("templates/" + file)
+	if err != nil {
+		return "", fmt.Errorf("email template %s not found", file)
+	}
+	return string(b), nil
+}
+
+// renderDigest はテンプレートからプレーンテキストとHTMLの本文を生成する
+//
+// view はダイジェストでは *DigestView、まとめメールでは *RollupView（rollup.go）。
+func renderDigest(dir, name string, view any) (text, html string, err error) {
+	textSrc, err := readEmailTemplate(dir, name+".txt.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+	textTmpl, err := texttemplate.New(name + ".txt.tmpl").Funcs(emailTemplateFuncs).Parse(textSrc)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid email template: %w", err)
+	}
+	var textBuf bytes.Buffer
+	if err := textTmpl.Execute(&textBuf, view); err != nil {
+		return "", "", fmt.Errorf("failed to render %s.txt.tmpl: %w", name, err)
+	}
+
+	htmlSrc, err := readEmailTemplate(dir, name+".html.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+	htmlTmpl, err := htmltemplate.New(name + ".html.tmpl").Funcs(emailTemplateFuncs).Parse(htmlSrc)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid email template: %w", err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := htmlTmpl.Execute(&htmlBuf, view); err != nil {
+		return "", "", fmt.Errorf("failed to render %s.html.tmpl: %w", name, err)
+	}
+
+	return textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,33 @@
+// =============================================================================
+// enrich.go - 見出しの付加情報抽出パス
+// =============================================================================
+//
+// このファイルは収集した見出しに付加情報（エンティティ等）を付与する処理をまとめます。
+// CollectFromSources の最後、および -headlines でファイルから読み込んだ後に実行されます。
+//
+// 【現在の抽出パス】
+//   - ExtractEntities: レジストリ・プロジェクトID・方法論・国・ETS（entities.go）
+//   - ExtractPrices:   価格・取引量（prices.go）
+//   - DetectLanguage:  記事の言語（language.go）
+//   - SummarizeExtractive: 言語別の文字数以内の抽出型要約（summarize.go）
+//   - ClassifyTopics:  トピック分類（topics.go）
+//
+// 抽出は構造記号（structure.go）を除いたプレーンテキストに対して行う。
+//
+// =============================================================================
+package pipeline
+
+// EnrichHeadlines は各見出しに付加情報を設定する
+//
+// 元のスライスを直接更新する（既に設定済みのフィールドも再計算する）。
+func EnrichHeadlines(headlines []Headline) {
+	for i := range headlines {
+		h := &headlines[i]
+		text := plainExcerpt(h.Excerpt)
+		h.Language = DetectLanguage(h.Title + " " + truncateString(text, 500))
+		h.Entities = ExtractEntities(h.Title, text)
+		h.Prices = ExtractPrices(h.Title, text, h.PublishedAt)
+		h.Summary = SummarizeExtractive(text, SummaryMaxRunesFor(h.Language))
+		h.Topics = ClassifyTopics(h.Title, text)
+	}
+}
@@ -0,0 +1,248 @@
+// =============================================================================
+// entities.go - 構造化エンティティ抽出
+// =============================================================================
+//
+// このファイルは記事のTitle/Excerptからカーボン市場特有のエンティティを抽出します。
+// アナリストが手作業で調べていた情報を構造化フィールドとして保存します。
+//
+// 【抽出対象】
+//   - Registries:    レジストリ・クレジット制度名（Verra, Gold Standard, ACR, CAR, ...）
+//   - ProjectIDs:    プロジェクトID（"VCS 1234", "GS 5678", "ACR 123", "CAR 1234"）
+//   - Methodologies: 方法論コード（VM0042, AMS-III.D, ACM0002, ...）
+//   - Countries:     国名から変換したISO 3166-1 alpha-2コード（"JP", "GH", ...）
+//   - Article6Pairs: パリ協定6条の二国間ペア（"CH-GH" のようにソート済みコードで表記）
+//   - ETS:           排出量取引制度名（EU ETS, UK ETS, RGGI, CCA, NZ ETS, ...）
+//
+// 【出力先】
+//   - Headline.Entities（JSON出力の "entities"）
+//   - Notionのマルチセレクトプロパティ（notion.go の ClipHeadline）
+//
+// =============================================================================
+package pipeline
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Entities は記事から抽出した構造化エンティティを保持する
+type Entities struct {
+	Registries    []string `json:"registries,omitempty"`    // レジストリ名
+	ProjectIDs    []string `json:"projectIds,omitempty"`    // プロジェクトID
+	Methodologies []string `json:"methodologies,omitempty"` // 方法論コード
+	Countries     []string `json:"countries,omitempty"`     // ISO 3166-1 alpha-2
+	Article6Pairs []string `json:"article6Pairs,omitempty"` // 6条ペア（"CH-GH"）
+	ETS           []string `json:"ets,omitempty"`           // 排出量取引制度名
+}
+
+// IsEmpty はエンティティが1つも抽出されていないかを返す
+func (e *Entities) IsEmpty() bool {
+	return e == nil || (len(e.Registries) == 0 && len(e.ProjectIDs) == 0 &&
+		len(e.Methodologies) == 0 && len(e.Countries) == 0 &&
+		len(e.Article6Pairs) == 0 && len(e.ETS) == 0)
+}
+
+// namedPattern は正規名と検出用正規表現の組
+type namedPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// registryPatterns はレジストリ・クレジット制度の検出パターン
+var registryPatterns = []namedPattern{
+	{"Verra", regexp.MustCompile(`(?i)\bverra\b|\bVCS\b|verified carbon standard`)},
+	{"Gold Standard", regexp.MustCompile(`(?i)\bgold standard\b|\bGS4GG\b|\bGS-?ID\b`)},
+	{"ACR", regexp.MustCompile(`\bACR\b|(?i)american carbon registry`)},
+	{"CAR", regexp.MustCompile(`(?i)climate action reserve|\bCAR\s?\d{3,4}\b`)},
+	{"Puro.earth", regexp.MustCompile(`(?i)\bpuro(\.earth)?\b`)},
+	{"Isometric", regexp.MustCompile(`(?i)\bisometric\b`)},
+	{"Plan Vivo", regexp.MustCompile(`(?i)\bplan vivo\b`)},
+	{"Global Carbon Council", regexp.MustCompile(`(?i)global carbon council`)},
+	{"ART TREES", regexp.MustCompile(`\bART[\s-]?TREES\b|(?i)architecture for redd\+ transactions`)},
+	{"CDM", regexp.MustCompile(`\bCDM\b|(?i)clean development mechanism`)},
+	{"J-Credit", regexp.MustCompile(`(?i)j-?credit|J-?クレジット`)},
+	{"JCM", regexp.MustCompile(`\bJCM\b|(?i)joint crediting mechanism|二国間クレジット`)},
+}
+
+// projectIDPatterns はプロジェクトIDの検出パターン（name はIDの接頭辞）
+var projectIDPatterns = []namedPattern{
+	{"VCS", regexp.MustCompile(`(?i)\bVCS\s*(?:ID|project)?\s*(?:no\.?|#)?\s*(\d{2,5})\b`)},
+	{"GS", regexp.MustCompile(`(?i)\bGS\s*-?\s*(?:ID)?\s*#?\s*(\d{3,5})\b`)},
+	{"ACR", regexp.MustCompile(`\bACR\s*-?\s*(\d{2,4})\b`)},
+	{"CAR", regexp.MustCompile(`\bCAR\s*-?\s*(\d{3,4})\b`)},
+	{"CDM", regexp.MustCompile(`(?i)\bCDM\s+project\s*(?:no\.?|number|#)?\s*(\d{2,5})\b`)},
+}
+
+// reMethodology は方法論コードの検出パターン
+//
+//	VM0042, VMR0006, VMD0054 - Verra
+//	AM0001, ACM0002          - CDM大規模方法論
+//	AMS-III.D                - CDM小規模方法論
+var reMethodology = regexp.MustCompile(`\b(?:VM[RD]?\d{4}|A(?:C)?M\d{4}|AMS-[IVX]+\.[A-Z]{1,2})\b`)
+
+// etsPatterns は排出量取引制度の検出パターン
+var etsPatterns = []namedPattern{
+	{"EU ETS", regexp.MustCompile(`(?i)\bEU[\s-]?ETS\b|EU emissions trading|european union emissions trading|欧州排出量取引`)},
+	{"EU ETS2", regexp.MustCompile(`(?i)\bETS[\s-]?2\b`)},
+	{"UK ETS", regexp.MustCompile(`(?i)\bUK[\s-]?ETS\b|UK emissions trading`)},
+	{"RGGI", regexp.MustCompile(`(?i)\bRGGI\b|regional greenhouse gas initiative`)},
+	{"CCA", regexp.MustCompile(`\bCCAs?\b|(?i)california carbon allowance|california cap[\s-]and[\s-]trade`)},
+	{"Washington CCA", regexp.MustCompile(`(?i)washington(?:'s)? (?:climate commitment act|cap[\s-]and[\s-]invest)`)},
+	{"NZ ETS", regexp.MustCompile(`(?i)\bNZ[\s-]?ETS\b|new zealand emissions trading`)},
+	{"K-ETS", regexp.MustCompile(`(?i)\bK-?ETS\b|korea(?:n)? emissions trading`)},
+	{"China ETS", regexp.MustCompile(`(?i)china(?:'s)? (?:national )?(?:ETS|emissions trading|carbon market)|中国の?(?:全国)?排出量取引`)},
+	{"Swiss ETS", regexp.MustCompile(`(?i)\bswiss ETS\b`)},
+	{"GX-ETS", regexp.MustCompile(`(?i)\bGX[\s-]?ETS\b|GXリーグ|GX-ETS`)},
+}
+
+// countryNames は国名（英語・日本語） → ISO 3166-1 alpha-2 の対応表
+//
+// カーボン市場・6条関連で頻出する国に限定している。
+var countryNames = map[string]string{
+	"japan": "JP", "日本": "JP",
+	"china": "CN", "中国": "CN",
+	"south korea": "KR", "korea": "KR", "韓国": "KR",
+	"mongolia": "MN", "モンゴル": "MN",
+	"vietnam": "VN", "viet nam": "VN", "ベトナム": "VN",
+	"thailand": "TH", "タイ王国": "TH",
+	"indonesia": "ID", "インドネシア": "ID",
+	"philippines": "PH", "フィリピン": "PH",
+	"cambodia": "KH", "カンボジア": "KH",
+	"laos": "LA", "ラオス": "LA",
+	"india": "IN", "インド": "IN",
+	"singapore": "SG", "シンガポール": "SG",
+	"malaysia": "MY", "マレーシア": "MY",
+	"australia": "AU", "オーストラリア": "AU",
+	"new zealand": "NZ", "ニュージーランド": "NZ",
+	"switzerland": "CH", "スイス": "CH",
+	"sweden": "SE", "スウェーデン": "SE",
+	"norway": "NO", "ノルウェー": "NO",
+	"germany": "DE", "ドイツ": "DE",
+	"france": "FR", "フランス": "FR",
+	"united kingdom": "GB", "britain": "GB", "英国": "GB",
+	"united states": "US", "米国": "US", "アメリカ": "US",
+	"canada": "CA", "カナダ": "CA",
+	"brazil": "BR", "ブラジル": "BR",
+	"chile": "CL", "チリ": "CL",
+	"peru": "PE", "ペルー": "PE",
+	"colombia": "CO", "コロンビア": "CO",
+	"mexico": "MX", "メキシコ": "MX",
+	"ghana": "GH", "ガーナ": "GH",
+	"kenya": "KE", "ケニア": "KE",
+	"rwanda": "RW", "ルワンダ": "RW",
+	"senegal": "SN", "セネガル": "SN",
+	"morocco": "MA", "モロッコ": "MA",
+	"zambia": "ZM", "ザンビア": "ZM",
+	"malawi": "MW", "マラウイ": "MW",
+	"tanzania": "TZ", "タンザニア": "TZ",
+	"nigeria": "NG", "ナイジェリア": "NG",
+	"ethiopia": "ET", "エチオピア": "ET",
+	"south africa": "ZA", "南アフリカ": "ZA",
+	"egypt": "EG", "エジプト": "EG",
+	"saudi arabia": "SA", "サウジアラビア": "SA",
+	"united arab emirates": "AE", "uae": "AE", "アラブ首長国連邦": "AE",
+	"ukraine": "UA", "ウクライナ": "UA",
+	"vanuatu": "VU", "バヌアツ": "VU",
+	"papua new guinea": "PG", "パプアニューギニア": "PG",
+	"guyana": "GY", "ガイアナ": "GY",
+	"dominican republic": "DO",
+	"uzbekistan":         "UZ", "ウズベキスタン": "UZ",
+	"kyrgyzstan": "KG", "キルギス": "KG",
+	"bangladesh": "BD", "バングラデシュ": "BD",
+	"sri lanka": "LK", "スリランカ": "LK",
+	"maldives": "MV", "モルディブ": "MV",
+	"palau": "PW", "パラオ": "PW",
+}
+
+// reCountry は countryNames のキーから構築した国名検出パターン（長い名前を優先）
+var reCountry = func() *regexp.Regexp {
+	names := make([]string, 0, len(countryNames))
+	for name := range countryNames {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	var ascii, cjk []string
+	for _, n := range names {
+		if containsCJK(n) {
+			cjk = append(cjk, regexp.QuoteMeta(n))
+		} else {
+			ascii = append(ascii, regexp.QuoteMeta(n))
+		}
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(ascii, "|") + `)\b|` + strings.Join(cjk, "|"))
+}()
+
+// reArticle6 はパリ協定6条関連の文脈を検出する
+var reArticle6 = regexp.MustCompile(`(?i)article\s*6(?:\.2|\.4)?|\bITMOs?\b|\bJCM\b|6条|二国間クレジット`)
+
+// reBilateralConnector は2国間をつなぐ接続語（"Japan and Mongolia", "Switzerland-Ghana", "日本・モンゴル"）
+var reBilateralConnector = regexp.MustCompile(`(?i)^\s*(?:and|with|-|–|—|&|/|・|と)\s*$`)
+
+// ExtractEntities はタイトルと本文からエンティティを抽出する
+//
+// 何も抽出できなかった場合は nil を返す（JSON出力で省略される）。
+func ExtractEntities(title, excerpt string) *Entities {
+	text := title + "\n" + excerpt
+	e := &Entities{}
+
+	for _, p := range registryPatterns {
+		if p.re.MatchString(text) {
+			e.Registries = append(e.Registries, p.name)
+		}
+	}
+
+	for _, p := range projectIDPatterns {
+		for _, m := range p.re.FindAllStringSubmatch(text, -1) {
+			e.ProjectIDs = append(e.ProjectIDs, p.name+" "+strings.TrimLeft(m[1], "0"))
+		}
+	}
+
+	for _, m := range reMethodology.FindAllString(text, -1) {
+		e.Methodologies = append(e.Methodologies, strings.ToUpper(m))
+	}
+
+	for _, p := range etsPatterns {
+		if p.re.MatchString(text) {
+			e.ETS = append(e.ETS, p.name)
+		}
+	}
+
+	e.Countries, e.Article6Pairs = extractCountries(text)
+
+	e.ProjectIDs = sortStrings(uniqStrings(e.ProjectIDs))
+	e.Methodologies = sortStrings(uniqStrings(e.Methodologies))
+	if e.IsEmpty() {
+		return nil
+	}
+	return e
+}
+
+// extractCountries は国名をISOコードに変換し、6条文脈では二国間ペアも抽出する
+func extractCountries(text string) (countries, pairs []string) {
+	locs := reCountry.FindAllStringIndex(text, -1)
+	codes := make([]string, len(locs))
+	for i, loc := range locs {
+		codes[i] = countryNames[strings.ToLower(text[loc[0]:loc[1]])]
+		countries = append(countries, codes[i])
+	}
+
+	// 6条関連の記事のみ、隣接する2国（"Japan and Mongolia" 等）をペアとして記録
+	if reArticle6.MatchString(text) {
+		for i := 0; i+1 < len(locs); i++ {
+			between := text[locs[i][1]:locs[i+1][0]]
+			if codes[i] == "" || codes[i+1] == "" || codes[i] == codes[i+1] {
+				continue
+			}
+			if !reBilateralConnector.MatchString(between) {
+				continue
+			}
+			pair := []string{codes[i], codes[i+1]}
+			sort.Strings(pair)
+			pairs = append(pairs, pair[0]+"-"+pair[1])
+		}
+	}
+
+	return sortStrings(uniqStrings(countries)), sortStrings(uniqStrings(pairs))
+}
@@ -0,0 +1,439 @@
+// =============================================================================
+// error_notify.go - 収集・保存のエラー通知（インシデントの重複排除）
+// =============================================================================
+//
+// このファイルはCLI（cmd/pipeline）と収集Lambda（collect / collect-exception）が共通で使う
+// エラー通知を提供します。どの実行元からも同じ形式のメールになります。
+//
+// 【インシデント】
+//   失敗しているソース（SourceResult の Status が error）、0件のソース（Status が empty）、
+//   保存の失敗（NotionClipResult.Failed > 0）をそれぞれ1つのインシデントとして扱い、
+//   状態ファイル（state.go）に記録する。
+//
+//	新規     - 初めて失敗した実行で通知する
+//	継続中   - 同じ失敗が続く間は通知しない。最後の通知から ERROR_REMIND_HOURS（デフォルト: 24）
+//	           経つと、継続中のインシデントをまとめてリマインダーとして通知する
+//	復旧     - そのソースを収集（保存を実行）して失敗しなかった実行で「復旧」として通知し、記録を消す
+//
+//   インシデントは実行元（pipeline / collect / collect-exception）ごとに分けて記録するため、
+//   収集するソースが違う実行元どうしで「復旧」を誤判定しない。
+//   0件のソースはエラーにならないまま壊れている（サイトの構造変更やフィルタの誤り）ことが多いため、
+//   エラーとは別の種類のインシデントとして同じように新規・リマインダー・復旧を通知する。
+//
+// 【件名】
+//
+//	[Carbon Relay] collect: 2 new, 1 ongoing, 1 recovered - 2026-10-18 09:00
+//
+// 【設定】
+//
+//	EMAIL_FROM / EMAIL_PASSWORD   送信元（mailer.go のとおり、SMTP以外ではパスワード不要）
+//	ERROR_EMAIL_TO                送信先（未設定なら EMAIL_TO）
+//	ERROR_REMIND_HOURS            継続中のインシデントを再通知する間隔（デフォルト: 24）
+//	ERROR_STATE_FILE              状態ファイル（未設定なら STATE_DIR/incidents_state.json）
+//
+//   Lambdaでは状態ファイルのデフォルトがないため、通知を設定した場合は ERROR_STATE_FILE か
+//   STATE_DIR が必須（state.go）。送信元・送信先がなく通知しない場合は状態ファイルも使わない。
+//
+//   メールを送れなかった場合は状態を更新しないため、次回の実行で同じ内容を送り直す。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// エラー通知の実行元
+const (
+	ErrorJobPipeline         = "pipeline"
+	ErrorJobCollect          = "collect"
+	ErrorJobCollectException = "collect-exception"
+)
+
+// インシデントの種類
+const (
+	incidentSource = "source"
+	incidentEmpty  = "empty"
+	incidentStore  = "store"
+)
+
+// DefaultErrorRemindHours は継続中のインシデントを再通知する間隔のデフォルト
+const DefaultErrorRemindHours = 24
+
+// incidentStateName は状態ファイルのデフォルトのファイル名
+const incidentStateName = "incidents_state.json"
+
+// incidentStateVersion は状態ファイルの保存形式のバージョン
+const incidentStateVersion = 1
+
+// RunReport は1回の実行の収集・保存の結果
+type RunReport struct {
+	Job     string            // 実行元（ErrorJobPipeline 等）
+	Collect *CollectResult    // 収集結果（ファイルから読み込んだ場合は nil）
+	Store   *NotionClipResult // 保存結果（保存しなかった場合は nil）
+}
+
+// ErrorNotifierConfig はエラー通知の設定
+type ErrorNotifierConfig struct {
+	From      string
+	Password  string
+	To        string
+	StateFile string
+	Remind    time.Duration
+}
+
+// ErrorNotifierConfigFromEnv は環境変数からエラー通知の設定を読み込む
+//
+// 状態ファイルは ERROR_STATE_FILE、未設定なら STATE_DIR（未設定なら stateDir）の下に置く。
+// stateDir が空（Lambda）の場合、通知を設定していなければ StateFile は空のまま、
+// 設定していて保存先がなければエラーを返す（/tmp では通知済みの記録が残らないため）。
+func ErrorNotifierConfigFromEnv(stateDir string) (ErrorNotifierConfig, error) {
+	cfg := ErrorNotifierConfig{
+		From:     os.Getenv("EMAIL_FROM"),
+		Password: os.Getenv("EMAIL_PASSWORD"),
+		To:       strings.TrimSpace(os.Getenv("ERROR_EMAIL_TO")),
+		Remind:   DefaultErrorRemindHours * time.Hour,
+	}
+	if cfg.To == "" {
+		cfg.To = os.Getenv("EMAIL_TO")
+	}
+	if stateDir != "" || EmailCredentialsSet(cfg.From, cfg.Password, cfg.To) {
+		path, err := StatePath("ERROR_STATE_FILE", stateDir, incidentStateName)
+		if err != nil {
+			return cfg, fmt.Errorf("error notification needs a persistent state file: %w", err)
+		}
+		cfg.StateFile = path
+	}
+	if s := strings.TrimSpace(os.Getenv("ERROR_REMIND_HOURS")); s != "" {
+		hours, err := strconv.Atoi(s)
+		if err != nil || hours <= 0 {
+			return cfg, fmt.Errorf("ERROR_REMIND_HOURS must be a positive integer, got %q", s)
+		}
+		cfg.Remind = time.Duration(hours) * time.Hour
+	}
+	return cfg, nil
+}
+
+// =============================================================================
+// インシデントの状態
+// =============================================================================
+
+// incidentState は記録中のインシデント（状態ファイルの内容）
+type incidentState struct {
+	Version   int                       `json:"version"`
+	Incidents map[string]incidentRecord `json:"incidents"`
+}
+
+// incidentRecord はインシデント1件の記録
+type incidentRecord struct {
+	Job          string    `json:"job"`
+	Kind         string    `json:"kind"` // source / empty / store
+	Name         string    `json:"name"` // ソース識別子（store の場合は空）
+	Detail       string    `json:"detail"`
+	FirstSeen    time.Time `json:"firstSeen"`
+	LastSeen     time.Time `json:"lastSeen"`
+	LastNotified time.Time `json:"lastNotified"`
+	Runs         int       `json:"runs"` // 連続して失敗した実行の回数
+}
+
+// incidentKey は状態ファイルのキーを返す（例: "collect/source/arxiv"、"collect/empty/jri"、"collect/store"）
+func incidentKey(job, kind, name string) string {
+	if name == "" {
+		return job + "/" + kind
+	}
+	return job + "/" + kind + "/" + strings.ToLower(name)
+}
+
+// label は通知に表示するインシデントの名前を返す
+func (r incidentRecord) label() string {
+	switch r.Kind {
+	case incidentStore:
+		return "[STORE] 保存"
+	case incidentEmpty:
+		return "[EMPTY] " + r.Name
+	}
+	return "[ERROR] " + r.Name
+}
+
+// recoveredLabel は復旧の通知に表示するインシデントの名前を返す
+func (r incidentRecord) recoveredLabel() string {
+	switch r.Kind {
+	case incidentStore:
+		return "保存"
+	case incidentEmpty:
+		return r.Name + " の0件"
+	}
+	return r.Name
+}
+
+// runIncidents は実行結果から失敗中のインシデントと、今回判定できたキーを返す
+//
+// 今回収集しなかったソースや、保存しなかった場合の保存はキーに含めない（復旧と判定しない）。
+func runIncidents(report RunReport) (failing map[string]incidentRecord, checked map[string]bool) {
+	failing = map[string]incidentRecord{}
+	checked = map[string]bool{}
+	if c := report.Collect; c != nil {
+		for _, sr := range c.SourceResults {
+			errKey := incidentKey(report.Job, incidentSource, sr.Name)
+			emptyKey := incidentKey(report.Job, incidentEmpty, sr.Name)
+			checked[errKey] = true
+			checked[emptyKey] = true
+			switch sr.Status {
+			case "error":
+				failing[errKey] = incidentRecord{Job: report.Job, Kind: incidentSource, Name: sr.Name, Detail: sr.ErrorMsg}
+			case "empty":
+				failing[emptyKey] = incidentRecord{Job: report.Job, Kind: incidentEmpty, Name: sr.Name, Detail: "0 headlines"}
+			}
+		}
+	}
+	if s := report.Store; s != nil {
+		key := incidentKey(report.Job, incidentStore, "")
+		checked[key] = true
+		if s.Failed > 0 {
+			detail := fmt.Sprintf("%d/%d件の保存に失敗", s.Failed, s.Clipped+s.Failed)
+			if lines := s.ErrorReport(); len(lines) > 0 {
+				detail += " - " + lines[0]
+			}
+			failing[key] = incidentRecord{Job: report.Job, Kind: incidentStore, Detail: detail}
+		}
+	}
+	return failing, checked
+}
+
+// incidentUpdate は今回の実行で通知する内容
+type incidentUpdate struct {
+	New       []incidentRecord
+	Ongoing   []incidentRecord
+	Recovered []incidentRecord
+	Remind    bool // 継続中のインシデントの再通知の時期になった
+}
+
+// notify は通知すべき内容があるかを返す
+func (u incidentUpdate) notify() bool {
+	return len(u.New) > 0 || len(u.Recovered) > 0 || u.Remind
+}
+
+// apply は実行結果を状態に反映し、通知する内容を返す
+func (s *incidentState) apply(report RunReport, now time.Time, remind time.Duration) incidentUpdate {
+	failing, checked := runIncidents(report)
+	var u incidentUpdate
+
+	for _, key := range sortedKeys(failing) {
+		cur := failing[key]
+		rec, ok := s.Incidents[key]
+		if !ok {
+			rec = cur
+			rec.FirstSeen = now
+		}
+		rec.Detail = cur.Detail
+		rec.LastSeen = now
+		rec.Runs++
+		s.Incidents[key] = rec
+		if !ok {
+			u.New = append(u.New, rec)
+			continue
+		}
+		u.Ongoing = append(u.Ongoing, rec)
+		if now.Sub(rec.LastNotified) >= remind {
+			u.Remind = true
+		}
+	}
+
+	for _, key := range sortedKeys(s.Incidents) {
+		if _, ok := failing[key]; ok || !checked[key] {
+			continue
+		}
+		u.Recovered = append(u.Recovered, s.Incidents[key])
+		delete(s.Incidents, key)
+	}
+	return u
+}
+
+// markNotified は通知したインシデントの最終通知日時を更新する
+func (s *incidentState) markNotified(u incidentUpdate, now time.Time) {
+	for _, list := range [][]incidentRecord{u.New, u.Ongoing} {
+		for _, rec := range list {
+			key := incidentKey(rec.Job, rec.Kind, rec.Name)
+			r := s.Incidents[key]
+			r.LastNotified = now
+			s.Incidents[key] = r
+		}
+	}
+}
+
+// sortedKeys はマップのキーを昇順で返す
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// =============================================================================
+// 通知
+// =============================================================================
+
+// NotifyErrors は実行結果をインシデントの状態に反映し、必要な場合だけエラー通知メールを送る
+//
+// 送信元・送信先がない場合は通知せず、状態も更新しない。
+func NotifyErrors(ctx context.Context, report RunReport, cfg ErrorNotifierConfig, now time.Time) error {
+	if cfg.StateFile == "" {
+		// Lambdaで通知を設定していない場合（ErrorNotifierConfigFromEnv）
+		warnf("Email env vars not set, skipping error notification email")
+		return nil
+	}
+	state := &incidentState{Version: incidentStateVersion, Incidents: map[string]incidentRecord{}}
+	if err := loadState(cfg.StateFile, state); err != nil {
+		return err
+	}
+	if state.Incidents == nil {
+		state.Incidents = map[string]incidentRecord{}
+	}
+
+	recorded := len(state.Incidents)
+	u := state.apply(report, now, cfg.Remind)
+	if !u.notify() {
+		// 問題がなく記録もない場合は状態ファイルを作らない
+		if recorded == 0 && len(state.Incidents) == 0 {
+			return nil
+		}
+		if len(u.Ongoing) > 0 {
+			infof("%d ongoing incident(s) already notified (reminder every %s)", len(u.Ongoing), cfg.Remind)
+		}
+		return saveState(cfg.StateFile, state)
+	}
+
+	if !EmailCredentialsSet(cfg.From, cfg.Password, cfg.To) {
+		warnf("Email env vars not set, skipping error notification email")
+		return nil
+	}
+	sender, err := NewEmailSender(cfg.From, cfg.Password, cfg.To)
+	if err != nil {
+		return fmt.Errorf("failed to create email sender: %w", err)
+	}
+	msg := sender.BuildEmailMessage(errorSubject(report.Job, u, now), errorBody(report, u, now))
+	if err := sender.SendWithRetry(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send error notification email: %w", err)
+	}
+	infof("Error notification email sent (%d new, %d ongoing, %d recovered)", len(u.New), len(u.Ongoing), len(u.Recovered))
+
+	state.markNotified(u, now)
+	return saveState(cfg.StateFile, state)
+}
+
+// NotifyRunErrors は環境変数の設定でエラー通知を行う（失敗しても処理は止めず、警告を表示する）
+//
+// stateDir は STATE_DIR が未設定の場合の状態ファイルの保存先（CLIは "."）。
+// Lambdaは "" を渡すか、ErrorNotifierConfigFromEnv で先に設定を検証して NotifyErrors を使う。
+func NotifyRunErrors(ctx context.Context, report RunReport, stateDir string) {
+	cfg, err := ErrorNotifierConfigFromEnv(stateDir)
+	if err != nil {
+		warnf("Error notification: %v", err)
+		return
+	}
+	if err := NotifyErrors(ctx, report, cfg, time.Now()); err != nil {
+		warnf("Error notification: %v", err)
+	}
+}
+
+// errorSubject はエラー通知メールの件名を返す
+func errorSubject(job string, u incidentUpdate, now time.Time) string {
+	var parts []string
+	if n := len(u.New); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", n))
+	}
+	if n := len(u.Ongoing); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d ongoing", n))
+	}
+	if n := len(u.Recovered); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d recovered", n))
+	}
+	return fmt.Sprintf("[Carbon Relay] %s: %s - %s", job, strings.Join(parts, ", "), now.Format("2006-01-02 15:04"))
+}
+
+// errorBody はエラー通知メールの本文を返す
+//
+// 【形式】
+//
+//	=== 新しい問題 ===
+//	  [ERROR] arxiv: 429 Too Many Requests
+//	  [EMPTY] jri: 0 headlines
+//
+//	=== 継続中の問題 ===
+//	  [ERROR] iisd: 403 Forbidden（2026-10-15 09:00 から、12回連続）
+//
+//	=== 復旧 ===
+//	  [OK] jri（2026-10-16 09:00 から 2026-10-18 09:00 まで、4回失敗）
+//
+//	=== 収集結果 ===
+//	=== 保存結果 ===
+func errorBody(report RunReport, u incidentUpdate, now time.Time) string {
+	const tf = "2006-01-02 15:04"
+	var body strings.Builder
+	fmt.Fprintf(&body, "Carbon Relay エラー通知（%s）\n", report.Job)
+
+	if len(u.New) > 0 {
+		body.WriteString("\n=== 新しい問題 ===\n")
+		for _, r := range u.New {
+			fmt.Fprintf(&body, "  %s: %s\n", r.label(), r.Detail)
+		}
+	}
+	if len(u.Ongoing) > 0 {
+		body.WriteString("\n=== 継続中の問題 ===\n")
+		for _, r := range u.Ongoing {
+			fmt.Fprintf(&body, "  %s: %s（%s から、%d回連続）\n", r.label(), r.Detail, r.FirstSeen.Format(tf), r.Runs)
+		}
+	}
+	if len(u.Recovered) > 0 {
+		body.WriteString("\n=== 復旧 ===\n")
+		for _, r := range u.Recovered {
+			fmt.Fprintf(&body, "  [OK] %s（%s から %s まで、%d回失敗）\n", r.recoveredLabel(), r.FirstSeen.Format(tf), r.LastSeen.Format(tf), r.Runs)
+		}
+	}
+
+	// === 収集結果 ===
+	if c := report.Collect; c != nil {
+		body.WriteString("\n=== 収集結果 ===\n")
+		successCount, successArticles, emptyCount, errorCount := 0, 0, 0, 0
+		var emptySources []string
+		for _, sr := range c.SourceResults {
+			switch sr.Status {
+			case "success":
+				successCount++
+				successArticles += sr.Count
+			case "empty":
+				emptyCount++
+				emptySources = append(emptySources, sr.Name)
+			case "error":
+				errorCount++
+			}
+		}
+		fmt.Fprintf(&body, "総ソース数: %d\n", len(c.SourceResults))
+		fmt.Fprintf(&body, "成功: %d (計 %d 記事) / 0件: %d / エラー: %d\n",
+			successCount, successArticles, emptyCount, errorCount)
+		for _, name := range emptySources {
+			fmt.Fprintf(&body, "  [WARN]  %s: 0 headlines\n", name)
+		}
+	}
+
+	// === 保存結果 ===
+	if s := report.Store; s != nil && s.Failed > 0 {
+		body.WriteString("\n=== 保存結果 ===\n")
+		fmt.Fprintf(&body, "成功: %d / 失敗: %d\n", s.Clipped, s.Failed)
+		// 種類別（認証・入力値・レート制限・一時障害）に対処方法付きで表示
+		for _, line := range s.ErrorReport() {
+			body.WriteString("  " + line + "\n")
+		}
+	}
+
+	fmt.Fprintf(&body, "\nTimestamp: %s\n", now.Format(time.RFC3339))
+	return body.String()
+}
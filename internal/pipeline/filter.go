@@ -0,0 +1,635 @@
+// =============================================================================
+// filter.go - キーワードフィルタエンジン
+// =============================================================================
+//
+// このファイルは記事のキーワードフィルタリングを一元管理するエンジンを提供します。
+// 従来の matchesKeywords（単純な部分文字列一致）を置き換え、以下をサポートします。
+//
+// 【フィルタ式の文法】
+//
+//	carbon AND (market OR price)        論理積・論理和・括弧
+//	"carbon credit"                     フレーズ（語順どおりに一致）
+//	NOT "carbon fibre"                  否定（"-" でも可: -"carbon fibre"）
+//	carbon market                       演算子を省略した場合は AND
+//	decarboni*                          末尾 "*" で前方一致
+//	排出量取引                          日本語（CJK）は部分一致
+//
+// 【一致ルール】
+//   - 大文字小文字を区別しない（全角英数字は半角に正規化）
+//   - 英数字の語は単語境界で判定する（"carbon" は "carbonated" に一致しない）
+//   - 末尾の英語複数形 "s" / "es" は許容する（"carbon credit" は "carbon credits" に一致）
+//   - CJK文字を含む語は単語境界を持たないため部分一致で判定する
+//
+// 【ソース別設定】
+//
+//	SOURCE_FILTERS_FILE（または -filters フラグ）でJSONファイルを指定すると、
+//	ソースごとにフィルタ式を適用できる。キーはソース識別子またはソース名（識別子を優先）、
+//	"*" は個別設定のない全ソースに適用される。
+//
+//	{
+//	  "carbonherald": "NOT (\"carbon fibre\" OR \"carbon steel\")",
+//	  "euractiv":     "carbon OR climate OR \"emissions trading\"",
+//	  "*":            "NOT carbonated"
+//	}
+//
+// =============================================================================
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+// 式の構造
+// =============================================================================
+
+// FilterExpr はパース済みのフィルタ式を表す
+type FilterExpr struct {
+	Raw  string     // 元の式
+	root filterNode // 構文木のルート
+}
+
+// FilterMatch はフィルタ評価の詳細（filter explain 用）
+type FilterMatch struct {
+	Matched  bool        // 式全体が真かどうか
+	Terms    []TermMatch // 一致した肯定語
+	Excluded []TermMatch // NOT で除外された語（一致したため否定が偽になったもの）
+}
+
+// TermMatch は一致した語とフィールドを表す
+type TermMatch struct {
+	Term  string // 式に書かれた語
+	Field string // "title" または "excerpt"
+}
+
+// filterNode は構文木のノード
+type filterNode interface {
+	eval(doc *filterDoc, m *FilterMatch) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+type filterOr struct{ left, right filterNode }
+type filterNot struct{ child filterNode }
+
+// filterTerm は単語・フレーズ・前方一致語を表す
+type filterTerm struct {
+	raw    string // 表示用（式に書かれたまま）
+	text   string // 正規化済みの検索文字列
+	prefix bool   // 末尾 "*" による前方一致
+	cjk    bool   // CJK文字を含む（部分一致で判定）
+}
+
+// filterDoc は評価対象の正規化済みテキスト
+type filterDoc struct {
+	title   string
+	excerpt string
+}
+
+func (n filterAnd) eval(doc *filterDoc, m *FilterMatch) bool {
+	// 両辺の一致語を explain に残すため短絡評価しない
+	l := n.left.eval(doc, m)
+	r := n.right.eval(doc, m)
+	return l && r
+}
+
+func (n filterOr) eval(doc *filterDoc, m *FilterMatch) bool {
+	l := n.left.eval(doc, m)
+	r := n.right.eval(doc, m)
+	return l || r
+}
+
+func (n filterNot) eval(doc *filterDoc, m *FilterMatch) bool {
+	// 否定の内側で一致した語は「除外理由」として記録する
+	inner := &FilterMatch{}
+	if n.child.eval(doc, inner) {
+		m.Excluded = append(m.Excluded, inner.Terms...)
+		return false
+	}
+	return true
+}
+
+func (t filterTerm) eval(doc *filterDoc, m *FilterMatch) bool {
+	switch {
+	case t.matchText(doc.title):
+		m.Terms = append(m.Terms, TermMatch{Term: t.raw, Field: "title"})
+		return true
+	case t.matchText(doc.excerpt):
+		m.Terms = append(m.Terms, TermMatch{Term: t.raw, Field: "excerpt"})
+		return true
+	}
+	return false
+}
+
+// matchText は正規化済みテキスト内に語が境界付きで出現するか判定する
+func (t filterTerm) matchText(text string) bool {
+	if t.text == "" || text == "" {
+		return false
+	}
+	if t.cjk {
+		return strings.Contains(text, t.text)
+	}
+
+	for start := 0; start < len(text); {
+		idx := strings.Index(text[start:], t.text)
+		if idx < 0 {
+			return false
+		}
+		pos := start + idx
+		end := pos + len(t.text)
+		if isFilterBoundaryBefore(text, pos) && (t.prefix || isFilterBoundaryAfter(text, end)) {
+			return true
+		}
+		start = pos + 1
+	}
+	return false
+}
+
+// =============================================================================
+// 公開API
+// =============================================================================
+
+// ParseFilter はフィルタ式をパースする
+//
+// 使用例:
+//
+//	f, err := ParseFilter(`carbon AND NOT ("carbon fibre" OR "carbon steel")`)
+//	if f.Match(h.Title, h.Excerpt) { ... }
+func ParseFilter(expr string) (*FilterExpr, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.pos+1)
+	}
+	return &FilterExpr{Raw: expr, root: root}, nil
+}
+
+// NewKeywordFilter はキーワードリストのいずれかに一致するフィルタ（OR結合）を作成する
+//
+// 既存のキーワードリスト（carbonKeywordsJapan 等）をエンジンで評価するために使用する。
+// 各キーワードはフレーズとして扱われる。リストごとに一度だけ作成し、記事ごとに作り直さないこと。
+func NewKeywordFilter(keywords []string) *FilterExpr {
+	var root filterNode
+	for _, kw := range keywords {
+		term := newFilterTerm(kw)
+		if term.text == "" {
+			continue
+		}
+		if root == nil {
+			root = term
+		} else {
+			root = filterOr{left: root, right: term}
+		}
+	}
+	return &FilterExpr{Raw: strings.Join(keywords, " OR "), root: root}
+}
+
+// Match は title または excerpt が式を満たすか判定する
+func (f *FilterExpr) Match(title, excerpt string) bool {
+	return f.Explain(title, excerpt).Matched
+}
+
+// Explain は式を評価し、どの語がどのフィールドで一致したかを返す
+func (f *FilterExpr) Explain(title, excerpt string) FilterMatch {
+	m := FilterMatch{}
+	if f == nil || f.root == nil {
+		return m
+	}
+	doc := &filterDoc{
+		title:   normalizeFilterText(title),
+		excerpt: normalizeFilterText(excerpt),
+	}
+	m.Matched = f.root.eval(doc, &m)
+	return m
+}
+
+// FilterHeadlines は式に一致する見出しのみを返す
+func (f *FilterExpr) FilterHeadlines(headlines []Headline) []Headline {
+	if f == nil {
+		return headlines
+	}
+	out := make([]Headline, 0, len(headlines))
+	for _, h := range headlines {
+		if f.Match(h.Title, h.Excerpt) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// String はマッチ結果を "term@field" 形式で返す（ログ・explain表示用）
+func (m FilterMatch) String() string {
+	var parts []string
+	for _, t := range m.Terms {
+		parts = append(parts, fmt.Sprintf("%s@%s", t.Term, t.Field))
+	}
+	for _, t := range m.Excluded {
+		parts = append(parts, fmt.Sprintf("NOT %s@%s", t.Term, t.Field))
+	}
+	if len(parts) == 0 {
+		return "(no terms matched)"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// =============================================================================
+// ソース別フィルタ設定
+// =============================================================================
+
+// SourceFilters はソースごとのフィルタ式を保持する
+//
+// キーはソース識別子（"carbonherald"）またはソース名（"Carbon Herald"）の小文字。
+// 収集（FilterHeadlines）と -filterExplain は同じ For で引くため、両者で異なるフィルタにはならない。
+// "lang:ja" / "lang:en" は個別設定のないソースの記事に言語別に適用される（language.go）。
+// "*" は個別設定のないソースに適用されるデフォルト。
+type SourceFilters map[string]*FilterExpr
+
+// LoadSourceFilters はJSONファイルからソース別フィルタを読み込む
+//
+// pathが空の場合は nil（フィルタなし）を返す。
+func LoadSourceFilters(path string) (SourceFilters, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw := map[string]string{}
+	if err := readJSONFile(path, &raw); err != nil {
+		return nil, fmt.Errorf("reading source filters: %w", err)
+	}
+
+	filters := SourceFilters{}
+	for key, expr := range raw {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			return nil, fmt.Errorf("source filter %q: %w", key, err)
+		}
+		filters[strings.ToLower(strings.TrimSpace(key))] = f
+	}
+	return filters, nil
+}
+
+// For はソース（識別子・名前）と記事の言語に対応するフィルタを返す
+//
+// 優先順位: ソース識別子 → ソース名 → "lang:<言語>" → "*"
+// 識別子が分からない場合（ファイルから読み込んだ古い見出し等）は空文字を渡す。
+func (sf SourceFilters) For(id, name, lang string) *FilterExpr {
+	if sf == nil {
+		return nil
+	}
+	for _, key := range []string{id, name} {
+		if key == "" {
+			continue
+		}
+		if f, ok := sf[strings.ToLower(strings.TrimSpace(key))]; ok {
+			return f
+		}
+	}
+	if lang != "" {
+		if f, ok := sf["lang:"+lang]; ok {
+			return f
+		}
+	}
+	return sf["*"]
+}
+
+// FilterHeadlines はソース・言語別のフィルタ式を各見出しに適用する（source はソース識別子）
+func (sf SourceFilters) FilterHeadlines(source string, headlines []Headline) []Headline {
+	if sf == nil {
+		return headlines
+	}
+	out := make([]Headline, 0, len(headlines))
+	for _, h := range headlines {
+		f := sf.For(source, h.Source, headlineLanguage(h))
+		if f == nil || f.Match(h.Title, h.Excerpt) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// filter explain ハンドラ
+// =============================================================================
+
+// HandleFilterExplain は各見出しに対するフィルタの評価結果を表示する
+//
+// expr が指定された場合は全見出しにその式を適用し、
+// 指定されていない場合はソース別フィルタ（filters）を適用する。
+func HandleFilterExplain(headlines []Headline, expr string, filters SourceFilters) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "🔍 Filter Explain")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	var override *FilterExpr
+	if expr != "" {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			fatalf("ERROR parsing filter: %v", err)
+		}
+		override = f
+		fmt.Fprintf(os.Stderr, "Expression: %s\n\n", expr)
+	} else if len(filters) == 0 {
+		fatalf("ERROR: -filter or -filters (SOURCE_FILTERS_FILE) is required for -filterExplain")
+	}
+
+	kept := 0
+	for i, h := range headlines {
+		f := override
+		if f == nil {
+			f = filters.For(h.SourceID, h.Source, headlineLanguage(h))
+		}
+		if f == nil {
+			fmt.Fprintf(os.Stderr, "[%d] ⚪ %s (no filter for source)\n", i+1, truncateString(h.Title, 60))
+			fmt.Fprintf(os.Stderr, "    Source: %s\n\n", h.Source)
+			kept++
+			continue
+		}
+
+		m := f.Explain(h.Title, h.Excerpt)
+		mark := "❌"
+		if m.Matched {
+			mark = "✅"
+			kept++
+		}
+		fmt.Fprintf(os.Stderr, "[%d] %s %s\n", i+1, mark, truncateString(h.Title, 60))
+		fmt.Fprintf(os.Stderr, "    Source: %s\n", h.Source)
+		if override == nil {
+			fmt.Fprintf(os.Stderr, "    Filter: %s\n", f.Raw)
+		}
+		fmt.Fprintf(os.Stderr, "    Terms:  %s\n\n", m.String())
+	}
+
+	fmt.Fprintf(os.Stderr, "📊 Kept %d / %d headlines\n", kept, len(headlines))
+	fmt.Fprintln(os.Stderr, "========================================")
+}
+
+// =============================================================================
+// 字句解析・構文解析
+// =============================================================================
+
+type filterTokenKind int
+
+const (
+	tokTerm filterTokenKind = iota
+	tokPhrase
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+// tokenizeFilter は式をトークン列に分解する
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokRParen, text: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			// 語頭の "-" は NOT の省略形
+			tokens = append(tokens, filterToken{kind: tokNot, text: "-"})
+			i++
+		case r == '"' || r == '“' || r == '”':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' && runes[j] != '”' && runes[j] != '“' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated phrase starting at %q", string(runes[i:]))
+			}
+			tokens = append(tokens, filterToken{kind: tokPhrase, text: string(runes[i+1 : j])})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '(' && runes[j] != ')' && runes[j] != '"' {
+				j++
+			}
+			word := string(runes[i:j])
+			switch word {
+			case "AND", "&&":
+				tokens = append(tokens, filterToken{kind: tokAnd, text: word})
+			case "OR", "||":
+				tokens = append(tokens, filterToken{kind: tokOr, text: word})
+			case "NOT":
+				tokens = append(tokens, filterToken{kind: tokNot, text: word})
+			default:
+				tokens = append(tokens, filterToken{kind: tokTerm, text: word})
+			}
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser は再帰下降パーサ
+//
+//	or    := and ("OR" and)*
+//	and   := unary (["AND"] unary)*
+//	unary := ("NOT" | "-") unary | primary
+//	primary := "(" or ")" | TERM | PHRASE
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() *filterToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t == nil || t.kind != tokOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokOr || t.kind == tokRParen {
+			return left, nil
+		}
+		if t.kind == tokAnd {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	t := p.peek()
+	if t != nil && t.kind == tokNot {
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch t.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case tokTerm, tokPhrase:
+		term := newFilterTerm(t.text)
+		if term.text == "" {
+			return nil, fmt.Errorf("empty term")
+		}
+		return term, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+}
+
+// newFilterTerm は語を正規化して filterTerm を作成する
+func newFilterTerm(raw string) filterTerm {
+	text := strings.TrimSpace(raw)
+	prefix := strings.HasSuffix(text, "*")
+	text = strings.TrimSuffix(text, "*")
+	text = normalizeFilterText(text)
+	return filterTerm{
+		raw:    strings.TrimSpace(raw),
+		text:   text,
+		prefix: prefix,
+		cjk:    containsCJK(text),
+	}
+}
+
+// =============================================================================
+// テキスト正規化・境界判定
+// =============================================================================
+
+// normalizeFilterText は照合用にテキストを正規化する
+//
+//  1. 全角英数字・記号（U+FF01〜U+FF5E）を半角に変換（"ＣＯ２" → "CO2"）
+//  2. 小文字化
+//  3. 連続する空白を単一スペースに
+func normalizeFilterText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			return r - 0xFEE0
+		}
+		if r == 0x3000 { // 全角スペース
+			return ' '
+		}
+		return r
+	}, s)
+	return normalizeWhitespace(strings.ToLower(s))
+}
+
+// containsCJK はテキストに漢字・ひらがな・カタカナが含まれるか判定する
+func containsCJK(s string) bool {
+	for _, r := range s {
+		if isCJKRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCJKRune は漢字・ひらがな・カタカナ（長音記号を含む）かどうかを返す
+func isCJKRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || r == 'ー'
+}
+
+// isFilterWordRune は単語を構成する文字かどうかを返す
+// CJK文字は単語境界として扱う（"CO2削減" の "CO2" は一致する）
+func isFilterWordRune(r rune) bool {
+	if isCJKRune(r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isFilterBoundaryBefore は pos の直前が単語境界かどうかを返す
+func isFilterBoundaryBefore(text string, pos int) bool {
+	if pos == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:pos])
+	return !isFilterWordRune(r)
+}
+
+// isFilterBoundaryAfter は end の直後が単語境界かどうかを返す
+// 英語の複数形（"s" / "es"）の後の境界も許容する
+func isFilterBoundaryAfter(text string, end int) bool {
+	rest := text[end:]
+	for _, suffix := range []string{"", "s", "es"} {
+		if !strings.HasPrefix(rest, suffix) {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(rest[len(suffix):])
+		if size == 0 || !isFilterWordRune(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import "testing"
+
+func TestCarbonKeywordFiltersRejectCarbonated(t *testing.T) {
+	filters := map[string]*FilterExpr{
+		"carbonFilterArXiv":    carbonFilterArXiv,
+		"carbonFilterAcademic": carbonFilterAcademic,
+		"carbonFilterEuractiv": carbonFilterEuractiv,
+		"carbonFilterJapan":    carbonFilterJapan,
+		"carbonKeywordsNature": NewKeywordFilter(carbonKeywordsNature),
+	}
+	for name, f := range filters {
+		if f.Match("Sales of carbonated drinks rise", "") {
+			t.Errorf("%s matched %q", name, "Sales of carbonated drinks rise")
+		}
+	}
+
+	// 前方一致をやめた派生語は個別の語で一致する
+	for _, title := range []string{
+		"EU backs low-carbon steel",
+		"Firm goes carbon-neutral",
+		"Shipping emissions rise",
+		"Project cuts 2 MtCO2e",
+		"Voluntary offsetting grows",
+	} {
+		if !carbonFilterAcademic.Match(title, "") {
+			t.Errorf("carbonFilterAcademic did not match %q", title)
+		}
+	}
+	if !carbonFilterEuractiv.Match("Plant emits 5 tCO2e", "") {
+		t.Error("carbonFilterEuractiv did not match tCO2e")
+	}
+	if !carbonFilterJapan.Match("CO2e排出量を公表", "") {
+		t.Error("carbonFilterJapan did not match CO2e")
+	}
+}
+
+func TestSourceFiltersKeyedBySourceID(t *testing.T) {
+	onlyMarkets, err := ParseFilter("market")
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, err := ParseFilter("NOT carbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf := SourceFilters{"carbonherald": onlyMarkets, "carbon herald": none, "*": none}
+
+	headlines := []Headline{
+		{Source: "Carbon Herald", SourceID: "carbonherald", Title: "Carbon market opens"},
+		{Source: "Carbon Herald", SourceID: "carbonherald", Title: "Carbon removal deal"},
+	}
+
+	// 収集時（識別子で適用）
+	kept := sf.FilterHeadlines("carbonherald", headlines)
+	if len(kept) != 1 || kept[0].Title != "Carbon market opens" {
+		t.Fatalf("FilterHeadlines kept %v, want only the market headline", kept)
+	}
+
+	// -filterExplain（見出しの SourceID で引く）も同じフィルタになる
+	if f := sf.For(headlines[0].SourceID, headlines[0].Source, "en"); f != onlyMarkets {
+		t.Errorf("For(id, name) = %v, want the filter keyed by source ID", f)
+	}
+
+	// 識別子が分からない見出しはソース名、どちらもなければ "*"
+	if f := sf.For("", "Carbon Herald", "en"); f != none {
+		t.Errorf("For(\"\", name) = %v, want the filter keyed by source name", f)
+	}
+	if f := sf.For("", "Carbon Brief", "en"); f != sf["*"] {
+		t.Errorf("For(unknown) = %v, want the default filter", f)
+	}
+}
@@ -8,13 +8,15 @@
 //   - HandleShortEmailSend:     50文字ヘッドラインダイジェスト送信
 //   - HandleListShortHeadlines: Article Summary 300診断表示
 //   - HandleNotionClip:         Notionに記事を保存
+//   - HandleStoreSave:          -store で選んだストアに記事を保存
 //   - HandleJSONOutput:         JSON出力
 //
 // 【共通ヘルパー関数】
 //   - validateNotionEnv:    Notion環境変数の検証
 //   - validateEmailEnv:     Email環境変数の検証
 //   - createNotionClipper:  Notionクライアント作成
-//   - fetchNotionHeadlines: Notionから記事取得
+//   - openHeadlineStore:    見出しストア作成（store.go）
+//   - fetchStoredHeadlines: ストアから記事取得
 //
 // =============================================================================
 package pipeline
@@ -24,7 +26,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 )
 
@@ -47,7 +48,7 @@ func validateNotionEnv() (token, dbID string) {
 		fatalf("ERROR: NOTION_TOKEN environment variable is required")
 	}
 	if dbID == "" {
-		fatalf("ERROR: NOTION_DATABASE_ID environment variable is required (run with -notionClip first to create database)")
+		fatalf("ERROR: NOTION_DATABASE_ID environment variable is required (create the database with -notionInit)")
 	}
 	return token, dbID
 }
@@ -56,11 +57,11 @@ func validateNotionEnv() (token, dbID string) {
 //
 // 【必要な環境変数】
 //   - EMAIL_FROM:     送信元メールアドレス
-//   - EMAIL_PASSWORD: Gmailアプリパスワード
-//   - EMAIL_TO:       送信先メールアドレス
+//   - EMAIL_PASSWORD: SMTP認証のパスワード（SMTP_AUTH=none やSMTP以外のMAILERの場合は不要）
+//   - EMAIL_TO:       送信先メールアドレス（requireTo がfalseの場合は不要、購読者リスト使用時）
 //
 // エラー時はfatalf()で終了する
-func validateEmailEnv() (from, password, to string) {
+func validateEmailEnv(requireTo bool) (from, password, to string) {
 	from = os.Getenv("EMAIL_FROM")
 	password = os.Getenv("EMAIL_PASSWORD")
 	to = os.Getenv("EMAIL_TO")
@@ -69,9 +70,15 @@ func validateEmailEnv() (from, password, to string) {
 		fatalf("ERROR: EMAIL_FROM environment variable is required for email sending")
 	}
 	if password == "" {
-		fatalf("ERROR: EMAIL_PASSWORD environment variable is required (use Gmail App Password)")
+		required, err := EmailPasswordRequired()
+		if err != nil {
+			fatalf("ERROR: %v", err)
+		}
+		if required {
+			fatalf("ERROR: EMAIL_PASSWORD environment variable is required for SMTP authentication (for Gmail use an App Password, or set SMTP_AUTH=none / MAILER=file)")
+		}
 	}
-	if to == "" {
+	if to == "" && requireTo {
 		fatalf("ERROR: EMAIL_TO environment variable is required")
 	}
 	return from, password, to
@@ -93,15 +100,29 @@ func createNotionClipper() *NotionClipper {
 	return clipper
 }
 
-// fetchNotionHeadlines はNotionDBから最近の記事を取得する
-func fetchNotionHeadlines(clipper *NotionClipper, daysBack int) []NotionHeadline {
+// openHeadlineStore は -store / -storePath で選んだストアを作成する
+//
+// notion の場合は環境変数のバリデーションも行う
+func openHeadlineStore(cfg *StoreConfig) HeadlineStore {
+	if cfg.Backend == "" || cfg.Backend == StoreBackendNotion {
+		return createNotionClipper()
+	}
+	store, err := OpenHeadlineStore(*cfg)
+	if err != nil {
+		fatalf("ERROR opening headline store: %v", err)
+	}
+	return store
+}
+
+// fetchStoredHeadlines はストアから最近の記事を取得する
+func fetchStoredHeadlines(store HeadlineStore, daysBack int) []NotionHeadline {
 	ctx := context.Background()
-	headlines, err := clipper.FetchRecentHeadlines(ctx, daysBack)
+	headlines, err := store.QueryHeadlines(ctx, RecentHeadlinesQuery(daysBack))
 	if err != nil {
-		fatalf("ERROR fetching headlines from Notion: %v", err)
+		fatalf("ERROR fetching headlines from %s: %v", store.Name(), err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Fetched %d headlines from Notion (last %d days)\n", len(headlines), daysBack)
+	fmt.Fprintf(os.Stderr, "Fetched %d headlines from %s (last %d days)\n", len(headlines), store.Name(), daysBack)
 	return headlines
 }
 
@@ -109,7 +130,7 @@ func fetchNotionHeadlines(clipper *NotionClipper, daysBack int) []NotionHeadline
 //
 // 環境変数のバリデーションも行い、値も返す（表示用）
 func createEmailSender() (*EmailSender, string, string) {
-	from, password, to := validateEmailEnv()
+	from, password, to := validateEmailEnv(true)
 	sender, err := NewEmailSender(from, password, to)
 	if err != nil {
 		fatalf("ERROR creating email sender: %v", err)
@@ -124,21 +145,33 @@ func createEmailSender() (*EmailSender, string, string) {
 // HandleShortEmailSend は50文字ヘッドラインダイジェストメールを送信する
 //
 // 【処理の流れ】
-//  1. 環境変数をチェック（Notion + Email）
-//  2. NotionDBから記事を取得
+//  1. 環境変数をチェック（ストア + Email）
+//  2. ストア（NotionDBまたはファイル）から記事を取得
 //  3. カーボンキーワードでフィルタリング（email.go内で実行）
-//  4. 50文字ヘッドライン + URLのメールを送信
-func HandleShortEmailSend(emailDaysBack int) {
+//  4. 50文字ヘッドライン + URLのメール（HTML + プレーンテキスト）を送信
+func HandleShortEmailSend(cfg *EmailModeConfig, storeCfg *StoreConfig) {
+	if cfg.SubscribersFile != "" {
+		handleSubscriberEmailSend(cfg, storeCfg)
+		return
+	}
+
 	fmt.Fprintln(os.Stderr, "\n========================================")
 	fmt.Fprintln(os.Stderr, "📧 Sending Short Headlines Digest")
 	fmt.Fprintln(os.Stderr, "========================================")
 
-	// Notionクリッパーを作成してヘッドラインを取得
-	clipper := createNotionClipper()
-	headlines := fetchNotionHeadlines(clipper, emailDaysBack)
+	// ストアからヘッドラインを取得
+	store := openHeadlineStore(storeCfg)
+	headlines := fetchStoredHeadlines(store, cfg.DaysBack)
 
 	// メール送信者を作成して送信（0件でも送信する）
 	sender, from, to := createEmailSender()
+	if err := sender.SetLanguage(cfg.Language); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	if err := sender.SetLayout(cfg.Layout); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
 	ctx := context.Background()
 	if err := sender.SendShortHeadlinesDigest(ctx, headlines); err != nil {
 		fatalf("ERROR sending email: %v", err)
@@ -150,22 +183,70 @@ func HandleShortEmailSend(emailDaysBack int) {
 	fmt.Fprintln(os.Stderr, "========================================")
 }
 
+// handleSubscriberEmailSend は購読者リストの各購読者にダイジェストを1通ずつ送信する（subscribers.go）
+//
+// 購読者ごとに形式（short / full）が決まるため、-sendShortEmail でも full を送ることがある。
+// 1人でも送信に失敗した場合は、残りの購読者に送った後でエラー終了する。
+func handleSubscriberEmailSend(cfg *EmailModeConfig, storeCfg *StoreConfig) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "📧 Sending Subscriber Digests")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	subs, err := LoadSubscribers(cfg.SubscribersFile)
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	now := time.Now()
+	since := SubscribersSince(subs, now, cfg.DaysBack)
+	if since.IsZero() {
+		fmt.Fprintf(os.Stderr, "No subscribers are scheduled for %s (%d subscribers)\n", now.Format("2006-01-02 Mon"), len(subs))
+		fmt.Fprintln(os.Stderr, "========================================")
+		return
+	}
+
+	from, password, _ := validateEmailEnv(false)
+	sender, err := NewSubscriberSender(from, password)
+	if err != nil {
+		fatalf("ERROR creating email sender: %v", err)
+	}
+	if err := sender.SetLayout(cfg.Layout); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+
+	store := openHeadlineStore(storeCfg)
+	ctx := context.Background()
+	headlines, err := store.QueryHeadlines(ctx, HeadlineQuery{CreatedOnOrAfter: since, Sort: HeadlineSortCreatedDesc})
+	if err != nil {
+		fatalf("ERROR fetching headlines from %s: %v", store.Name(), err)
+	}
+	fmt.Fprintf(os.Stderr, "Fetched %d headlines from %s (since %s)\n", len(headlines), store.Name(), since.Format("2006-01-02"))
+
+	result := sender.SendSubscriberDigests(ctx, headlines, subs, now, cfg.DaysBack)
+	fmt.Fprintf(os.Stderr, "Sent: %d, not scheduled today: %d, failed: %d\n", result.Sent, result.Skipped, len(result.Failed))
+	if err := result.Err(); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "✅ Subscriber digests sent successfully")
+	fmt.Fprintln(os.Stderr, "========================================")
+}
+
 // =============================================================================
 // 診断ハンドラ
 // =============================================================================
 
-// HandleListShortHeadlines はNotionDBのArticle Summary 300値を一覧表示する
+// HandleListShortHeadlines はストア（NotionDB等）のArticle Summary 300値を一覧表示する
 //
 // Notion AIによるフィルタリング結果を確認するための診断機能。
 // Article Summary 300の状態（要約あり、"-"、空）でグループ化して表示する。
-func HandleListShortHeadlines(emailDaysBack int) {
+func HandleListShortHeadlines(emailDaysBack int, storeCfg *StoreConfig) {
 	fmt.Fprintln(os.Stderr, "\n========================================")
 	fmt.Fprintln(os.Stderr, "📋 Listing Article Summary 300 Values from NotionDB")
 	fmt.Fprintln(os.Stderr, "========================================")
 
-	// Notionクリッパーを作成してヘッドラインを取得
-	clipper := createNotionClipper()
-	headlines := fetchNotionHeadlines(clipper, emailDaysBack)
+	// ストアからヘッドラインを取得
+	store := openHeadlineStore(storeCfg)
+	headlines := fetchStoredHeadlines(store, emailDaysBack)
 
 	fmt.Fprintf(os.Stderr, "Found %d headlines (last %d days)\n\n", len(headlines), emailDaysBack)
 
@@ -187,6 +268,15 @@ func HandleListShortHeadlines(emailDaysBack int) {
 	fmt.Fprintf(os.Stderr, "   ✅ With Summary: %d\n", len(withSummary))
 	fmt.Fprintf(os.Stderr, "   ❌ Filtered (-): %d\n", len(withDash))
 	fmt.Fprintf(os.Stderr, "   ⏳ Empty:        %d\n", len(empty))
+
+	// Notion AI未処理（空または本文のまま）で抽出型要約を使う記事数
+	extracted := 0
+	for _, h := range headlines {
+		if isUnsummarized(h) && (h.ExtractedSummary != "" || h.ShortHeadline != "") {
+			extracted++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "   🤖 Extracted fallback: %d\n", extracted)
 	fmt.Fprintln(os.Stderr, "")
 
 	// 要約ありのヘッドラインを表示
@@ -234,15 +324,43 @@ func HandleListShortHeadlines(emailDaysBack int) {
 type NotionClipResult struct {
 	Clipped int
 	Failed  int
-	Errors  []string // "[Notion] 'タイトル': エラー内容" 形式
+	Errors  []string // "[Notion/種類] 'タイトル': エラー内容" 形式
+
+	// 失敗の詳細（種類別の集計・対処方法の表示に使用、notion_errors.go）
+	Failures []NotionClipFailure
+
+	// スループット（notion_batch.go の ClipHeadlines で設定）
+	Duration    time.Duration // クリップ全体の所要時間
+	Requests    int64         // Notion APIへのリクエスト数（429の再送を含む）
+	RateLimited int64         // 429を受けた回数
+}
+
+// ArticlesPerMinute は1分あたりのクリップ件数を返す
+func (r *NotionClipResult) ArticlesPerMinute() float64 {
+	if r == nil || r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Clipped+r.Failed) / r.Duration.Minutes()
+}
+
+// ThroughputSummary はスループットの1行表示を返す（ログ用）
+func (r *NotionClipResult) ThroughputSummary() string {
+	if r == nil {
+		return ""
+	}
+	rps := 0.0
+	if r.Duration > 0 {
+		rps = float64(r.Requests) / r.Duration.Seconds()
+	}
+	return fmt.Sprintf("%d clipped, %d failed in %v (%.1f articles/min, %d requests, %.2f req/s, %d rate-limited)",
+		r.Clipped, r.Failed, r.Duration.Round(time.Second), r.ArticlesPerMinute(), r.Requests, rps, r.RateLimited)
 }
 
 // HandleNotionClip は見出しをNotionデータベースに保存する
 //
 // 【処理の流れ】
-//  1. Notion環境変数を確認
-//  2. 必要に応じて新規データベースを作成
-//  3. 各見出しをクリップ
+//  1. Notion環境変数を確認（データベースは -notionInit で作成済みであること）
+//  2. 見出しを並列でクリップ（NOTION_CLIP_WORKERS、レート制限付き）
 func HandleNotionClip(headlines []Headline, cfg *OutputConfig) *NotionClipResult {
 	fmt.Fprintln(os.Stderr, "\n========================================")
 	fmt.Fprintln(os.Stderr, "📎 Clipping to Notion Database")
@@ -252,6 +370,9 @@ func HandleNotionClip(headlines []Headline, cfg *OutputConfig) *NotionClipResult
 	if notionToken == "" {
 		fatalf("NOTION_TOKEN environment variable is required for Notion integration")
 	}
+	if cfg.NotionDatabaseID == "" {
+		fatalf("NOTION_DATABASE_ID (or -notionDatabaseID) is required; create the database with -notionInit -notionPageID <page>")
+	}
 
 	clipper, err := NewNotionClipper(notionToken, cfg.NotionDatabaseID)
 	if err != nil {
@@ -259,46 +380,15 @@ func HandleNotionClip(headlines []Headline, cfg *OutputConfig) *NotionClipResult
 	}
 
 	ctx := context.Background()
+	fmt.Fprintf(os.Stderr, "Using Notion database: %s\n", cfg.NotionDatabaseID)
 
-	// 必要に応じてデータベースを作成
-	if cfg.NotionDatabaseID == "" {
-		if cfg.NotionPageID == "" {
-			fatalf("-notionPageID is required when creating a new Notion database")
-		}
-		fmt.Fprintln(os.Stderr, "Creating new Notion database...")
-		dbID, err := clipper.CreateDatabase(ctx, cfg.NotionPageID)
-		if err != nil {
-			fatalf("creating Notion database: %v", err)
-		}
-
-		// データベースIDを.envに保存
-		if err := appendToEnvFile(".env", "NOTION_DATABASE_ID", dbID); err != nil {
-			warnf("Failed to save database ID to .env: %v", err)
-			fmt.Fprintf(os.Stderr, "Please manually add to .env:\nNOTION_DATABASE_ID=%s\n", dbID)
-		} else {
-			fmt.Fprintf(os.Stderr, "✅ Database ID saved to .env file\n")
-		}
-	} else {
-		fmt.Fprintf(os.Stderr, "Using existing Notion database: %s\n", cfg.NotionDatabaseID)
-	}
-
-	// 各見出しをクリップ
+	// 見出しを並列でクリップ（notion_batch.go）
 	fmt.Fprintln(os.Stderr, "\nClipping articles...")
-	notionResult := &NotionClipResult{}
-	for _, h := range headlines {
-		if err := clipper.ClipHeadline(ctx, h); err != nil {
-			warnf("failed to clip headline '%s': %v", h.Title, err)
-			notionResult.Failed++
-			notionResult.Errors = append(notionResult.Errors,
-				fmt.Sprintf("[Notion] '%s': %v", truncateString(h.Title, 50), err))
-			continue
-		}
-		notionResult.Clipped++
-		fmt.Fprintf(os.Stderr, "  ✅ Clipped: %s\n", truncateString(h.Title, 50))
-	}
+	notionResult := clipper.ClipHeadlines(ctx, headlines, 0)
 
 	fmt.Fprintln(os.Stderr, "========================================")
 	fmt.Fprintf(os.Stderr, "✅ Clipped %d headlines to Notion\n", notionResult.Clipped)
+	fmt.Fprintf(os.Stderr, "   Throughput: %s\n", notionResult.ThroughputSummary())
 	if notionResult.Failed > 0 {
 		fmt.Fprintf(os.Stderr, "⚠️  Failed %d headlines\n", notionResult.Failed)
 	}
@@ -306,6 +396,31 @@ func HandleNotionClip(headlines []Headline, cfg *OutputConfig) *NotionClipResult
 	return notionResult
 }
 
+// HandleStoreSave は見出しを -store で選んだストア（Notionまたはファイル）に保存する
+//
+// Notionの場合は既存のデータベース（NOTION_DATABASE_ID）が必要。
+// データベースの新規作成は -notionInit を使う。
+func HandleStoreSave(headlines []Headline, cfg *StoreConfig) *NotionClipResult {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "💾 Saving to Headline Store")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	store := openHeadlineStore(cfg)
+	fmt.Fprintf(os.Stderr, "Store: %s\n", store.Name())
+	result := store.SaveHeadlines(context.Background(), headlines)
+
+	fmt.Fprintln(os.Stderr, "========================================")
+	fmt.Fprintf(os.Stderr, "✅ Saved %d headlines\n", result.Clipped)
+	if result.Failed > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed %d headlines\n", result.Failed)
+		for _, line := range result.ErrorReport() {
+			fmt.Fprintf(os.Stderr, "   %s\n", line)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "========================================")
+	return result
+}
+
 // =============================================================================
 // JSON出力ハンドラ
 // =============================================================================
@@ -326,112 +441,3 @@ func HandleJSONOutput(headlines []Headline, cfg *OutputConfig) {
 	}
 }
 
-// =============================================================================
-// エラー通知ハンドラ
-// =============================================================================
-
-// SendErrorNotification は収集・Notion保存の問題をメールで通知する
-//
-// collectResultとnotionResultの両方を確認し、問題がなければメール送信しない。
-// EMAIL_FROM, EMAIL_PASSWORD, EMAIL_TO が設定されている場合のみ送信する。
-func SendErrorNotification(collectResult *CollectResult, notionResult *NotionClipResult) {
-	// 問題があるかチェック
-	hasCollectIssues := collectResult != nil && len(collectResult.Errors) > 0
-	hasNotionIssues := notionResult != nil && notionResult.Failed > 0
-	if !hasCollectIssues && !hasNotionIssues {
-		return
-	}
-
-	from := os.Getenv("EMAIL_FROM")
-	password := os.Getenv("EMAIL_PASSWORD")
-	to := os.Getenv("EMAIL_TO")
-
-	if from == "" || password == "" || to == "" {
-		fmt.Fprintln(os.Stderr, "[WARN] Email env vars not set, skipping error notification email")
-		return
-	}
-
-	sender, err := NewEmailSender(from, password, to)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[WARN] Failed to create email sender: %v\n", err)
-		return
-	}
-
-	// issue数をカウント
-	issueCount := 0
-	if hasCollectIssues {
-		issueCount += len(collectResult.Errors)
-	}
-	if hasNotionIssues {
-		issueCount += notionResult.Failed
-	}
-
-	subject := fmt.Sprintf("[Carbon Relay] %d issue(s) - %s",
-		issueCount, time.Now().Format("2006-01-02 15:04"))
-
-	var body strings.Builder
-
-	// === 収集結果 ===
-	if collectResult != nil {
-		body.WriteString("=== 収集結果 ===\n")
-
-		successCount := 0
-		successArticles := 0
-		emptyCount := 0
-		errorCount := 0
-		for _, sr := range collectResult.SourceResults {
-			switch sr.Status {
-			case "success":
-				successCount++
-				successArticles += sr.Count
-			case "empty":
-				emptyCount++
-			case "error":
-				errorCount++
-			}
-		}
-
-		body.WriteString(fmt.Sprintf("総ソース数: %d\n", len(collectResult.SourceResults)))
-		body.WriteString(fmt.Sprintf("成功: %d (計 %d 記事) / 0件: %d / エラー: %d\n",
-			successCount, successArticles, emptyCount, errorCount))
-
-		// 問題のあったソースを表示
-		var problemSources []string
-		for _, sr := range collectResult.SourceResults {
-			switch sr.Status {
-			case "error":
-				problemSources = append(problemSources,
-					fmt.Sprintf("  [ERROR] %s: %s", sr.Name, sr.ErrorMsg))
-			case "empty":
-				problemSources = append(problemSources,
-					fmt.Sprintf("  [WARN]  %s: 0 headlines", sr.Name))
-			}
-		}
-		if len(problemSources) > 0 {
-			body.WriteString("\n--- 問題のあったソース ---\n")
-			for _, ps := range problemSources {
-				body.WriteString(ps + "\n")
-			}
-		}
-	}
-
-	// === Notion保存結果 ===
-	if hasNotionIssues {
-		body.WriteString("\n=== Notion保存結果 ===\n")
-		body.WriteString(fmt.Sprintf("成功: %d / 失敗: %d\n",
-			notionResult.Clipped, notionResult.Failed))
-		for _, e := range notionResult.Errors {
-			body.WriteString("  " + e + "\n")
-		}
-	}
-
-	body.WriteString(fmt.Sprintf("\nTimestamp: %s\n", time.Now().Format(time.RFC3339)))
-
-	msg := sender.BuildEmailMessage(subject, body.String())
-	if err := sender.SendWithRetry(msg); err != nil {
-		fmt.Fprintf(os.Stderr, "[WARN] Failed to send error notification email: %v\n", err)
-	} else {
-		fmt.Fprintln(os.Stderr, "[INFO] Error notification email sent")
-	}
-}
-
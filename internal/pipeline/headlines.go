@@ -224,6 +224,9 @@ type HeadlineSourceConfig struct {
 	UserAgent string        // HTTPリクエスト時のUser-Agentヘッダー
 	Timeout   time.Duration // HTTPリクエストのタイムアウト時間
 	Client    *http.Client  // 共有HTTPクライアント（コネクションプーリング有効）
+
+	// SourceFilters はソース別のフィルタ式（filter.go、nilの場合はフィルタなし）
+	SourceFilters SourceFilters
 }
 
 // DefaultHeadlineConfig はデフォルトの見出し収集設定を返す
@@ -302,6 +305,20 @@ func CollectFromSources(sources []string, perSource int, cfg HeadlineSourceConfi
 			continue
 		}
 
+		// ソース識別子を記録（-filterExplain でも収集時と同じソース別フィルタを引くため）
+		for i := range hs {
+			hs[i].SourceID = src
+		}
+
+		// ソース別フィルタ式を適用（SOURCE_FILTERS_FILE / -filters）
+		if cfg.SourceFilters != nil {
+			before := len(hs)
+			hs = cfg.SourceFilters.FilterHeadlines(src, hs)
+			if os.Getenv("DEBUG_SCRAPING") != "" {
+				fmt.Fprintf(os.Stderr, "[DEBUG] %s: source filter kept %d/%d headlines\n", src, len(hs), before)
+			}
+		}
+
 		if len(hs) == 0 {
 			warnMsg := fmt.Sprintf("[WARN] %s returned 0 headlines", src)
 			fmt.Fprintln(os.Stderr, warnMsg)
@@ -331,6 +348,7 @@ func CollectFromSources(sources []string, perSource int, cfg HeadlineSourceConfi
 	}
 
 	result.Headlines = uniqueHeadlinesByURL(result.Headlines)
+	EnrichHeadlines(result.Headlines)
 	return result, nil
 }
 
@@ -444,8 +462,8 @@ func collectWordPressHeadlines(baseURL, sourceName string, limit int, cfg Headli
 		}
 
 		// 無料記事の全文からHTMLを除去
-		content := cleanHTMLTags(p.Content.Rendered)
-		content = strings.TrimSpace(content)
+		// 見出し・箇条書きの構造を残してHTMLを除去（structure.go）
+		content := htmlToStructuredText(p.Content.Rendered)
 		// Notion AI要約用に3000文字で切り詰め
 		content = truncateString(content, 3000)
 
@@ -501,8 +519,8 @@ func collectWordPressHeadlinesCustomType(baseURL, sourceName, postType string, l
 			continue
 		}
 
-		content := cleanHTMLTags(p.Content.Rendered)
-		content = strings.TrimSpace(content)
+		// 見出し・箇条書きの構造を残してHTMLを除去（structure.go）
+		content := htmlToStructuredText(p.Content.Rendered)
 		// Notion AI要約用に3000文字で切り詰め
 		content = truncateString(content, 3000)
 
@@ -644,7 +662,7 @@ func extractExcerptFromContext(linkSel *goquery.Selection) string {
 			return
 		}
 		text := strings.TrimSpace(s.Text())
-		if text != "" && len(text) > 20 {
+		if text != "" && !isNoiseLine(text) {
 			if excerpt.Len() > 0 {
 				excerpt.WriteString(" ")
 			}
@@ -659,7 +677,7 @@ func extractExcerptFromContext(linkSel *goquery.Selection) string {
 				return
 			}
 			text := strings.TrimSpace(s.Text())
-			if text != "" && len(text) > 20 {
+			if text != "" && !isNoiseLine(text) {
 				if excerpt.Len() > 0 {
 					excerpt.WriteString(" ")
 				}
@@ -671,9 +689,7 @@ func extractExcerptFromContext(linkSel *goquery.Selection) string {
 	result := strings.TrimSpace(excerpt.String())
 
 	// 長すぎる場合は切り詰め
-	if len(result) > maxChars {
-		result = result[:maxChars] + "..."
-	}
+	result = truncateString(result, maxChars)
 
 	if os.Getenv("DEBUG_SCRAPING") != "" && result != "" {
 		fmt.Fprintf(os.Stderr, "[DEBUG] Extracted excerpt from context (%d chars)\n", len(result))
@@ -682,22 +698,6 @@ func extractExcerptFromContext(linkSel *goquery.Selection) string {
 	return result
 }
 
-// matchesKeywords は title または excerpt が keywords のいずれかを含むかチェック
-//
-// キーワードフィルタリングが必要なソース（arXiv, IOP Science, Nature Eco&Evo,
-// ScienceDirect, Euractiv, JRI, Env Ministry, Mizuho R&T）で共通使用。
-func matchesKeywords(title, excerpt string, keywords []string) bool {
-	titleLower := strings.ToLower(title)
-	excerptLower := strings.ToLower(excerpt)
-	for _, kw := range keywords {
-		kwLower := strings.ToLower(kw)
-		if strings.Contains(titleLower, kwLower) || strings.Contains(excerptLower, kwLower) {
-			return true
-		}
-	}
-	return false
-}
-
 // fetchRSSFeed は指定URLからRSS/Atomフィードを取得してパース
 //
 // 共有HTTPクライアントを使用してフィードをフェッチし、gofeedでパースする。
@@ -745,6 +745,20 @@ func extractRSSExcerpt(item *gofeed.Item) string {
 	return strings.TrimSpace(text)
 }
 
+// feedAuthors は gofeed.Item の著者名を返す（Authors がなければ Author、どちらもなければnil）
+func feedAuthors(item *gofeed.Item) []string {
+	var names []string
+	for _, p := range item.Authors {
+		if p != nil {
+			names = append(names, strings.TrimSpace(p.Name))
+		}
+	}
+	if len(names) == 0 && item.Author != nil {
+		names = append(names, strings.TrimSpace(item.Author.Name))
+	}
+	return uniqStrings(names)
+}
+
 // fetchViaCurl は TLS フィンガープリント検出を回避するため curl 経由でURLを取得する。
 // 一部のサイト（例: Fastly を使用する nature.com）は Go の net/http の TLS フィンガープリントを
 // ブロックするが curl は許可する。この関数は回避策として curl を外部呼び出しする。
@@ -0,0 +1,101 @@
+// =============================================================================
+// language.go - 言語判定と言語別の閾値
+// =============================================================================
+//
+// このファイルは記事の言語（日本語/英語）を判定し、言語ごとに異なる処理の閾値を提供します。
+//
+// 【背景】
+//   - 日本語ソース（JRI、JPX、PwC Japan、みずほRT、CarbonCredits.jp）と英語ソースが
+//     同じテキスト処理を通っていた
+//   - len() はバイト数のため、日本語は英語の約3倍の長さとして扱われていた
+//   - 日本語は英語より情報密度が高く、同じ文字数でも内容量が異なる
+//
+// 【判定方法】
+//   ひらがな・カタカナまたは漢字が文字の一定割合を超える場合は日本語、
+//   それ以外でラテン文字があれば英語と判定する。
+//
+// 【言語別の処理】
+//   - isNoiseLine:        ナビゲーション等の短い行の判定閾値
+//   - SummaryMaxRunesFor: 抽出型要約の最大文字数
+//   - SourceFilters.For:  "lang:ja" / "lang:en" キーによる言語別フィルタ式
+//   - メールダイジェスト:  言語別セクション、EMAIL_LANGUAGE による絞り込み
+//
+// =============================================================================
+package pipeline
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 言語コード（ISO 639-1）
+const (
+	LangJapanese = "ja"
+	LangEnglish  = "en"
+)
+
+// DetectLanguage はテキストの言語を判定する（判定できない場合は空文字）
+func DetectLanguage(text string) string {
+	var kana, han, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case r < 0x80 && unicode.IsLetter(r):
+			latin++
+		}
+	}
+	switch {
+	case kana > 0 && kana*5 >= kana+han+latin:
+		// 英語記事中の固有名詞程度のカナは無視する（20%未満）
+		return LangJapanese
+	case han > 0 && han*5 >= han+latin:
+		return LangJapanese
+	case latin > 0:
+		return LangEnglish
+	}
+	return ""
+}
+
+// headlineLanguage は見出しの言語を返す（未設定の場合はタイトルと本文から判定）
+func headlineLanguage(h Headline) string {
+	if h.Language != "" {
+		return h.Language
+	}
+	return DetectLanguage(h.Title + " " + truncateString(h.Excerpt, 500))
+}
+
+// isNoiseLine はナビゲーション・カテゴリラベル等の短い行かを判定する
+//
+// 日本語は1文字あたりの情報量が多いため、英語より短い閾値を使う。
+func isNoiseLine(line string) bool {
+	n := utf8.RuneCountInString(strings.TrimSpace(line))
+	if DetectLanguage(line) == LangJapanese {
+		return n < 12
+	}
+	return n < 20
+}
+
+// SummaryMaxRunesFor は言語ごとの抽出型要約の最大文字数を返す
+//
+// 日本語150文字は英語300文字とおおむね同じ情報量になる。
+func SummaryMaxRunesFor(lang string) int {
+	if lang == LangJapanese {
+		return SummaryMaxRunes / 2
+	}
+	return SummaryMaxRunes
+}
+
+// languageLabel はメールのセクション見出しに使う言語名を返す
+func languageLabel(lang string) string {
+	switch lang {
+	case LangJapanese:
+		return "日本語"
+	case LangEnglish:
+		return "English"
+	}
+	return "その他"
+}
@@ -0,0 +1,289 @@
+// =============================================================================
+// mailer.go - メールの配送先（SMTP / ファイル / HTTP API）
+// =============================================================================
+//
+// このファイルは組み立て済みのメッセージを配送する Mailer インターフェースと、その実装を提供します。
+// ダイジェスト（SendShortHeadlinesDigest / SendHeadlinesSummary）とエラー通知は
+// いずれも EmailSender.SendWithRetry → Mailer.Send を通るため、本文の生成を変えずに
+// 配送方法だけを切り替えられます。
+//
+// 【バックエンド】（MAILER）
+//
+//	smtp    - SMTPサーバー（email_smtp.go の SMTP_* 設定）。デフォルト
+//	file    - MAILER_DIR に1通ずつ .eml ファイルとして書き出す（プレビュー・テスト用）
+//	maildir - MAILER_DIR をMaildir（tmp/ new/ cur/）として配送する（メールクライアントで確認可能）
+//	http    - MAILER_HTTP_URL にJSONでPOSTする（メール配信サービスのHTTP API）
+//
+// 【HTTP API】
+//
+//	POST {MAILER_HTTP_URL}
+//	Authorization: Bearer {MAILER_HTTP_TOKEN}   ← 設定した場合のみ
+//	{"from": "...", "to": ["..."], "raw": "<RFC 5322 メッセージのbase64>"}
+//
+//   2xx 以外の応答はエラー（SendWithRetry が再送する）。
+//   ローカルでは cmd/mail-fake（internal/mailfake）を配信サービスの代わりに使える。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// メール配送のバックエンド
+const (
+	MailerSMTP    = "smtp"
+	MailerFile    = "file"
+	MailerMaildir = "maildir"
+	MailerHTTP    = "http"
+)
+
+// DefaultMailerDir はファイル・Maildirの書き出し先のデフォルト
+const DefaultMailerDir = "mail"
+
+// Mailer は組み立て済みのメッセージを配送する
+type Mailer interface {
+	// Name はログ・エラー表示用の配送先の名前
+	Name() string
+
+	// Send はメッセージを to の全員に配送する
+	Send(ctx context.Context, from string, to []string, msg []byte) error
+}
+
+// MailerConfig は配送先の選択（MAILER / MAILER_DIR / MAILER_HTTP_URL / MAILER_HTTP_TOKEN）
+type MailerConfig struct {
+	Backend   string // smtp / file / maildir / http（空の場合は smtp）
+	Dir       string // file / maildir の書き出し先
+	HTTPURL   string // http のエンドポイント
+	HTTPToken string // http のBearerトークン（空の場合は送らない）
+}
+
+// MailerConfigFromEnv は環境変数から配送先の設定を読み込み、検証する
+func MailerConfigFromEnv() (MailerConfig, error) {
+	cfg := MailerConfig{
+		Backend:   strings.ToLower(strings.TrimSpace(os.Getenv("MAILER"))),
+		Dir:       strings.TrimSpace(os.Getenv("MAILER_DIR")),
+		HTTPURL:   strings.TrimSpace(os.Getenv("MAILER_HTTP_URL")),
+		HTTPToken: os.Getenv("MAILER_HTTP_TOKEN"),
+	}
+	switch cfg.Backend {
+	case "":
+		cfg.Backend = MailerSMTP
+	case MailerSMTP, MailerFile, MailerMaildir:
+	case MailerHTTP:
+		if cfg.HTTPURL == "" {
+			return cfg, fmt.Errorf("MAILER_HTTP_URL is required for MAILER=http")
+		}
+	default:
+		return cfg, fmt.Errorf("unsupported MAILER %q (use smtp, file, maildir or http)", cfg.Backend)
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultMailerDir
+	}
+	return cfg, nil
+}
+
+// EmailPasswordRequired は現在の配送設定でEMAIL_PASSWORDが必要かを返す
+//
+// SMTPでSMTP_AUTHが none 以外の場合のみ必要。
+func EmailPasswordRequired() (bool, error) {
+	mcfg, err := MailerConfigFromEnv()
+	if err != nil {
+		return false, err
+	}
+	if mcfg.Backend != MailerSMTP {
+		return false, nil
+	}
+	scfg, err := SMTPConfigFromEnv()
+	if err != nil {
+		return false, err
+	}
+	return scfg.RequiresPassword(), nil
+}
+
+// EmailCredentialsSet はメール送信に必要な値がそろっているかを返す
+//
+// SMTP以外の配送先や SMTP_AUTH=none の場合はパスワードがなくてもよい。
+// エラー通知の送信可否の判定に使う。
+func EmailCredentialsSet(from, password, to string) bool {
+	if from == "" || to == "" {
+		return false
+	}
+	if password != "" {
+		return true
+	}
+	required, err := EmailPasswordRequired()
+	return err == nil && !required
+}
+
+// NewMailer は設定に応じた Mailer を作成する（password はSMTP認証に使用）
+func NewMailer(cfg MailerConfig, password string) (Mailer, error) {
+	switch cfg.Backend {
+	case MailerSMTP, "":
+		scfg, err := SMTPConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return &SMTPMailer{Config: scfg, Password: password}, nil
+	case MailerFile:
+		return &FileMailer{Dir: cfg.Dir}, nil
+	case MailerMaildir:
+		return &FileMailer{Dir: cfg.Dir, Maildir: true}, nil
+	case MailerHTTP:
+		return &HTTPMailer{URL: cfg.HTTPURL, Token: cfg.HTTPToken, Client: &http.Client{Timeout: 30 * time.Second}}, nil
+	}
+	return nil, fmt.Errorf("unsupported MAILER %q", cfg.Backend)
+}
+
+// =============================================================================
+// SMTP
+// =============================================================================
+
+// SMTPMailer はSMTPサーバーに配送する（email_smtp.go）
+type SMTPMailer struct {
+	Config   SMTPConfig
+	Password string
+}
+
+// Name は配送先の名前を返す
+func (m *SMTPMailer) Name() string {
+	return "SMTP " + m.Config.Addr()
+}
+
+// Send はSMTPで配送する
+func (m *SMTPMailer) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	return sendSMTP(ctx, m.Config, m.Password, from, to, msg)
+}
+
+// =============================================================================
+// ファイル / Maildir
+// =============================================================================
+
+// fileMailerSeq は同じ時刻に書き出すファイル名を区別する連番
+var fileMailerSeq atomic.Int64
+
+// FileMailer はメッセージをファイルとして書き出す（実際には送信しない）
+type FileMailer struct {
+	Dir     string
+	Maildir bool // trueの場合はMaildir（tmp/ に書いてから new/ に移動）
+}
+
+// Name は配送先の名前を返す
+func (m *FileMailer) Name() string {
+	if m.Maildir {
+		return "maildir " + m.Dir
+	}
+	return "file " + m.Dir
+}
+
+// Send はメッセージを1通1ファイルで書き出す
+//
+// 宛先はメッセージのヘッダー（To / Bcc）のとおりで、ファイルには含めない。
+func (m *FileMailer) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	now := time.Now()
+	seq := fileMailerSeq.Add(1)
+
+	if !m.Maildir {
+		if err := os.MkdirAll(m.Dir, 0755); err != nil {
+			return fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		name := fmt.Sprintf("%s-%d-%03d.eml", now.Format("20060102-150405"), os.Getpid(), seq)
+		path := filepath.Join(m.Dir, name)
+		if err := os.WriteFile(path, msg, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "📝 Wrote email to %s\n", path)
+		return nil
+	}
+
+	// Maildir: 一意な名前で tmp/ に書き、完成してから new/ に rename する
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(m.Dir, sub), 0755); err != nil {
+			return fmt.Errorf("failed to create maildir: %w", err)
+		}
+	}
+	host, _ := os.Hostname()
+	name := fmt.Sprintf("%d.P%dQ%d.%s", now.Unix(), os.Getpid(), seq, strings.ReplaceAll(host, "/", "_"))
+	tmp := filepath.Join(m.Dir, "tmp", name)
+	if err := os.WriteFile(tmp, msg, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	dst := filepath.Join(m.Dir, "new", name)
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to deliver to maildir: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "📝 Delivered email to %s\n", dst)
+	return nil
+}
+
+// =============================================================================
+// HTTP API
+// =============================================================================
+
+// HTTPMailer はメール配信サービスのHTTP APIに配送する
+type HTTPMailer struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// httpMailRequest はHTTP APIに送るJSON
+type httpMailRequest struct {
+	From string   `json:"from"`
+	To   []string `json:"to"`
+	Raw  string   `json:"raw"` // RFC 5322 メッセージのbase64
+}
+
+// Name は配送先の名前を返す
+func (m *HTTPMailer) Name() string {
+	return "HTTP " + m.URL
+}
+
+// Send はメッセージをJSONでPOSTする
+func (m *HTTPMailer) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	body, err := json.Marshal(httpMailRequest{
+		From: from,
+		To:   to,
+		Raw:  base64.StdEncoding.EncodeToString(msg),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid MAILER_HTTP_URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	}
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mail API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("mail API returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
@@ -10,14 +10,15 @@
 // =============================================================================
 //
 // 1. データベース作成
-//    - 新規Notionデータベースの自動作成
-//    - 作成したデータベースIDを.envに自動保存
+//    - -notionInit で新規Notionデータベースを作成（notion_init.go）
+//    - クリップ時にはデータベースを作成しない（NOTION_DATABASE_ID が必須）
 //
 // 2. 記事のクリッピング
 //    - 記事の見出しをデータベースに保存
 //
 // 3. 記事の取得
 //    - Notionデータベースから最近の記事を取得
+//    - 期間・Type・Sourceの絞り込みと並び替えはNotion API側で行う（notion_query.go）
 //    - メール送信機能で使用
 //
 // =============================================================================
@@ -35,8 +36,16 @@
 //   │ Type           │ Select       │ Headline / Related Free        │
 //   │ Score          │ Number       │ マッチングスコア（0-1）        │
 //   │ Published Date │ Date         │ 記事の公開日                   │
+//   │ Registries 等  │ Multi-select │ 抽出エンティティ（entities.go）│
+//   │ Prices         │ Text         │ 価格・取引量（prices.go）      │
+//   │ Extracted Summary │ Text      │ 抽出型要約（summarize.go）     │
+//   │ Language       │ Select       │ ja / en（language.go）         │
+//   │ Authors        │ Text         │ 著者（カンマ区切り）           │
+//   │ Topics         │ Multi-select │ トピック（topics.go）          │
 //   └────────────────┴──────────────┴────────────────────────────────┘
 //
+// スキーマの定義とバージョン管理は notion_schema.go を参照（-notionMigrate）。
+//
 // =============================================================================
 // 【Notion API制限への対応】
 // =============================================================================
@@ -72,44 +81,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jomei/notionapi" // Notion API クライアントライブラリ
 )
 
-// notionRetry はNotion API呼び出しをリトライ付きで実行する
-// レート制限（429）やサーバーエラー（5xx）でHTMLが返された場合、
-// JSONパースエラー（"invalid character '<'"）として検出しリトライする
-func notionRetry(operation string, fn func() error) error {
-	const maxRetries = 3
-	var lastErr error
-
-	for i := 0; i <= maxRetries; i++ {
-		if i > 0 {
-			wait := time.Duration(1<<uint(i)) * time.Second // 2s, 4s, 8s
-			fmt.Fprintf(os.Stderr, "⏳ Notion API retry %d/%d for %s (waiting %v)...\n", i, maxRetries, operation, wait)
-			time.Sleep(wait)
-		}
-
-		err := fn()
-		if err == nil {
-			if i > 0 {
-				fmt.Fprintf(os.Stderr, "✅ Notion API %s succeeded after %d retries\n", operation, i)
-			}
-			return nil
-		}
-
-		lastErr = err
-
-		// HTMLレスポンス（レート制限/サーバーエラー）の場合のみリトライ
-		if !strings.Contains(err.Error(), "invalid character '<'") {
-			return err
-		}
-	}
-
-	return lastErr
-}
-
 // academicSources は査読付き学術論文・プレプリントのソース
 var academicSources = map[string]bool{
 	"arXiv":              true,
@@ -138,7 +115,8 @@ type NotionClipperConfig struct {
 type NotionClipper struct {
 	client                     *notionapi.Client     // Notion APIクライアント
 	dbID                       notionapi.DatabaseID  // 操作対象のデータベースID
-	shortHeadlinePropertyEnsured bool                // Article Summary 300プロパティ確認済みフラグ
+	transport                  *notionTransport      // レート制限付きトランスポート（notion_batch.go）
+	schemaOnce                 sync.Once             // スキーママイグレーションを一度だけ実行
 }
 
 // NewNotionClipper は新しいNotionクリッパーを作成する
@@ -147,10 +125,13 @@ func NewNotionClipper(token string, databaseID string) (*NotionClipper, error) {
 		return nil, fmt.Errorf("NOTION_TOKEN is required")
 	}
 
-	client := notionapi.NewClient(notionapi.Token(token))
+	// 全リクエストを共有のレート制限付きトランスポート経由で送信する
+	httpClient, transport := newNotionHTTPClient()
+	client := notionapi.NewClient(notionapi.Token(token), notionapi.WithHTTPClient(httpClient))
 
 	nc := &NotionClipper{
-		client: client,
+		client:    client,
+		transport: transport,
 	}
 
 	if databaseID != "" {
@@ -175,67 +156,12 @@ func (nc *NotionClipper) CreateDatabase(ctx context.Context, pageID string) (str
 		Title: []notionapi.RichText{
 			{
 				Text: &notionapi.Text{
-					Content: "Carbon News Clippings",
-				},
-			},
-		},
-		Properties: notionapi.PropertyConfigs{
-			"Title": notionapi.TitlePropertyConfig{
-				Type: notionapi.PropertyConfigTypeTitle,
-			},
-			"URL": notionapi.URLPropertyConfig{
-				Type: notionapi.PropertyConfigTypeURL,
-			},
-			"Source": notionapi.SelectPropertyConfig{
-				Type: notionapi.PropertyConfigTypeSelect,
-				Select: notionapi.Select{
-					Options: []notionapi.Option{
-						{Name: "CarbonCredits.jp", Color: notionapi.ColorOrange},
-						{Name: "Carbon Herald", Color: notionapi.ColorPink},
-						{Name: "Climate Home News", Color: notionapi.ColorPurple},
-						{Name: "CarbonCredits.com", Color: notionapi.ColorYellow},
-						{Name: "Sandbag", Color: notionapi.ColorBlue},
-						{Name: "Ecosystem Marketplace", Color: notionapi.ColorGreen},
-						{Name: "Carbon Brief", Color: notionapi.ColorPurple},
-						{Name: "ICAP", Color: notionapi.ColorRed},
-						{Name: "IETA", Color: notionapi.ColorBrown},
-						{Name: "Energy Monitor", Color: notionapi.ColorPink},
-						{Name: "Japan Research Institute", Color: notionapi.ColorGreen},
-						{Name: "Japan Environment Ministry", Color: notionapi.ColorBlue},
-						{Name: "Japan Exchange Group (JPX)", Color: notionapi.ColorRed},
-						{Name: "Japan Ministry of Economy (METI)", Color: notionapi.ColorRed},
-						{Name: "World Bank", Color: notionapi.ColorBrown},
-						{Name: "Carbon Market Watch", Color: notionapi.ColorPurple},
-						{Name: "NewClimate Institute", Color: notionapi.ColorGreen},
-						{Name: "Carbon Knowledge Hub", Color: notionapi.ColorOrange},
-						{Name: "PwC Japan", Color: notionapi.ColorPink},
-						{Name: "Mizuho Research & Technologies", Color: notionapi.ColorBlue},
-						{Name: "Free Article", Color: notionapi.ColorDefault},
-					},
-				},
-			},
-			"Article Summary 300": notionapi.RichTextPropertyConfig{
-				Type: notionapi.PropertyConfigTypeRichText,
-			},
-			"Type": notionapi.SelectPropertyConfig{
-				Type: notionapi.PropertyConfigTypeSelect,
-				Select: notionapi.Select{
-					Options: []notionapi.Option{
-						{Name: "News", Color: notionapi.ColorBlue},
-						{Name: "Academic", Color: notionapi.ColorGreen},
-					},
-				},
-			},
-			"Score": notionapi.NumberPropertyConfig{
-				Type: notionapi.PropertyConfigTypeNumber,
-				Number: notionapi.NumberFormat{
-					Format: notionapi.FormatNumber,
+					Content: NotionDatabaseTitle,
 				},
 			},
-			"Published Date": notionapi.DatePropertyConfig{
-				Type: notionapi.PropertyConfigTypeDate,
-			},
 		},
+		// プロパティはスキーマ定義（notion_schema.go）の最新バージョン
+		Properties: notionSchemaPropertyConfigs(),
 	}
 
 	db, err := nc.client.Database.Create(ctx, dbRequest)
@@ -250,71 +176,14 @@ func (nc *NotionClipper) CreateDatabase(ctx context.Context, pageID string) (str
 	return string(db.ID), nil
 }
 
-// ensureShortHeadlineProperty は既存のデータベースにArticle Summary 300プロパティを追加する
-//
-// 【背景】
-//   - 既存のデータベースにはArticle Summary 300プロパティが存在しない場合がある
-//   - この関数はプロパティが存在しない場合のみ追加する
-//   - 既存プロパティのAI機能設定を上書きしないよう、存在確認してから追加
-func (nc *NotionClipper) ensureShortHeadlineProperty(ctx context.Context) error {
-	// 既に確認済みの場合はスキップ
-	if nc.shortHeadlinePropertyEnsured {
-		return nil
-	}
-
-	if nc.dbID == "" {
-		return nil
-	}
-
-	// データベースのスキーマを取得してArticle Summary 300プロパティの存在を確認
-	db, err := nc.client.Database.Get(ctx, nc.dbID)
-	if err != nil {
-		if os.Getenv("DEBUG_SCRAPING") != "" {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Failed to get database schema: %v\n", err)
-		}
-		nc.shortHeadlinePropertyEnsured = true
-		return nil
-	}
-
-	// Article Summary 300プロパティが既に存在する場合はスキップ（AI機能設定を保持）
-	if _, exists := db.Properties["Article Summary 300"]; exists {
-		if os.Getenv("DEBUG_SCRAPING") != "" {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Article Summary 300 property already exists, skipping update\n")
-		}
-		nc.shortHeadlinePropertyEnsured = true
-		return nil
-	}
-
-	// Article Summary 300プロパティが存在しない場合のみ追加
-	_, err = nc.client.Database.Update(ctx, nc.dbID, &notionapi.DatabaseUpdateRequest{
-		Properties: notionapi.PropertyConfigs{
-			"Article Summary 300": notionapi.RichTextPropertyConfig{
-				Type: notionapi.PropertyConfigTypeRichText,
-			},
-		},
-	})
-	if err != nil {
-		if os.Getenv("DEBUG_SCRAPING") != "" {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Failed to add Article Summary 300 property: %v\n", err)
-		}
-	} else {
-		if os.Getenv("DEBUG_SCRAPING") != "" {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Article Summary 300 property added to database\n")
-		}
-	}
-
-	nc.shortHeadlinePropertyEnsured = true
-	return nil
-}
-
 // ClipHeadline はヘッドラインをNotionにクリップする
 func (nc *NotionClipper) ClipHeadline(ctx context.Context, h Headline) error {
 	if nc.dbID == "" {
 		return fmt.Errorf("database ID not set")
 	}
 
-	// 既存DBにArticle Summary 300プロパティがない場合に追加
-	nc.ensureShortHeadlineProperty(ctx)
+	// 既存DBに不足しているプロパティ・セレクトオプションを追加（notion_schema.go）
+	nc.ensureSchema(ctx)
 
 	properties := notionapi.Properties{
 		"Title": notionapi.TitleProperty{
@@ -364,10 +233,61 @@ func (nc *NotionClipper) ClipHeadline(ctx context.Context, h Headline) error {
 		}
 	}
 
-	// Article Summary 300フィールドに全文を追加
+	// 抽出エンティティをマルチセレクトプロパティに追加
+	for name, prop := range entityProperties(h.Entities) {
+		properties[name] = prop
+	}
+
+	// 抽出した価格・取引量を1行1件で追加
+	if len(h.Prices) > 0 {
+		properties["Prices"] = notionapi.RichTextProperty{
+			Type:     notionapi.PropertyTypeRichText,
+			RichText: splitIntoRichTextBlocks(formatPriceMentions(h.Prices)),
+		}
+	}
+
+	// 言語を追加
+	lang := headlineLanguage(h)
+	if lang != "" {
+		properties["Language"] = notionapi.SelectProperty{
+			Type:   notionapi.PropertyTypeSelect,
+			Select: notionapi.Option{Name: lang},
+		}
+	}
+
+	// 抽出型要約を追加（Notion AIが要約しなかった場合のメール用代替）
+	summary := h.Summary
+	if summary == "" {
+		summary = SummarizeExtractive(h.Excerpt, SummaryMaxRunesFor(lang))
+	}
+	if summary != "" {
+		properties["Extracted Summary"] = notionapi.RichTextProperty{
+			Type:     notionapi.PropertyTypeRichText,
+			RichText: splitIntoRichTextBlocks(summary),
+		}
+	}
+
+	// 著者・トピックを追加
+	if len(h.Authors) > 0 {
+		properties["Authors"] = notionapi.RichTextProperty{
+			Type:     notionapi.PropertyTypeRichText,
+			RichText: splitIntoRichTextBlocks(strings.Join(h.Authors, ", ")),
+		}
+	}
+	if len(h.Topics) > 0 {
+		properties["Topics"] = multiSelectProperty(h.Topics)
+	}
+
+	// 編集者が除外するまでダイジェストに含める（チェックボックスの未設定は false のため明示する）
+	properties[EditorialIncludeProperty] = notionapi.CheckboxProperty{
+		Type:     notionapi.PropertyTypeCheckbox,
+		Checkbox: true,
+	}
+
+	// Article Summary 300フィールドに全文を追加（構造記号を除いたプレーンテキスト）
 	// （2000文字制限のため、必要に応じて複数のRichTextブロックに分割）
 	if h.Excerpt != "" {
-		richTextBlocks := splitIntoRichTextBlocks(h.Excerpt)
+		richTextBlocks := splitIntoRichTextBlocks(plainExcerpt(h.Excerpt))
 		properties["Article Summary 300"] = notionapi.RichTextProperty{
 			Type:     notionapi.PropertyTypeRichText,
 			RichText: richTextBlocks,
@@ -384,7 +304,7 @@ func (nc *NotionClipper) ClipHeadline(ctx context.Context, h Headline) error {
 	}
 
 	var page *notionapi.Page
-	err := notionRetry("Page.Create", func() error {
+	err := notionRetryCreate(ctx, "Page.Create", func() error {
 		var createErr error
 		page, createErr = nc.client.Page.Create(ctx, pageRequest)
 		return createErr
@@ -393,28 +313,160 @@ func (nc *NotionClipper) ClipHeadline(ctx context.Context, h Headline) error {
 		return fmt.Errorf("failed to clip headline: %w", err)
 	}
 
-	// 全文をページブロックとして追加（Excerptがある場合）
-	if h.Excerpt != "" {
-		blocks := createContentBlocks(h.Excerpt)
-		if os.Getenv("DEBUG_SCRAPING") != "" {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Adding %d content blocks to page (total chars: %d)\n", len(blocks), len(h.Excerpt))
-		}
+	// ブックマーク・メタデータ・本文をページブロックとして追加
+	blocks := createPageBlocks(h)
+	if os.Getenv("DEBUG_SCRAPING") != "" {
+		fmt.Fprintf(os.Stderr, "[DEBUG] Adding %d content blocks to page (total chars: %d)\n", len(blocks), len(h.Excerpt))
+	}
 
-		// ページにブロックを追加
-		err = notionRetry("Block.AppendChildren", func() error {
-			_, appendErr := nc.client.Block.AppendChildren(ctx, notionapi.BlockID(page.ID), &notionapi.AppendBlockChildrenRequest{
-				Children: blocks,
-			})
-			return appendErr
+	// ページにブロックを追加
+	err = notionRetryCreate(ctx, "Block.AppendChildren", func() error {
+		_, appendErr := nc.client.Block.AppendChildren(ctx, notionapi.BlockID(page.ID), &notionapi.AppendBlockChildrenRequest{
+			Children: blocks,
 		})
-		if err != nil {
-			return fmt.Errorf("failed to add content blocks: %w", err)
-		}
+		return appendErr
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add content blocks: %w", err)
 	}
 
 	return nil
 }
 
+// =============================================================================
+// エンティティ用プロパティ
+// =============================================================================
+
+// entityPropertyNames はエンティティを保存するマルチセレクトプロパティ名
+var entityPropertyNames = []string{
+	"Registries", "Project IDs", "Methodologies", "Jurisdictions", "Article 6 Pairs", "ETS",
+}
+
+// entityValues はプロパティ名ごとのエンティティ値を返す
+func entityValues(e *Entities) map[string][]string {
+	if e == nil {
+		return nil
+	}
+	return map[string][]string{
+		"Registries":      e.Registries,
+		"Project IDs":     e.ProjectIDs,
+		"Methodologies":   e.Methodologies,
+		"Jurisdictions":   e.Countries,
+		"Article 6 Pairs": e.Article6Pairs,
+		"ETS":             e.ETS,
+	}
+}
+
+// entityProperties はエンティティをNotionのマルチセレクトプロパティに変換する
+func entityProperties(e *Entities) notionapi.Properties {
+	props := notionapi.Properties{}
+	for name, values := range entityValues(e) {
+		if len(values) == 0 {
+			continue
+		}
+		props[name] = multiSelectProperty(values)
+	}
+	return props
+}
+
+// multiSelectProperty は値の一覧からマルチセレクトプロパティを作成する
+func multiSelectProperty(values []string) notionapi.MultiSelectProperty {
+	options := make([]notionapi.Option, 0, len(values))
+	for _, v := range values {
+		// マルチセレクトのオプション名にカンマは使用できない
+		options = append(options, notionapi.Option{Name: strings.ReplaceAll(v, ",", " ")})
+	}
+	return notionapi.MultiSelectProperty{
+		Type:        notionapi.PropertyTypeMultiSelect,
+		MultiSelect: options,
+	}
+}
+
+// multiSelectValues はマルチセレクトプロパティの値を返す（プロパティがない場合はnil）
+func multiSelectValues(props notionapi.Properties, name string) []string {
+	prop, ok := props[name].(*notionapi.MultiSelectProperty)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, opt := range prop.MultiSelect {
+		values = append(values, opt.Name)
+	}
+	return values
+}
+
+// authorsFromProperties は "Authors" テキスト（カンマ区切り）から著者を復元する
+func authorsFromProperties(props notionapi.Properties) []string {
+	prop, ok := props["Authors"].(*notionapi.RichTextProperty)
+	if !ok {
+		return nil
+	}
+	var text string
+	for _, rt := range prop.RichText {
+		text += rt.PlainText
+	}
+	var authors []string
+	for _, a := range strings.Split(text, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
+
+// entitiesFromProperties はNotionページのマルチセレクトプロパティからエンティティを復元する
+func entitiesFromProperties(props notionapi.Properties) *Entities {
+	values := map[string][]string{}
+	for _, name := range entityPropertyNames {
+		values[name] = multiSelectValues(props, name)
+	}
+	e := &Entities{
+		Registries:    values["Registries"],
+		ProjectIDs:    values["Project IDs"],
+		Methodologies: values["Methodologies"],
+		Countries:     values["Jurisdictions"],
+		Article6Pairs: values["Article 6 Pairs"],
+		ETS:           values["ETS"],
+	}
+	if e.IsEmpty() {
+		return nil
+	}
+	return e
+}
+
+// =============================================================================
+// 価格・取引量プロパティ
+// =============================================================================
+
+// formatPriceMentions は価格・取引量を "Prices" プロパティ用のテキストに変換する
+func formatPriceMentions(prices []PriceMention) string {
+	lines := make([]string, 0, len(prices))
+	for _, p := range prices {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pricesFromProperties はNotionページの "Prices" プロパティから価格・取引量を復元する
+// （手動で編集された行など、解釈できない行は無視する）
+func pricesFromProperties(props notionapi.Properties) []PriceMention {
+	prop, ok := props["Prices"].(*notionapi.RichTextProperty)
+	if !ok {
+		return nil
+	}
+	text := ""
+	for _, rt := range prop.RichText {
+		text += rt.PlainText
+	}
+	var prices []PriceMention
+	for _, line := range strings.Split(text, "\n") {
+		if p, ok := parsePriceMention(line); ok {
+			prices = append(prices, p)
+		}
+	}
+	return prices
+}
+
 // ClipHeadlineWithRelated はClipHeadlineのエイリアス（Lambda互換用）
 func (nc *NotionClipper) ClipHeadlineWithRelated(ctx context.Context, h Headline) error {
 	return nc.ClipHeadline(ctx, h)
@@ -430,15 +482,11 @@ func splitIntoRichTextBlocks(text string) []notionapi.RichText {
 		return richTexts
 	}
 
-	// テキストをmaxChars文字ごとのチャンクに分割
-	for i := 0; i < len(text); i += maxChars {
-		end := i + maxChars
-		if end > len(text) {
-			end = len(text)
-		}
+	// テキストをmaxChars文字ごとのチャンクに分割（日本語が途中で切れないようrune単位）
+	for _, chunk := range chunkRunes(text, maxChars) {
 		richTexts = append(richTexts, notionapi.RichText{
 			Text: &notionapi.Text{
-				Content: text[i:end],
+				Content: chunk,
 			},
 		})
 	}
@@ -446,86 +494,142 @@ func splitIntoRichTextBlocks(text string) []notionapi.RichText {
 	return richTexts
 }
 
-// createContentBlocks は長いテキストをNotionの段落ブロックに分割する
-// Notionはブロックあたり2000文字の制限があるため、長文を分割する
-func createContentBlocks(content string) notionapi.Blocks {
-	const maxBlockSize  = 2000
-	const maxBlockCount = 100 // Notion APIの上限
+// notionMaxBlocks は1回の AppendChildren で追加できるブロック数の上限
+const notionMaxBlocks = 100
+
+// createPageBlocks はページ本文のブロックを作成する
+//
+// 冒頭に元記事へのブックマークとメタデータ（ソース・公開日・著者・トピック）のコールアウトを置き、
+// 続けて本文（createContentBlocks）を配置する。
+func createPageBlocks(h Headline) notionapi.Blocks {
 	blocks := notionapi.Blocks{}
+	if h.URL != "" {
+		blocks = append(blocks, notionapi.BookmarkBlock{
+			BasicBlock: notionapi.BasicBlock{Type: notionapi.BlockTypeBookmark, Object: notionapi.ObjectTypeBlock},
+			Bookmark:   notionapi.Bookmark{URL: h.URL},
+		})
+	}
+	blocks = append(blocks, metadataCallout(h))
+	if h.Excerpt != "" {
+		content := createContentBlocks(h.Excerpt)
+		if room := notionMaxBlocks - len(blocks); len(content) > room {
+			content = content[:room]
+		}
+		blocks = append(blocks, content...)
+	}
+	return blocks
+}
 
-	// まず段落（空行）で分割
-	paragraphs := []string{}
-	currentPara := ""
-	for _, line := range strings.Split(content, "\n") {
-		if strings.TrimSpace(line) == "" {
-			if currentPara != "" {
-				paragraphs = append(paragraphs, strings.TrimSpace(currentPara))
-				currentPara = ""
-			}
-		} else {
-			if currentPara != "" {
-				currentPara += "\n"
-			}
-			currentPara += line
+// metadataCallout は記事のメタデータを1行ずつ並べたコールアウトを作成する
+func metadataCallout(h Headline) notionapi.CalloutBlock {
+	lines := []string{"Source: " + h.Source}
+	if h.PublishedAt != "" {
+		if t, err := parsePublishedDate(h.PublishedAt); err == nil {
+			lines = append(lines, "Published: "+t.Format("2006-01-02"))
 		}
 	}
-	if currentPara != "" {
-		paragraphs = append(paragraphs, strings.TrimSpace(currentPara))
+	if len(h.Authors) > 0 {
+		lines = append(lines, "Authors: "+strings.Join(h.Authors, ", "))
+	}
+	if len(h.Topics) > 0 {
+		lines = append(lines, "Topics: "+strings.Join(h.Topics, ", "))
+	}
+
+	emoji := notionapi.Emoji("📰")
+	return notionapi.CalloutBlock{
+		BasicBlock: notionapi.BasicBlock{Type: notionapi.BlockTypeCallout, Object: notionapi.ObjectTypeBlock},
+		Callout: notionapi.Callout{
+			RichText: splitIntoRichTextBlocks(strings.Join(lines, "\n")),
+			Icon:     &notionapi.Icon{Type: "emoji", Emoji: &emoji},
+			Color:    "gray_background",
+		},
 	}
+}
 
-	// ブロックを作成（段落が最大サイズを超える場合は分割）
-	for _, para := range paragraphs {
-		if len(blocks) >= maxBlockCount {
-			break
+// createContentBlocks は本文をNotionのブロックに変換する
+//
+// 構造記号（structure.go）に従い、"## " は見出し、"- " は箇条書き、それ以外は段落にする。
+// PDF由来の本文（改ページ記号を含む）はページごとに "Page N" の見出しを付けて分割する。
+// Notionはブロックあたり2000文字の制限があるため、長い段落は分割する。
+func createContentBlocks(content string) notionapi.Blocks {
+	blocks := notionapi.Blocks{}
+
+	pages := splitPDFPages(content)
+	for i, page := range pages {
+		if len(pages) > 1 && page != "" {
+			blocks = append(blocks, headingBlock(notionapi.BlockTypeHeading3, fmt.Sprintf("Page %d", i+1)))
 		}
-		if len(para) <= maxBlockSize {
-			blocks = append(blocks, notionapi.ParagraphBlock{
-				BasicBlock: notionapi.BasicBlock{
-					Type:   notionapi.BlockTypeParagraph,
-					Object: notionapi.ObjectTypeBlock,
-				},
-				Paragraph: notionapi.Paragraph{
-					RichText: []notionapi.RichText{
-						{
-							Text: &notionapi.Text{
-								Content: para,
-							},
-						},
-					},
-				},
-			})
-		} else {
-			// 長い段落をチャンクに分割
-			for i := 0; i < len(para); i += maxBlockSize {
-				if len(blocks) >= maxBlockCount {
-					break
+		for _, para := range splitParagraphs(page) {
+			switch {
+			case strings.HasPrefix(para, excerptSubheadingPrefix):
+				blocks = append(blocks, headingBlock(notionapi.BlockTypeHeading3, strings.TrimPrefix(para, excerptSubheadingPrefix)))
+			case strings.HasPrefix(para, excerptHeadingPrefix):
+				blocks = append(blocks, headingBlock(notionapi.BlockTypeHeading2, strings.TrimPrefix(para, excerptHeadingPrefix)))
+			case strings.HasPrefix(para, excerptBulletPrefix):
+				blocks = append(blocks, bulletBlock(strings.TrimPrefix(para, excerptBulletPrefix)))
+			default:
+				// 長い段落をチャンクに分割（rune単位）
+				for _, chunk := range chunkRunes(para, 2000) {
+					blocks = append(blocks, paragraphBlock(chunk))
 				}
-				end := i + maxBlockSize
-				if end > len(para) {
-					end = len(para)
-				}
-				blocks = append(blocks, notionapi.ParagraphBlock{
-					BasicBlock: notionapi.BasicBlock{
-						Type:   notionapi.BlockTypeParagraph,
-						Object: notionapi.ObjectTypeBlock,
-					},
-					Paragraph: notionapi.Paragraph{
-						RichText: []notionapi.RichText{
-							{
-								Text: &notionapi.Text{
-									Content: para[i:end],
-								},
-							},
-						},
-					},
-				})
 			}
 		}
+		if len(blocks) >= notionMaxBlocks {
+			return blocks[:notionMaxBlocks]
+		}
 	}
-
 	return blocks
 }
 
+// splitParagraphs はテキストを空行で段落に分割する
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	current := ""
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if current != "" {
+				paragraphs = append(paragraphs, strings.TrimSpace(current))
+				current = ""
+			}
+			continue
+		}
+		if current != "" {
+			current += "\n"
+		}
+		current += line
+	}
+	if current != "" {
+		paragraphs = append(paragraphs, strings.TrimSpace(current))
+	}
+	return paragraphs
+}
+
+// paragraphBlock は段落ブロックを作成する
+func paragraphBlock(text string) notionapi.ParagraphBlock {
+	return notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{Type: notionapi.BlockTypeParagraph, Object: notionapi.ObjectTypeBlock},
+		Paragraph:  notionapi.Paragraph{RichText: splitIntoRichTextBlocks(text)},
+	}
+}
+
+// headingBlock は見出しブロック（heading_2 / heading_3）を作成する
+func headingBlock(blockType notionapi.BlockType, text string) notionapi.Block {
+	heading := notionapi.Heading{RichText: splitIntoRichTextBlocks(text)}
+	basic := notionapi.BasicBlock{Type: blockType, Object: notionapi.ObjectTypeBlock}
+	if blockType == notionapi.BlockTypeHeading2 {
+		return notionapi.Heading2Block{BasicBlock: basic, Heading2: heading}
+	}
+	return notionapi.Heading3Block{BasicBlock: basic, Heading3: heading}
+}
+
+// bulletBlock は箇条書きブロックを作成する
+func bulletBlock(text string) notionapi.BulletedListItemBlock {
+	return notionapi.BulletedListItemBlock{
+		BasicBlock:       notionapi.BasicBlock{Type: notionapi.BlockTypeBulletedListItem, Object: notionapi.ObjectTypeBlock},
+		BulletedListItem: notionapi.ListItem{RichText: splitIntoRichTextBlocks(text)},
+	}
+}
+
 // parsePublishedDate は各種フォーマットの公開日をパースする
 // WordPress APIがタイムゾーンなしの日付を返す場合があるため、複数フォーマットを試行する
 func parsePublishedDate(dateStr string) (time.Time, error) {
@@ -559,152 +663,96 @@ func parsePublishedDate(dateStr string) (time.Time, error) {
 }
 
 // FetchRecentHeadlines はNotionデータベースからヘッドラインを取得する
-// 過去daysBack日以内に作成されたヘッドラインを返す
+// 過去daysBack日以内に作成されたヘッドラインを作成日時の新しい順に返す
+//
+// 期間の絞り込みと並び替えはNotion API側で行う（notion_query.go）。
 func (nc *NotionClipper) FetchRecentHeadlines(ctx context.Context, daysBack int) ([]NotionHeadline, error) {
-	if nc.dbID == "" {
-		return nil, fmt.Errorf("database ID not set")
-	}
-
-	// 取得対象の基準日を算出
-	cutoffDate := time.Now().AddDate(0, 0, -daysBack)
-
-	// ページネーション付きでデータベースを検索（フィルタはコード側で適用）
-	var allHeadlines []NotionHeadline
-	var cursor *notionapi.Cursor
-
-	for {
-		query := &notionapi.DatabaseQueryRequest{
-			PageSize: 100,
-		}
-		if cursor != nil {
-			query.StartCursor = *cursor
-		}
-
-		resp, err := nc.client.Database.Query(ctx, nc.dbID, query)
-		if err != nil {
-			return nil, fmt.Errorf("failed to query database: %w", err)
-		}
-
-		// 結果を処理
-		for _, page := range resp.Results {
-			// 作成日でフィルタ
-			if !page.CreatedTime.After(cutoffDate) {
-				continue
-			}
-
-			// Titleを抽出
-			title := ""
-			if titleProp, ok := page.Properties["Title"].(*notionapi.TitleProperty); ok && len(titleProp.Title) > 0 {
-				title = titleProp.Title[0].PlainText
-			}
-
-			// URLを抽出
-			url := ""
-			if urlProp, ok := page.Properties["URL"].(*notionapi.URLProperty); ok {
-				url = string(urlProp.URL)
-			}
-
-			// Sourceを抽出
-			source := ""
-			if sourceProp, ok := page.Properties["Source"].(*notionapi.SelectProperty); ok && sourceProp.Select.Name != "" {
-				source = sourceProp.Select.Name
-			}
-
-			// Type（Academic/News）を抽出
-			articleType := ""
-			if typeProp, ok := page.Properties["Type"].(*notionapi.SelectProperty); ok && typeProp.Select.Name != "" {
-				articleType = typeProp.Select.Name
-			}
-
-			// Article Summary 300を抽出
-			shortHeadline := ""
-			if shortProp, ok := page.Properties["Article Summary 300"].(*notionapi.RichTextProperty); ok && len(shortProp.RichText) > 0 {
-				for _, rt := range shortProp.RichText {
-					shortHeadline += rt.PlainText
-				}
-			}
-
-			// Published Dateを抽出
-			publishedDate := ""
-			if dateProp, ok := page.Properties["Published Date"].(*notionapi.DateProperty); ok && dateProp.Date != nil && dateProp.Date.Start != nil {
-				publishedDate = time.Time(*dateProp.Date.Start).Format(time.RFC3339)
-			}
+	return nc.QueryHeadlines(ctx, RecentHeadlinesQuery(daysBack))
+}
 
-			// 作成日時を抽出
-			createdAt := page.CreatedTime.Format(time.RFC3339)
-
-			allHeadlines = append(allHeadlines, NotionHeadline{
-				Title:         title,
-				URL:           url,
-				Source:        source,
-				Type:          articleType,
-				ShortHeadline: shortHeadline,
-				PublishedDate: publishedDate,
-				CreatedAt:     createdAt,
-			})
-		}
+// notionHeadlineFromPage はNotionのページをNotionHeadlineに変換する
+//
+// QueryHeadlines とエクスポート（notion_export.go）で共通して使う。
+func notionHeadlineFromPage(page notionapi.Page) NotionHeadline {
+	props := page.Properties
 
-		// 次のページがあるか確認
-		if !resp.HasMore {
-			break
-		}
-		cursor = &resp.NextCursor
+	// Titleを抽出
+	title := ""
+	if titleProp, ok := props["Title"].(*notionapi.TitleProperty); ok && len(titleProp.Title) > 0 {
+		title = titleProp.Title[0].PlainText
 	}
 
-	return allHeadlines, nil
-}
+	// URLを抽出
+	url := ""
+	if urlProp, ok := props["URL"].(*notionapi.URLProperty); ok {
+		url = string(urlProp.URL)
+	}
 
-// =============================================================================
-// 環境変数ファイル操作
-// =============================================================================
+	// Sourceを抽出
+	source := ""
+	if sourceProp, ok := props["Source"].(*notionapi.SelectProperty); ok && sourceProp.Select.Name != "" {
+		source = sourceProp.Select.Name
+	}
 
-// appendToEnvFile は.envファイルにキーと値のペアを追加または更新する
-//
-// キーが既に存在する場合は値を更新、存在しない場合は末尾に追加する。
-// コメントアウトされたキー（#KEY=value）も検出して上書きする。
-//
-// 【使用場面】
-//
-//	新しいNotionデータベースを作成した際に、NOTION_DATABASE_IDを
-//	.envファイルに自動保存する
-//
-// 引数:
-//
-//	filename: .envファイルのパス
-//	key:      環境変数名（例: "NOTION_DATABASE_ID"）
-//	value:    設定する値
-func appendToEnvFile(filename, key, value string) error {
-	// 既存の.envファイルを読み込む（存在しない場合は空文字）
-	content := ""
-	data, err := os.ReadFile(filename)
-	if err == nil {
-		content = string(data)
+	// Type（Academic/News）を抽出
+	articleType := ""
+	if typeProp, ok := props["Type"].(*notionapi.SelectProperty); ok && typeProp.Select.Name != "" {
+		articleType = typeProp.Select.Name
 	}
 
-	// キーが既に存在するかチェック
-	lines := strings.Split(content, "\n")
-	keyExists := false
-	for i, line := range lines {
-		if strings.HasPrefix(line, key+"=") || strings.HasPrefix(line, "#"+key+"=") {
-			lines[i] = key + "=" + value
-			keyExists = true
-			break
+	// Article Summary 300を抽出
+	shortHeadline := ""
+	if shortProp, ok := props["Article Summary 300"].(*notionapi.RichTextProperty); ok && len(shortProp.RichText) > 0 {
+		for _, rt := range shortProp.RichText {
+			shortHeadline += rt.PlainText
 		}
 	}
 
-	// キーが存在しない場合は末尾に追加
-	if !keyExists {
-		if content != "" && !strings.HasSuffix(content, "\n") {
-			content += "\n"
-		}
-		lines = append(lines, key+"="+value)
+	// Languageを抽出（未設定の古いページはタイトルと要約から判定）
+	language := ""
+	if langProp, ok := props["Language"].(*notionapi.SelectProperty); ok && langProp.Select.Name != "" {
+		language = langProp.Select.Name
+	} else {
+		language = DetectLanguage(title + " " + shortHeadline)
 	}
 
-	// ファイルに書き戻す
-	newContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(filename, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write .env file: %w", err)
+	// Extracted Summaryを抽出
+	extractedSummary := ""
+	if extProp, ok := props["Extracted Summary"].(*notionapi.RichTextProperty); ok {
+		for _, rt := range extProp.RichText {
+			extractedSummary += rt.PlainText
+		}
 	}
 
-	return nil
+	// Published Dateを抽出
+	publishedDate := ""
+	if dateProp, ok := props["Published Date"].(*notionapi.DateProperty); ok && dateProp.Date != nil && dateProp.Date.Start != nil {
+		publishedDate = time.Time(*dateProp.Date.Start).Format(time.RFC3339)
+	}
+
+	// 作成日時を抽出
+	createdAt := page.CreatedTime.Format(time.RFC3339)
+
+	// 編集ステータスを抽出（editorial.go）
+	excluded, priority, editorNote := editorialFromProperties(props)
+
+	return NotionHeadline{
+		ID:               string(page.ID),
+		Title:            title,
+		URL:              url,
+		Source:           source,
+		Type:             articleType,
+		ShortHeadline:    shortHeadline,
+		ExtractedSummary: extractedSummary,
+		Language:         language,
+		PublishedDate:    publishedDate,
+		CreatedAt:        createdAt,
+		Entities:         entitiesFromProperties(props),
+		Prices:           pricesFromProperties(props),
+		Authors:          authorsFromProperties(props),
+		Topics:           multiSelectValues(props, "Topics"),
+		Excluded:         excluded,
+		Priority:         priority,
+		EditorNote:       editorNote,
+	}
 }
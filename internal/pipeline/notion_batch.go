@@ -0,0 +1,335 @@
+// =============================================================================
+// notion_batch.go - Notionへの並列クリップとレート制限
+// =============================================================================
+//
+// このファイルは複数の見出しを並列でNotionにクリップする処理と、
+// Notion APIのレート制限（平均3リクエスト/秒）を守るHTTPトランスポートを提供します。
+//
+// 【背景】
+//   - 1記事あたり Page.Create と Block.AppendChildren の2リクエストが必要
+//   - 200件以上を1件ずつ順番に処理するとLambdaの実行時間の大半を占めていた
+//   - notionapi ライブラリは429の再試行時にリクエストボディを再送しないため、
+//     429はトランスポート層で Retry-After に従って再送する。再送しきれなかった場合も
+//     429のレスポンスは notionapi に返さず、notionRateLimitedError にする
+//   - タイムアウトはクライアント全体（http.Client.Timeout）ではなく1回の送信ごとに設ける。
+//     リミッタの待ちや Retry-After の待機まで含めると、並列時に作成リクエストが送信前に
+//     タイムアウトし、作成済みか判断できない通信エラーとして再送されなくなるため
+//
+// 【構成】
+//
+//	ClipHeadlines（ワーカープール）
+//	  └→ ClipHeadline × N 並列
+//	       └→ notionTransport（全ワーカー共有のトークンバケット + 429処理）
+//
+// 【環境変数】
+//
+//	NOTION_CLIP_WORKERS - 並列数（デフォルト: 3）
+//	NOTION_RATE_LIMIT   - 平均リクエスト数/秒（デフォルト: 3）
+//	NOTION_API_BASE_URL - リクエスト先（デフォルト: https://api.notion.com、
+//	                      結合テストでは internal/notionfake の代替サーバーを指定）
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultNotionClipWorkers = 3
+	defaultNotionRateLimit   = 3.0 // Notion APIの平均許容リクエスト数/秒
+	notionRateBurst          = 3   // 短時間に連続で送れるリクエスト数
+	notionMax429Retries      = 5
+	notionRequestTimeout     = 60 * time.Second // 1回の送信（リミッタ・Retry-Afterの待ちを含まない）の上限
+)
+
+// =============================================================================
+// レート制限
+// =============================================================================
+
+// notionRateLimiter は全ワーカーで共有するトークンバケット
+//
+// 429を受けた場合は Pause で全ワーカーの送信を Retry-After の間止める。
+type notionRateLimiter struct {
+	mu          sync.Mutex
+	interval    time.Duration // トークン1個の補充間隔（1 / rate）
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// newNotionRateLimiter は rate リクエスト/秒のリミッタを作成する
+func newNotionRateLimiter(rate float64) *notionRateLimiter {
+	if rate <= 0 {
+		rate = defaultNotionRateLimit
+	}
+	return &notionRateLimiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		tokens:   notionRateBurst,
+		last:     time.Now(),
+	}
+}
+
+// Wait はトークンが得られるまで待機する
+func (l *notionRateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if now.Before(l.pausedUntil) {
+			wait = l.pausedUntil.Sub(now)
+		} else {
+			l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+			if l.tokens > notionRateBurst {
+				l.tokens = notionRateBurst
+			}
+			l.last = now
+			if l.tokens >= 1 {
+				l.tokens--
+				l.mu.Unlock()
+				return nil
+			}
+			wait = time.Duration((1 - l.tokens) * float64(l.interval))
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Pause は d の間、全ての送信を停止する（429 Retry-After）
+func (l *notionRateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+}
+
+// notionTransport はレート制限と429の再送を行うHTTPトランスポート
+type notionTransport struct {
+	base    http.RoundTripper
+	limiter *notionRateLimiter
+
+	requests    atomic.Int64 // 送信したリクエスト数（再送を含む）
+	rateLimited atomic.Int64 // 429を受けた回数
+}
+
+// notionRateLimitedError は429の再送を繰り返しても制限が解除されなかったことを表す
+//
+// Notion側で処理されていないことが確実なため、通信エラー（isNotionNetworkError）とは区別する。
+type notionRateLimitedError struct {
+	Attempts   int
+	RetryAfter time.Duration // 最後の429の Retry-After
+}
+
+func (e *notionRateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited (429) after %d attempt(s), last Retry-After %v", e.Attempts, e.RetryAfter)
+}
+
+// cancelOnCloseBody はボディを閉じたときに送信ごとのタイムアウトを解放する
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// RoundTrip はリミッタを待ってからリクエストを送信し、429の場合は Retry-After 後に再送する
+//
+// 送信ごとに notionRequestTimeout のタイムアウトを設ける（ボディを読み終えるまで有効）。
+func (t *notionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		// 再送時はボディを作り直す
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		t.requests.Add(1)
+		attemptCtx, cancel := context.WithTimeout(req.Context(), notionRequestTimeout)
+		resp, err := t.base.RoundTrip(req.WithContext(attemptCtx))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		t.rateLimited.Add(1)
+		wait := parseRetryAfter(resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+		cancel()
+		if attempt >= notionMax429Retries || (req.Body != nil && req.GetBody == nil) {
+			// 再送できない場合も429を notionapi に返さない（notionapi は読み終えたボディで再試行するため）
+			return nil, &notionRateLimitedError{Attempts: attempt + 1, RetryAfter: wait}
+		}
+		fmt.Fprintf(os.Stderr, "⏳ Notion API rate limited (429), pausing %v\n", wait)
+		t.limiter.Pause(wait)
+	}
+}
+
+// parseRetryAfter は Retry-After ヘッダー（秒数またはHTTP日付）を解釈する
+//
+// ヘッダーがない・解釈できない場合は1秒を返す。
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return time.Second
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return time.Second
+}
+
+// notionBaseURLTransport はリクエスト先を NOTION_API_BASE_URL に差し替えるトランスポート
+//
+// notionapi ライブラリは api.notion.com が固定のため、トランスポート層で書き換える。
+type notionBaseURLTransport struct {
+	base   http.RoundTripper
+	target *url.URL
+}
+
+// RoundTrip はスキーム・ホスト（とパスの接頭辞）を差し替えて送信する
+func (t *notionBaseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.URL.Path = strings.TrimSuffix(t.target.Path, "/") + req.URL.Path
+	req.Host = t.target.Host
+	return t.base.RoundTrip(req)
+}
+
+// newNotionHTTPClient はレート制限付きのHTTPクライアントを作成する
+func newNotionHTTPClient() (*http.Client, *notionTransport) {
+	rate := defaultNotionRateLimit
+	if v, err := strconv.ParseFloat(os.Getenv("NOTION_RATE_LIMIT"), 64); err == nil && v > 0 {
+		rate = v
+	}
+	var base http.RoundTripper = http.DefaultTransport
+	if v := os.Getenv("NOTION_API_BASE_URL"); v != "" {
+		if u, err := url.Parse(v); err == nil && u.Host != "" {
+			base = &notionBaseURLTransport{base: base, target: u}
+		} else {
+			warnf("ignoring invalid NOTION_API_BASE_URL %q", v)
+		}
+	}
+	transport := &notionTransport{
+		base:    base,
+		limiter: newNotionRateLimiter(rate),
+	}
+	// タイムアウトは送信ごと（notionTransport.RoundTrip）。全体の上限は呼び出し側の ctx で決める
+	return &http.Client{Transport: transport}, transport
+}
+
+// =============================================================================
+// 並列クリップ
+// =============================================================================
+
+// notionClipWorkers は NOTION_CLIP_WORKERS から並列数を返す
+func notionClipWorkers() int {
+	if v, err := strconv.Atoi(os.Getenv("NOTION_CLIP_WORKERS")); err == nil && v > 0 {
+		return v
+	}
+	return defaultNotionClipWorkers
+}
+
+// ClipHeadlines は見出しを並列でNotionにクリップする
+//
+// workers が0以下の場合は NOTION_CLIP_WORKERS（デフォルト3）を使う。
+// リクエスト数は全ワーカー共有のリミッタで平均 NOTION_RATE_LIMIT/秒 に抑えられる。
+func (nc *NotionClipper) ClipHeadlines(ctx context.Context, headlines []Headline, workers int) *NotionClipResult {
+	if workers <= 0 {
+		workers = notionClipWorkers()
+	}
+	if workers > len(headlines) {
+		workers = len(headlines)
+	}
+
+	start := time.Now()
+	requestsBefore, rateLimitedBefore := nc.transportStats()
+
+	// 並列処理の前にスキーマを一度だけ確認する
+	nc.ensureSchema(ctx)
+
+	result := &NotionClipResult{}
+	var mu sync.Mutex
+	jobs := make(chan Headline)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range jobs {
+				err := nc.ClipHeadline(ctx, h)
+
+				mu.Lock()
+				if err != nil {
+					warnf("failed to clip headline '%s': %v", h.Title, err)
+					result.addFailure(h.Title, err)
+				} else {
+					result.Clipped++
+					fmt.Fprintf(os.Stderr, "  ✅ Clipped: %s\n", truncateString(h.Title, 50))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, h := range headlines {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- h
+	}
+	close(jobs)
+	wg.Wait()
+
+	requestsAfter, rateLimitedAfter := nc.transportStats()
+	result.Duration = time.Since(start)
+	result.Requests = requestsAfter - requestsBefore
+	result.RateLimited = rateLimitedAfter - rateLimitedBefore
+	return result
+}
+
+// transportStats はトランスポートのリクエスト数と429回数を返す
+func (nc *NotionClipper) transportStats() (requests, rateLimited int64) {
+	if nc.transport == nil {
+		return 0, 0
+	}
+	return nc.transport.requests.Load(), nc.transport.rateLimited.Load()
+}
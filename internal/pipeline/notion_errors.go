@@ -0,0 +1,284 @@
+// =============================================================================
+// notion_errors.go - Notion APIエラーの分類とリトライ方針
+// =============================================================================
+//
+// このファイルはNotion APIのエラーを種類ごとに分類し、種類別のリトライ方針を提供します。
+//
+// 【背景】
+//   以前は err.Error() に "invalid character '<'"（HTMLエラーページ）が含まれる場合のみ
+//   リトライしていたため、JSONで返る 429 rate_limited・409 conflict_error・502/503 は
+//   即座に失敗し、文字数超過などの入力エラーと区別できなかった。
+//
+// 【分類】
+//
+//	┌─────────────┬──────────────────────────────────────────┬──────────────┐
+//	│ 種類        │ 該当するエラー                           │ リトライ     │
+//	├─────────────┼──────────────────────────────────────────┼──────────────┤
+//	│ rate_limit  │ 429 rate_limited、RateLimitedError、     │ しない（※1） │
+//	│             │ notionRateLimitedError（notion_batch.go）│              │
+//	│ transient   │ 409、5xx、HTMLレスポンス、通信エラー     │ 3回（2s〜）  │
+//	│ validation  │ 400 validation_error / invalid_json 等   │ しない       │
+//	│ auth        │ 401、403、404（DB未共有）                │ しない       │
+//	│ unknown     │ 上記以外                                 │ しない       │
+//	└─────────────┴──────────────────────────────────────────┴──────────────┘
+//
+//	※1 429はトランスポート層（notion_batch.go）が Retry-After に従って再送済みのため、
+//	    ここでさらに待つと待機時間が積み重なる。
+//
+// 【リトライの制限】
+//   - 待機は ctx のキャンセルで中断し、待機時間の合計は notionMaxRetryWait まで
+//   - ページ作成・ブロック追加（notionRetryCreate）は、タイムアウトや途中切断のように
+//     Notion側で処理されたか判断できない通信エラーでは再送しない（ページ・ブロックの重複を避ける）
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// Notion APIエラーの種類
+const (
+	NotionErrRateLimit  = "rate_limit"
+	NotionErrTransient  = "transient"
+	NotionErrValidation = "validation"
+	NotionErrAuth       = "auth"
+	NotionErrUnknown    = "unknown"
+)
+
+// NotionError は分類済みのNotion APIエラー
+type NotionError struct {
+	Category  string // NotionErrRateLimit など
+	Operation string // "Page.Create" など
+	Status    int    // HTTPステータス（不明な場合は0）
+	Code      string // Notionのエラーコード（"validation_error" など）
+	Attempts  int    // 試行回数
+	Err       error  // 元のエラー
+}
+
+func (e *NotionError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Operation, e.Category, e.Err)
+}
+
+func (e *NotionError) Unwrap() error {
+	return e.Err
+}
+
+// notionRetryPolicy は種類ごとのリトライ方針
+type notionRetryPolicy struct {
+	MaxRetries int
+	BaseWait   time.Duration // 1回目の待機時間（以降は倍々）
+}
+
+// notionRetryPolicies は種類別のリトライ方針（記載のない種類はリトライしない）
+//
+// 429（NotionErrRateLimit）はトランスポート層（notion_batch.go）で再送済みのため記載しない。
+var notionRetryPolicies = map[string]notionRetryPolicy{
+	NotionErrTransient: {MaxRetries: 3, BaseWait: 2 * time.Second},
+}
+
+// notionMaxRetryWait は1回のAPI呼び出しでリトライのために待つ時間の合計の上限
+var notionMaxRetryWait = 30 * time.Second
+
+// classifyNotionError はエラーを種類に分類し、HTTPステータスとエラーコードを返す
+func classifyNotionError(err error) (category string, status int, code string) {
+	if err == nil {
+		return "", 0, ""
+	}
+
+	var already *NotionError
+	if errors.As(err, &already) {
+		return already.Category, already.Status, already.Code
+	}
+
+	var rateErr *notionapi.RateLimitedError
+	var transportRateErr *notionRateLimitedError
+	if errors.As(err, &rateErr) || errors.As(err, &transportRateErr) {
+		return NotionErrRateLimit, http.StatusTooManyRequests, "rate_limited"
+	}
+
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) {
+		status, code = apiErr.Status, string(apiErr.Code)
+		switch {
+		case status == http.StatusTooManyRequests || code == "rate_limited":
+			return NotionErrRateLimit, status, code
+		case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusNotFound ||
+			code == "unauthorized" || code == "restricted_resource" || code == "object_not_found":
+			return NotionErrAuth, status, code
+		case status == http.StatusConflict || status >= 500 || code == "conflict_error" ||
+			code == "service_unavailable" || code == "internal_server_error" || code == "gateway_timeout" ||
+			code == "database_connection_unavailable":
+			return NotionErrTransient, status, code
+		case status == http.StatusBadRequest || code == "validation_error" || code == "invalid_json" ||
+			code == "invalid_request" || code == "invalid_request_url" || code == "missing_version":
+			return NotionErrValidation, status, code
+		}
+		return NotionErrUnknown, status, code
+	}
+
+	// CDN等がHTMLのエラーページを返した場合（JSONとして解析できない）
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || strings.Contains(err.Error(), "invalid character '<'") {
+		return NotionErrTransient, 0, ""
+	}
+
+	// 呼び出し側のキャンセルはリトライしない
+	if errors.Is(err, context.Canceled) {
+		return NotionErrUnknown, 0, ""
+	}
+
+	// 通信エラー（タイムアウト、接続リセット、途中切断）
+	if isNotionNetworkError(err) {
+		return NotionErrTransient, 0, ""
+	}
+
+	return NotionErrUnknown, 0, ""
+}
+
+// isNotionNetworkError はレスポンスを受け取れなかった通信エラーかを返す
+//
+// タイムアウトや途中切断ではNotion側でリクエストが処理されたかどうかが分からない。
+// トランスポートが返す429（notionRateLimitedError）は *url.Error に包まれるが、処理されていないため含めない。
+func isNotionNetworkError(err error) bool {
+	var rateErr *notionRateLimitedError
+	if errors.As(err, &rateErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "connection reset")
+}
+
+// notionErrorCategory はエラーの種類を返す（分類できない場合は NotionErrUnknown）
+func notionErrorCategory(err error) string {
+	category, _, _ := classifyNotionError(err)
+	return category
+}
+
+// notionRetry は冪等なNotion API呼び出し（取得・更新）を種類別のリトライ方針で実行する
+//
+// 最終的に失敗した場合は *NotionError を返す。
+func notionRetry(ctx context.Context, operation string, fn func() error) error {
+	return notionRetryWith(ctx, operation, true, fn)
+}
+
+// notionRetryCreate はページ作成・ブロック追加のように再送で重複が生じる呼び出しを実行する
+//
+// 通信エラー（isNotionNetworkError）は作成済みの可能性があるため再送しない。
+func notionRetryCreate(ctx context.Context, operation string, fn func() error) error {
+	return notionRetryWith(ctx, operation, false, fn)
+}
+
+// notionRetryWith はリトライの本体（retryNetwork がfalseの場合は通信エラーを再送しない）
+func notionRetryWith(ctx context.Context, operation string, retryNetwork bool, fn func() error) error {
+	attempts := 0
+	var waited time.Duration
+	for {
+		attempts++
+		err := fn()
+		if err == nil {
+			if attempts > 1 {
+				fmt.Fprintf(os.Stderr, "✅ Notion API %s succeeded after %d retries\n", operation, attempts-1)
+			}
+			return nil
+		}
+
+		category, status, code := classifyNotionError(err)
+		failed := &NotionError{
+			Category:  category,
+			Operation: operation,
+			Status:    status,
+			Code:      code,
+			Attempts:  attempts,
+			Err:       err,
+		}
+		policy, retryable := notionRetryPolicies[category]
+		if !retryable || attempts > policy.MaxRetries || (!retryNetwork && isNotionNetworkError(err)) {
+			return failed
+		}
+
+		wait := policy.BaseWait << uint(attempts-1)
+		if waited+wait > notionMaxRetryWait {
+			return failed
+		}
+		waited += wait
+		fmt.Fprintf(os.Stderr, "⏳ Notion API retry %d/%d for %s (%s, waiting %v)...\n",
+			attempts, policy.MaxRetries, operation, category, wait)
+		select {
+		case <-ctx.Done():
+			return failed
+		case <-time.After(wait):
+		}
+	}
+}
+
+// =============================================================================
+// 失敗レポート（エラー通知メール用）
+// =============================================================================
+
+// notionErrorHints は種類ごとの対処方法
+var notionErrorHints = map[string]string{
+	NotionErrRateLimit:  "Notion APIのレート制限です。NOTION_CLIP_WORKERS / NOTION_RATE_LIMIT を下げてください。",
+	NotionErrTransient:  "Notion側の一時的な障害です。次回の実行で再度クリップされるため、通常は対応不要です。",
+	NotionErrValidation: "送信した値が不正です（文字数上限・プロパティの型不一致など）。-notionMigrate -notionDryRun でスキーマ差分を確認してください。",
+	NotionErrAuth:       "認証エラーです。NOTION_TOKEN が有効か、データベースがインテグレーションに共有されているか確認してください。",
+	NotionErrUnknown:    "分類できないエラーです。ログを確認してください。",
+}
+
+// notionErrorCategoryOrder はレポートに表示する順序（対応が必要なものを先に）
+var notionErrorCategoryOrder = []string{
+	NotionErrAuth, NotionErrValidation, NotionErrRateLimit, NotionErrTransient, NotionErrUnknown,
+}
+
+// NotionClipFailure はクリップに失敗した1記事
+type NotionClipFailure struct {
+	Title    string
+	Category string
+	Message  string
+}
+
+// addFailure は失敗を記録する（Errors には "[Notion/種類] 'タイトル': エラー" 形式で追加）
+func (r *NotionClipResult) addFailure(title string, err error) {
+	category := notionErrorCategory(err)
+	r.Failed++
+	r.Failures = append(r.Failures, NotionClipFailure{Title: title, Category: category, Message: err.Error()})
+	r.Errors = append(r.Errors, fmt.Sprintf("[Notion/%s] '%s': %v", category, truncateString(title, 50), err))
+}
+
+// ErrorReport は失敗を種類別にまとめ、対処方法を付けた行を返す
+func (r *NotionClipResult) ErrorReport() []string {
+	if r == nil || len(r.Failures) == 0 {
+		return nil
+	}
+	byCategory := map[string][]NotionClipFailure{}
+	for _, f := range r.Failures {
+		byCategory[f.Category] = append(byCategory[f.Category], f)
+	}
+
+	var lines []string
+	for _, category := range notionErrorCategoryOrder {
+		failures := byCategory[category]
+		if len(failures) == 0 {
+			continue
+		}
+		sort.SliceStable(failures, func(i, j int) bool { return failures[i].Title < failures[j].Title })
+		lines = append(lines, fmt.Sprintf("[Notion/%s] %d件: %s", category, len(failures), notionErrorHints[category]))
+		for _, f := range failures {
+			lines = append(lines, fmt.Sprintf("    '%s': %s", truncateString(f.Title, 50), truncateString(f.Message, 200)))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,539 @@
+// =============================================================================
+// notion_export.go - Notionデータベースのローカルエクスポート（JSONL / CSV / Markdown）
+// =============================================================================
+//
+// このファイルはNotionデータベースのページを、プロパティとページ本文（ブロック）付きで
+// ローカルファイルに書き出します。
+//
+// 【用途】
+//   - バックアップ
+//   - オフラインでの分析（pandas / スプレッドシート）
+//   - Notion以外への移行
+//
+// 【出力形式】
+//
+//	jsonl    - 1行1ページ（プロパティは型に応じた値、本文はブロック配列とMarkdown）
+//	csv      - 1行1ページ（全ページのプロパティ名の和集合を列にする、複数値は "; " 区切り）
+//	markdown - 1ページ1ファイル（先頭にプロパティ、続けて本文）を -out のフォルダに出力
+//
+// 【期間指定】
+//   -exportFrom / -exportTo はページの作成日時（created_time）で絞り込む。
+//   絞り込みはNotion API側で行う（notion_query.go の HeadlineQuery）。
+//
+// 【本文の取得】
+//   本文の取得は1ページにつき1リクエスト以上かかるため、-exportBody=false で省略できる。
+//   リクエストは notion_batch.go のリミッタを通るため、大量のページでもレート制限を超えない。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// エクスポート形式
+const (
+	ExportFormatJSONL    = "jsonl"
+	ExportFormatCSV      = "csv"
+	ExportFormatMarkdown = "markdown"
+)
+
+// notionExportMaxDepth は子ブロックを辿る最大の深さ（入れ子のリスト等）
+const notionExportMaxDepth = 3
+
+// NotionExportBlock はページ本文の1ブロック
+type NotionExportBlock struct {
+	Type     string              `json:"type"`
+	Text     string              `json:"text,omitempty"`
+	URL      string              `json:"url,omitempty"`      // bookmark / embed 等
+	Checked  *bool               `json:"checked,omitempty"`  // to_do
+	Language string              `json:"language,omitempty"` // code
+	Children []NotionExportBlock `json:"children,omitempty"`
+}
+
+// NotionExportPage はエクスポートする1ページ
+type NotionExportPage struct {
+	ID             string              `json:"id"`
+	URL            string              `json:"url"`
+	CreatedTime    string              `json:"created_time"`
+	LastEditedTime string              `json:"last_edited_time"`
+	Properties     map[string]any      `json:"properties"`
+	Blocks         []NotionExportBlock `json:"blocks,omitempty"`
+	Markdown       string              `json:"markdown,omitempty"`
+
+	headline NotionHeadline // ファイル名の決定に使う
+}
+
+// =============================================================================
+// 取得
+// =============================================================================
+
+// ExportPages は作成日時が [from, to] のページを取得する（ゼロ値は無制限）
+//
+// withBody が true の場合はページ本文のブロックも取得する。
+func (nc *NotionClipper) ExportPages(ctx context.Context, from, to time.Time, withBody bool) ([]NotionExportPage, error) {
+	// to は日付として扱い、その日の終わりまでを含める
+	q := HeadlineQuery{CreatedOnOrAfter: from, Sort: HeadlineSortCreatedAsc}
+	if !to.IsZero() {
+		q.CreatedBefore = to.AddDate(0, 0, 1)
+	}
+
+	var pages []NotionExportPage
+	err := nc.queryPages(ctx, q, func(page notionapi.Page) error {
+		p := NotionExportPage{
+			ID:             string(page.ID),
+			URL:            page.URL,
+			CreatedTime:    page.CreatedTime.Format(time.RFC3339),
+			LastEditedTime: page.LastEditedTime.Format(time.RFC3339),
+			Properties:     exportProperties(page.Properties),
+			headline:       notionHeadlineFromPage(page),
+		}
+		if withBody {
+			blocks, err := nc.exportBlocks(ctx, notionapi.BlockID(page.ID), 0)
+			if err != nil {
+				return fmt.Errorf("failed to fetch blocks of '%s': %w", truncateString(p.headline.Title, 50), err)
+			}
+			p.Blocks = blocks
+			p.Markdown = blocksToMarkdown(blocks)
+		}
+		pages = append(pages, p)
+		if len(pages)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "  exported %d pages...\n", len(pages))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// exportBlocks はブロックの子を取得する（深さ notionExportMaxDepth まで）
+func (nc *NotionClipper) exportBlocks(ctx context.Context, id notionapi.BlockID, depth int) ([]NotionExportBlock, error) {
+	var blocks []NotionExportBlock
+	pagination := &notionapi.Pagination{PageSize: 100}
+	for {
+		var resp *notionapi.GetChildrenResponse
+		err := notionRetry(ctx, "Block.GetChildren", func() error {
+			var gerr error
+			resp, gerr = nc.client.Block.GetChildren(ctx, id, pagination)
+			return gerr
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range resp.Results {
+			eb := exportBlock(b)
+			if b.GetHasChildren() && depth+1 < notionExportMaxDepth {
+				children, err := nc.exportBlocks(ctx, b.GetID(), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				eb.Children = children
+			}
+			blocks = append(blocks, eb)
+		}
+
+		if !resp.HasMore {
+			break
+		}
+		pagination.StartCursor = notionapi.Cursor(resp.NextCursor)
+	}
+	return blocks, nil
+}
+
+// exportBlock はNotionのブロックをテキスト中心の表現に変換する
+//
+// 記事ページで使うブロック（段落・見出し・リスト・引用・コールアウト・ブックマーク・コード）
+// 以外は種類のみを残す。
+func exportBlock(b notionapi.Block) NotionExportBlock {
+	eb := NotionExportBlock{Type: string(b.GetType())}
+	switch v := b.(type) {
+	case *notionapi.ParagraphBlock:
+		eb.Text = plainText(v.Paragraph.RichText)
+	case *notionapi.Heading1Block:
+		eb.Text = plainText(v.Heading1.RichText)
+	case *notionapi.Heading2Block:
+		eb.Text = plainText(v.Heading2.RichText)
+	case *notionapi.Heading3Block:
+		eb.Text = plainText(v.Heading3.RichText)
+	case *notionapi.BulletedListItemBlock:
+		eb.Text = plainText(v.BulletedListItem.RichText)
+	case *notionapi.NumberedListItemBlock:
+		eb.Text = plainText(v.NumberedListItem.RichText)
+	case *notionapi.ToDoBlock:
+		eb.Text = plainText(v.ToDo.RichText)
+		checked := v.ToDo.Checked
+		eb.Checked = &checked
+	case *notionapi.QuoteBlock:
+		eb.Text = plainText(v.Quote.RichText)
+	case *notionapi.CalloutBlock:
+		eb.Text = plainText(v.Callout.RichText)
+	case *notionapi.ToggleBlock:
+		eb.Text = plainText(v.Toggle.RichText)
+	case *notionapi.CodeBlock:
+		eb.Text = plainText(v.Code.RichText)
+		eb.Language = v.Code.Language
+	case *notionapi.BookmarkBlock:
+		eb.URL = v.Bookmark.URL
+		eb.Text = plainText(v.Bookmark.Caption)
+	case *notionapi.EmbedBlock:
+		eb.URL = v.Embed.URL
+	}
+	return eb
+}
+
+// plainText はリッチテキストを連結したプレーンテキストを返す
+func plainText(rts []notionapi.RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		sb.WriteString(rt.PlainText)
+	}
+	return sb.String()
+}
+
+// exportProperties はプロパティを型に応じたJSON値に変換する
+//
+// title/rich_text/select 等は文字列、multi_select は文字列の配列、number は数値、
+// checkbox は真偽値、date は開始日時（終了日時がある場合は "開始/終了"）。
+func exportProperties(props notionapi.Properties) map[string]any {
+	out := make(map[string]any, len(props))
+	for name, prop := range props {
+		switch p := prop.(type) {
+		case *notionapi.TitleProperty:
+			out[name] = plainText(p.Title)
+		case *notionapi.RichTextProperty:
+			out[name] = plainText(p.RichText)
+		case *notionapi.URLProperty:
+			out[name] = p.URL
+		case *notionapi.EmailProperty:
+			out[name] = p.Email
+		case *notionapi.SelectProperty:
+			out[name] = p.Select.Name
+		case *notionapi.StatusProperty:
+			out[name] = p.Status.Name
+		case *notionapi.MultiSelectProperty:
+			values := make([]string, 0, len(p.MultiSelect))
+			for _, o := range p.MultiSelect {
+				values = append(values, o.Name)
+			}
+			out[name] = values
+		case *notionapi.NumberProperty:
+			out[name] = p.Number
+		case *notionapi.CheckboxProperty:
+			out[name] = p.Checkbox
+		case *notionapi.DateProperty:
+			if p.Date == nil || p.Date.Start == nil {
+				out[name] = ""
+				continue
+			}
+			v := p.Date.Start.String()
+			if p.Date.End != nil {
+				v += "/" + p.Date.End.String()
+			}
+			out[name] = v
+		case *notionapi.CreatedTimeProperty:
+			out[name] = p.CreatedTime.Format(time.RFC3339)
+		case *notionapi.LastEditedTimeProperty:
+			out[name] = p.LastEditedTime.Format(time.RFC3339)
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// Markdown変換
+// =============================================================================
+
+// blocksToMarkdown はブロックをMarkdownに変換する
+func blocksToMarkdown(blocks []NotionExportBlock) string {
+	var sb strings.Builder
+	writeMarkdownBlocks(&sb, blocks, "")
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func writeMarkdownBlocks(sb *strings.Builder, blocks []NotionExportBlock, indent string) {
+	number := 0
+	prevList := false
+	for _, b := range blocks {
+		if b.Type == "numbered_list_item" {
+			number++
+		} else {
+			number = 0
+		}
+		// リストの後は空行を入れて段落と区切る
+		isList := isMarkdownListBlock(b.Type)
+		if prevList && !isList && indent == "" {
+			sb.WriteString("\n")
+		}
+		prevList = isList
+
+		childIndent := indent
+		switch b.Type {
+		case "heading_1":
+			fmt.Fprintf(sb, "%s# %s\n\n", indent, b.Text)
+		case "heading_2":
+			fmt.Fprintf(sb, "%s## %s\n\n", indent, b.Text)
+		case "heading_3":
+			fmt.Fprintf(sb, "%s### %s\n\n", indent, b.Text)
+		case "bulleted_list_item", "toggle":
+			fmt.Fprintf(sb, "%s- %s\n", indent, b.Text)
+			childIndent = indent + "  "
+		case "numbered_list_item":
+			fmt.Fprintf(sb, "%s%d. %s\n", indent, number, b.Text)
+			childIndent = indent + "   "
+		case "to_do":
+			mark := " "
+			if b.Checked != nil && *b.Checked {
+				mark = "x"
+			}
+			fmt.Fprintf(sb, "%s- [%s] %s\n", indent, mark, b.Text)
+			childIndent = indent + "  "
+		case "quote", "callout":
+			for _, line := range strings.Split(b.Text, "\n") {
+				fmt.Fprintf(sb, "%s> %s\n", indent, line)
+			}
+			sb.WriteString("\n")
+		case "code":
+			fmt.Fprintf(sb, "%s```%s\n%s\n%s```\n\n", indent, b.Language, b.Text, indent)
+		case "bookmark", "embed":
+			label := b.Text
+			if label == "" {
+				label = b.URL
+			}
+			fmt.Fprintf(sb, "%s[%s](%s)\n\n", indent, label, b.URL)
+		case "divider":
+			fmt.Fprintf(sb, "%s---\n\n", indent)
+		case "paragraph":
+			if b.Text != "" {
+				fmt.Fprintf(sb, "%s%s\n\n", indent, b.Text)
+			}
+		default:
+			if b.Text != "" {
+				fmt.Fprintf(sb, "%s%s\n\n", indent, b.Text)
+			}
+		}
+
+		if len(b.Children) > 0 {
+			writeMarkdownBlocks(sb, b.Children, childIndent)
+		}
+	}
+}
+
+// isMarkdownListBlock はMarkdownでリスト項目として書き出すブロックかを返す
+func isMarkdownListBlock(blockType string) bool {
+	switch blockType {
+	case "bulleted_list_item", "numbered_list_item", "to_do", "toggle":
+		return true
+	}
+	return false
+}
+
+// =============================================================================
+// 書き出し
+// =============================================================================
+
+// WriteNotionExport は jsonl / csv 形式でページを書き出す
+func WriteNotionExport(w io.Writer, pages []NotionExportPage, format string) error {
+	switch format {
+	case ExportFormatJSONL, "":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, p := range pages {
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ExportFormatCSV:
+		return writeNotionExportCSV(w, pages)
+	}
+	return fmt.Errorf("unknown export format: %s (use jsonl, csv or markdown)", format)
+}
+
+// writeNotionExportCSV はプロパティ名の和集合を列としてCSVを書き出す
+func writeNotionExportCSV(w io.Writer, pages []NotionExportPage) error {
+	names := map[string]bool{}
+	for _, p := range pages {
+		for name := range p.Properties {
+			names[name] = true
+		}
+	}
+	columns := make([]string, 0, len(names))
+	for name := range names {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+
+	cw := csv.NewWriter(w)
+	header := append([]string{"page_id", "page_url", "created_time", "last_edited_time"}, columns...)
+	_ = cw.Write(append(header, "body"))
+	for _, p := range pages {
+		row := []string{p.ID, p.URL, p.CreatedTime, p.LastEditedTime}
+		for _, name := range columns {
+			row = append(row, exportValueString(p.Properties[name]))
+		}
+		_ = cw.Write(append(row, strings.TrimSpace(p.Markdown)))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportValueString はプロパティ値をCSVのセル文字列に変換する
+func exportValueString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []string:
+		return strings.Join(x, "; ")
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
+
+// reExportSlugUnsafe はファイル名に使えない文字
+var reExportSlugUnsafe = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// markdownFileName は "公開日（なければ作成日）-タイトル-ページID先頭8文字.md" を返す
+func markdownFileName(p NotionExportPage) string {
+	date := p.headline.PublishedDate
+	if date == "" {
+		date = p.CreatedTime
+	}
+	if len(date) >= 10 {
+		date = date[:10]
+	}
+	slug := strings.Trim(reExportSlugUnsafe.ReplaceAllString(strings.ToLower(p.headline.Title), "-"), "-")
+	slug = truncateString(slug, 60)
+	id := strings.ReplaceAll(p.ID, "-", "")
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%s-%s-%s.md", date, slug, id)
+}
+
+// WriteNotionExportMarkdown は1ページ1ファイルでMarkdownを書き出す
+//
+// 先頭の "---" で囲んだ部分にプロパティを書く（値はJSON形式のため、そのままYAMLとして読める）。
+func WriteNotionExportMarkdown(dir string, pages []NotionExportPage) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating export directory: %w", err)
+	}
+	for _, p := range pages {
+		var sb strings.Builder
+		sb.WriteString("---\n")
+		meta := map[string]any{
+			"notion_id":        p.ID,
+			"notion_url":       p.URL,
+			"created_time":     p.CreatedTime,
+			"last_edited_time": p.LastEditedTime,
+		}
+		writeFrontMatter(&sb, meta)
+		writeFrontMatter(&sb, p.Properties)
+		sb.WriteString("---\n\n")
+		fmt.Fprintf(&sb, "# %s\n\n", p.headline.Title)
+		if p.Markdown != "" {
+			sb.WriteString(p.Markdown)
+		}
+
+		path := filepath.Join(dir, markdownFileName(p))
+		if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// writeFrontMatter はキー順に "キー: JSON値" を書き出す
+func writeFrontMatter(sb *strings.Builder, values map[string]any) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, _ := json.Marshal(k)
+		val, _ := json.Marshal(values[k])
+		fmt.Fprintf(sb, "%s: %s\n", key, val)
+	}
+}
+
+// =============================================================================
+// CLIハンドラ
+// =============================================================================
+
+// HandleNotionExport は -notionExport モードのハンドラ
+//
+// jsonl / csv は outPath（空の場合はstdout）に、markdown は outPath のフォルダ
+// （空の場合は notion-export）に書き出す。
+func HandleNotionExport(cfg *NotionAdminConfig, outPath string) {
+	from, to, err := cfg.ExportRange()
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	format := strings.ToLower(cfg.ExportFormat)
+	if format == "md" {
+		format = ExportFormatMarkdown
+	}
+	switch format {
+	case ExportFormatJSONL, ExportFormatCSV, ExportFormatMarkdown:
+	default:
+		fatalf("ERROR: unknown export format: %s (use jsonl, csv or markdown)", cfg.ExportFormat)
+	}
+
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "📦 Notion Export")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	clipper := createNotionClipper()
+	start := time.Now()
+	pages, err := clipper.ExportPages(context.Background(), from, to, cfg.ExportBody)
+	if err != nil {
+		fatalf("ERROR exporting from Notion: %v", err)
+	}
+
+	if format == ExportFormatMarkdown {
+		dir := outPath
+		if dir == "" {
+			dir = "notion-export"
+		}
+		if err := WriteNotionExportMarkdown(dir, pages); err != nil {
+			fatalf("ERROR: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Exported %d pages to %s/ (%v)\n", len(pages), dir, time.Since(start).Round(time.Second))
+		return
+	}
+
+	w := io.Writer(os.Stdout)
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			fatalf("creating output: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := WriteNotionExport(w, pages, format); err != nil {
+		fatalf("writing export: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %d pages as %s (%v)\n", len(pages), format, time.Since(start).Round(time.Second))
+}
@@ -0,0 +1,239 @@
+// =============================================================================
+// notion_init.go - Notionデータベースの初期設定（notion init）
+// =============================================================================
+//
+// このファイルは "Carbon News Clippings" データベースを明示的に作成・確認する
+// -notionInit コマンドを提供します。
+//
+// 【背景】
+//   以前は -notionClip の実行時に NOTION_DATABASE_ID が空だとデータベースを作成し、
+//   作業ディレクトリの .env を書き換えていた。CIでは予期しない動作になり、
+//   Lambdaでは .env を書き込めない。クリップ時のデータベース作成は廃止し、
+//   作成はこのコマンドだけで行う。
+//
+// 【冪等性】
+//   何度実行しても同じデータベースになる:
+//     1. NOTION_DATABASE_ID（-notionDatabaseID）がある場合 → そのデータベースを確認
+//     2. 親ページ（-notionPageID）の子に同名のデータベースがある場合 → それを再利用
+//     3. どちらもない場合 → 最新のスキーマ（notion_schema.go）で新規作成
+//   既存データベースには不足プロパティ・セレクトオプションを追加する（-notionMigrate と同じ）。
+//
+// 【ビュー】
+//   Notion APIはデータベースのビュー（フィルタ・並び替え・ボード表示）を作成できない。
+//   推奨ビューの設定内容を表示するので、Notionの画面で作成する。
+//
+// 【出力】
+//   データベースIDのみを stdout に出力する（スクリプトで受け取れるように）。
+//   -notionInitWrite を指定した場合は、そのファイルに NOTION_DATABASE_ID=... を書き込む。
+//
+// 【使用方法】
+//
+//	./pipeline -notionInit -notionPageID <親ページID>
+//	./pipeline -notionInit -notionPageID <親ページID> -notionInitWrite .env
+//	NOTION_DATABASE_ID=$(./pipeline -notionInit -notionPageID <親ページID>)
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// NotionDatabaseTitle は記事クリッピング用データベースのタイトル
+const NotionDatabaseTitle = "Carbon News Clippings"
+
+// notionViewSpec は推奨ビューの設定（APIで作成できないため表示のみ）
+type notionViewSpec struct {
+	Name   string
+	Layout string
+	Setup  string
+}
+
+// notionRecommendedViews はダイジェスト編集で使う推奨ビュー
+var notionRecommendedViews = []notionViewSpec{
+	{Name: "Digest queue", Layout: "Table", Setup: "filter: Include in digest is checked; sort: Priority, then Created time descending"},
+	{Name: "By source", Layout: "Board", Setup: "group by: Source; sort: Published Date descending"},
+	{Name: "Academic", Layout: "Table", Setup: "filter: Type is Academic; sort: Published Date descending"},
+}
+
+// NotionInitResult はデータベースの初期設定の結果
+type NotionInitResult struct {
+	// DatabaseID は作成または確認したデータベースのID
+	DatabaseID string
+
+	// Created はデータベースを新規作成した場合にtrue
+	Created bool
+
+	// Reused は親ページの既存データベースを再利用した場合にtrue
+	Reused bool
+
+	// Plan は既存データベースに適用したマイグレーション（新規作成時はnil）
+	Plan *SchemaPlan
+}
+
+// InitDatabase はデータベースを確認し、なければ親ページの下に作成する
+//
+// クリッパーにデータベースIDが設定されている場合は親ページを使わない。
+func (nc *NotionClipper) InitDatabase(ctx context.Context, pageID string) (*NotionInitResult, error) {
+	result := &NotionInitResult{}
+
+	if nc.dbID == "" {
+		if pageID == "" {
+			return nil, fmt.Errorf("-notionPageID (NOTION_PAGE_ID) is required to create the database")
+		}
+		existing, err := nc.findChildDatabase(ctx, pageID, NotionDatabaseTitle)
+		if err != nil {
+			return nil, err
+		}
+		if existing == "" {
+			dbID, err := nc.CreateDatabase(ctx, pageID)
+			if err != nil {
+				return nil, err
+			}
+			result.DatabaseID = dbID
+			result.Created = true
+			return result, nil
+		}
+		nc.dbID = notionapi.DatabaseID(existing)
+		result.Reused = true
+	}
+
+	plan, err := nc.MigrateSchema(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	result.DatabaseID = string(nc.dbID)
+	result.Plan = plan
+	return result, nil
+}
+
+// findChildDatabase は親ページ直下にあるタイトルが一致するデータベースのIDを返す（ない場合は空）
+func (nc *NotionClipper) findChildDatabase(ctx context.Context, pageID, title string) (string, error) {
+	pagination := &notionapi.Pagination{PageSize: 100}
+	for {
+		var resp *notionapi.GetChildrenResponse
+		err := notionRetry(ctx, "Block.GetChildren", func() error {
+			var gerr error
+			resp, gerr = nc.client.Block.GetChildren(ctx, notionapi.BlockID(pageID), pagination)
+			return gerr
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list parent page %s: %w", pageID, err)
+		}
+
+		for _, b := range resp.Results {
+			if db, ok := b.(*notionapi.ChildDatabaseBlock); ok && db.ChildDatabase.Title == title && !db.Archived {
+				return string(db.ID), nil
+			}
+		}
+
+		if !resp.HasMore {
+			return "", nil
+		}
+		pagination.StartCursor = notionapi.Cursor(resp.NextCursor)
+	}
+}
+
+// =============================================================================
+// 設定ファイル操作
+// =============================================================================
+
+// writeEnvFileValue は KEY=value 形式の設定ファイルにキーを追加または更新する
+//
+// キーが既に存在する場合は値を更新、存在しない場合は末尾に追加する。
+// コメントアウトされたキー（#KEY=value）も検出して上書きする。
+// ファイルがない場合は作成する。
+func writeEnvFileValue(filename, key, value string) error {
+	content := ""
+	data, err := os.ReadFile(filename)
+	if err == nil {
+		content = strings.TrimRight(string(data), "\n")
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(content, "\n")
+	}
+	keyExists := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, key+"=") || strings.HasPrefix(line, "#"+key+"=") {
+			lines[i] = key + "=" + value
+			keyExists = true
+			break
+		}
+	}
+	if !keyExists {
+		lines = append(lines, key+"="+value)
+	}
+
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
+
+// =============================================================================
+// notion init ハンドラ
+// =============================================================================
+
+// HandleNotionInit はデータベースを作成または確認し、IDを stdout に出力する
+func HandleNotionInit(cfg *NotionAdminConfig, out *OutputConfig) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "🗂  Notion Database Setup")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	token := os.Getenv("NOTION_TOKEN")
+	if token == "" {
+		fatalf("ERROR: NOTION_TOKEN environment variable is required")
+	}
+	clipper, err := NewNotionClipper(token, out.NotionDatabaseID)
+	if err != nil {
+		fatalf("ERROR creating Notion clipper: %v", err)
+	}
+
+	result, err := clipper.InitDatabase(context.Background(), out.NotionPageID)
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+
+	switch {
+	case result.Created:
+		fmt.Fprintf(os.Stderr, "Schema version: v%d\n", NotionSchemaVersion())
+	case result.Reused:
+		fmt.Fprintf(os.Stderr, "✅ Found existing database %q under the parent page: %s\n", NotionDatabaseTitle, result.DatabaseID)
+	default:
+		fmt.Fprintf(os.Stderr, "✅ Using database: %s\n", result.DatabaseID)
+	}
+	if result.Plan != nil {
+		if result.Plan.Applicable() {
+			fmt.Fprintf(os.Stderr, "✅ Migrated schema v%d → v%d (%d properties updated)\n",
+				result.Plan.CurrentVersion, result.Plan.TargetVersion, len(result.Plan.Updates))
+		} else {
+			fmt.Fprintf(os.Stderr, "✅ Schema is up to date (v%d)\n", result.Plan.TargetVersion)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "\nRecommended views (the Notion API cannot create views; add them in Notion):")
+	for _, v := range notionRecommendedViews {
+		fmt.Fprintf(os.Stderr, "  - %s (%s): %s\n", v.Name, v.Layout, v.Setup)
+	}
+
+	if cfg.InitWrite != "" {
+		if err := writeEnvFileValue(cfg.InitWrite, "NOTION_DATABASE_ID", result.DatabaseID); err != nil {
+			fatalf("ERROR: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "\n✅ NOTION_DATABASE_ID written to %s\n", cfg.InitWrite)
+	} else {
+		fmt.Fprintf(os.Stderr, "\nSet NOTION_DATABASE_ID=%s (or rerun with -notionInitWrite .env)\n", result.DatabaseID)
+	}
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	fmt.Println(result.DatabaseID)
+}
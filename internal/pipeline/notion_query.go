@@ -0,0 +1,204 @@
+// =============================================================================
+// notion_query.go - Notionデータベースのサーバー側フィルタ・ソート
+// =============================================================================
+//
+// このファイルはNotionデータベースの検索条件（期間・Type・Source・並び順）を
+// DatabaseQueryRequest のフィルタに変換し、条件に合うページだけを取得します。
+//
+// 【背景】
+//   以前の FetchRecentHeadlines はフィルタなしで全ページを取得し、作成日時での絞り込みを
+//   コード側で行っていた。クリップが数千件に増えると、毎日のメール送信のたびに
+//   データベース全体をページングしていた。
+//
+// 【変換ルール】
+//
+//	CreatedOnOrAfter / CreatedBefore     → timestamp: created_time
+//	PublishedOnOrAfter / PublishedBefore → property: "Published Date"（date）
+//	Types / Sources                      → property: "Type" / "Source"（select、複数指定はOR）
+//	Sort                                 → sorts（作成日時または公開日の昇順/降順）
+//
+//	条件は全て AND で結合する（Notionの入れ子制限: 2階層まで）。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// 並び順
+const (
+	HeadlineSortCreatedDesc   = "created_desc"   // 作成日時の新しい順（デフォルト）
+	HeadlineSortCreatedAsc    = "created_asc"    // 作成日時の古い順
+	HeadlineSortPublishedDesc = "published_desc" // 公開日の新しい順
+	HeadlineSortPublishedAsc  = "published_asc"  // 公開日の古い順
+)
+
+// HeadlineQuery はNotionデータベースの検索条件（ゼロ値の項目は条件にしない）
+type HeadlineQuery struct {
+	// 作成日時（created_time）の範囲 [CreatedOnOrAfter, CreatedBefore)
+	CreatedOnOrAfter time.Time
+	CreatedBefore    time.Time
+
+	// 公開日（"Published Date" プロパティ）の範囲 [PublishedOnOrAfter, PublishedBefore)
+	PublishedOnOrAfter time.Time
+	PublishedBefore    time.Time
+
+	// Types / Sources はいずれかに一致するページのみ（Academic / News、ソース名）
+	Types   []string
+	Sources []string
+
+	// Sort は並び順（HeadlineSortCreatedDesc など、空の場合は作成日時の新しい順）
+	Sort string
+
+	// Limit は取得する最大件数（0 = 無制限）
+	Limit int
+}
+
+// Filter は検索条件をNotionのフィルタに変換する（条件がない場合はnil）
+func (q HeadlineQuery) Filter() notionapi.Filter {
+	var filters notionapi.AndCompoundFilter
+
+	filters = append(filters, dateRangeFilters(q.CreatedOnOrAfter, q.CreatedBefore,
+		func(c *notionapi.DateFilterCondition) notionapi.Filter {
+			return notionapi.TimestampFilter{Timestamp: notionapi.TimestampCreated, CreatedTime: c}
+		})...)
+	filters = append(filters, dateRangeFilters(q.PublishedOnOrAfter, q.PublishedBefore,
+		func(c *notionapi.DateFilterCondition) notionapi.Filter {
+			return notionapi.PropertyFilter{Property: "Published Date", Date: c}
+		})...)
+	if f := selectAnyOf("Type", q.Types); f != nil {
+		filters = append(filters, f)
+	}
+	if f := selectAnyOf("Source", q.Sources); f != nil {
+		filters = append(filters, f)
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	}
+	return filters
+}
+
+// Sorts は並び順をNotionのソート指定に変換する
+func (q HeadlineQuery) Sorts() ([]notionapi.SortObject, error) {
+	switch q.Sort {
+	case HeadlineSortCreatedDesc, "":
+		return []notionapi.SortObject{{Timestamp: notionapi.TimestampCreated, Direction: notionapi.SortOrderDESC}}, nil
+	case HeadlineSortCreatedAsc:
+		return []notionapi.SortObject{{Timestamp: notionapi.TimestampCreated, Direction: notionapi.SortOrderASC}}, nil
+	case HeadlineSortPublishedDesc:
+		return []notionapi.SortObject{{Property: "Published Date", Direction: notionapi.SortOrderDESC}}, nil
+	case HeadlineSortPublishedAsc:
+		return []notionapi.SortObject{{Property: "Published Date", Direction: notionapi.SortOrderASC}}, nil
+	}
+	return nil, fmt.Errorf("unknown sort: %s", q.Sort)
+}
+
+// dateRangeFilters は [onOrAfter, before) の日付条件を返す（ゼロ値の側は条件にしない）
+//
+// Notion APIは1つの日付条件に複数の演算子を指定できないため、開始と終了を別の条件にする。
+func dateRangeFilters(onOrAfter, before time.Time, build func(*notionapi.DateFilterCondition) notionapi.Filter) []notionapi.Filter {
+	var filters []notionapi.Filter
+	if !onOrAfter.IsZero() {
+		d := notionapi.Date(onOrAfter)
+		filters = append(filters, build(&notionapi.DateFilterCondition{OnOrAfter: &d}))
+	}
+	if !before.IsZero() {
+		d := notionapi.Date(before)
+		filters = append(filters, build(&notionapi.DateFilterCondition{Before: &d}))
+	}
+	return filters
+}
+
+// selectAnyOf はセレクトプロパティがいずれかの値に一致する条件を返す（値がない場合はnil）
+func selectAnyOf(property string, values []string) notionapi.Filter {
+	var filters notionapi.OrCompoundFilter
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		filters = append(filters, notionapi.PropertyFilter{
+			Property: property,
+			Select:   &notionapi.SelectFilterCondition{Equals: v},
+		})
+	}
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	}
+	return filters
+}
+
+// queryPages は条件に合うページをページングしながら取得し、visit を呼び出す
+//
+// visit がエラーを返すか Limit 件に達した時点で取得を止める。
+func (nc *NotionClipper) queryPages(ctx context.Context, q HeadlineQuery, visit func(notionapi.Page) error) error {
+	if nc.dbID == "" {
+		return fmt.Errorf("database ID not set")
+	}
+	sorts, err := q.Sorts()
+	if err != nil {
+		return err
+	}
+
+	pageSize := 100
+	if q.Limit > 0 && q.Limit < pageSize {
+		pageSize = q.Limit
+	}
+	req := &notionapi.DatabaseQueryRequest{
+		Filter:   q.Filter(),
+		Sorts:    sorts,
+		PageSize: pageSize,
+	}
+
+	count := 0
+	for {
+		var resp *notionapi.DatabaseQueryResponse
+		err := notionRetry(ctx, "Database.Query", func() error {
+			var qerr error
+			resp, qerr = nc.client.Database.Query(ctx, nc.dbID, req)
+			return qerr
+		})
+		if err != nil {
+			return fmt.Errorf("failed to query database: %w", err)
+		}
+
+		for _, page := range resp.Results {
+			if err := visit(page); err != nil {
+				return err
+			}
+			count++
+			if q.Limit > 0 && count >= q.Limit {
+				return nil
+			}
+		}
+
+		if !resp.HasMore {
+			return nil
+		}
+		req.StartCursor = resp.NextCursor
+	}
+}
+
+// QueryHeadlines は条件に合うヘッドラインを取得する
+func (nc *NotionClipper) QueryHeadlines(ctx context.Context, q HeadlineQuery) ([]NotionHeadline, error) {
+	var headlines []NotionHeadline
+	err := nc.queryPages(ctx, q, func(page notionapi.Page) error {
+		headlines = append(headlines, notionHeadlineFromPage(page))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return headlines, nil
+}
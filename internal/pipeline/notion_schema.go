@@ -0,0 +1,568 @@
+// =============================================================================
+// notion_schema.go - Notionデータベーススキーマとマイグレーション
+// =============================================================================
+//
+// このファイルは "Carbon News Clippings" データベースのスキーマを宣言的に定義し、
+// 既存データベースとの差分（ドリフト）の検出とマイグレーションを行います。
+//
+// 【背景】
+//   - 以前は Article Summary 300 の追加だけを行う一回限りの処理があり、
+//     Sourceのセレクトオプションは CreateDatabase に固定されていた
+//   - 後から追加したソース（Verra、Puro.earth、Isometric、RMI 等）は
+//     Notionのオプション自動作成に頼っていた
+//
+// 【マイグレーション】
+//   notionMigrations にバージョン順で定義する。各マイグレーションは
+//   「追加するプロパティ」と「追加するセレクトオプション」のみを持つ。
+//   適用済みバージョンはデータベースのプロパティから判定する
+//   （そのバージョンまでの全プロパティと全セレクトオプションが存在すれば適用済み）。
+//
+// 【安全性】
+//   - 既存プロパティの型・設定は変更しない（Notion AIの自動入力設定を保持）
+//   - セレクトオプションは既存オプションとの和集合で更新し、削除しない
+//   - 型の不一致やスキーマ外のプロパティはドリフトとして報告するのみ
+//
+// 【使用方法】
+//
+//	./pipeline -notionMigrate              # 不足プロパティ・オプションを追加
+//	./pipeline -notionMigrate -notionDryRun # 差分の表示のみ
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// notionPropertySpec はスキーマ上の1プロパティの定義
+type notionPropertySpec struct {
+	Name    string
+	Type    notionapi.PropertyConfigType
+	Options []notionapi.Option // select / multi_select のオプション
+}
+
+// notionMigration はスキーマのバージョン1つ分の変更
+type notionMigration struct {
+	Version     int
+	Description string
+	Properties  []notionPropertySpec
+}
+
+// notionMigrations はスキーマの変更履歴（バージョン順）
+//
+// 新しいプロパティやセレクトオプションを追加する場合は、既存のマイグレーションを
+// 編集せず末尾に新しいバージョンを追加すること。
+var notionMigrations = []notionMigration{
+	{
+		Version:     1,
+		Description: "base clipping properties",
+		Properties: []notionPropertySpec{
+			{Name: "Title", Type: notionapi.PropertyConfigTypeTitle},
+			{Name: "URL", Type: notionapi.PropertyConfigTypeURL},
+			{Name: "Source", Type: notionapi.PropertyConfigTypeSelect, Options: []notionapi.Option{
+				{Name: "CarbonCredits.jp", Color: notionapi.ColorOrange},
+				{Name: "Carbon Herald", Color: notionapi.ColorPink},
+				{Name: "Climate Home News", Color: notionapi.ColorPurple},
+				{Name: "CarbonCredits.com", Color: notionapi.ColorYellow},
+				{Name: "Sandbag", Color: notionapi.ColorBlue},
+				{Name: "Ecosystem Marketplace", Color: notionapi.ColorGreen},
+				{Name: "Carbon Brief", Color: notionapi.ColorPurple},
+				{Name: "ICAP", Color: notionapi.ColorRed},
+				{Name: "IETA", Color: notionapi.ColorBrown},
+				{Name: "Energy Monitor", Color: notionapi.ColorPink},
+				{Name: "Japan Research Institute", Color: notionapi.ColorGreen},
+				{Name: "Japan Environment Ministry", Color: notionapi.ColorBlue},
+				{Name: "Japan Exchange Group (JPX)", Color: notionapi.ColorRed},
+				{Name: "Japan Ministry of Economy (METI)", Color: notionapi.ColorRed},
+				{Name: "World Bank", Color: notionapi.ColorBrown},
+				{Name: "Carbon Market Watch", Color: notionapi.ColorPurple},
+				{Name: "NewClimate Institute", Color: notionapi.ColorGreen},
+				{Name: "Carbon Knowledge Hub", Color: notionapi.ColorOrange},
+				{Name: "PwC Japan", Color: notionapi.ColorPink},
+				{Name: "Mizuho Research & Technologies", Color: notionapi.ColorBlue},
+				{Name: "Free Article", Color: notionapi.ColorDefault},
+			}},
+			{Name: "Type", Type: notionapi.PropertyConfigTypeSelect, Options: []notionapi.Option{
+				{Name: "News", Color: notionapi.ColorBlue},
+				{Name: "Academic", Color: notionapi.ColorGreen},
+			}},
+			{Name: "Score", Type: notionapi.PropertyConfigTypeNumber},
+			{Name: "Published Date", Type: notionapi.PropertyConfigTypeDate},
+		},
+	},
+	{
+		Version:     2,
+		Description: "Article Summary 300 (filled by Notion AI)",
+		Properties: []notionPropertySpec{
+			{Name: "Article Summary 300", Type: notionapi.PropertyConfigTypeRichText},
+		},
+	},
+	{
+		Version:     3,
+		Description: "Source options for sources added after launch",
+		Properties: []notionPropertySpec{
+			{Name: "Source", Type: notionapi.PropertyConfigTypeSelect, Options: []notionapi.Option{
+				{Name: "RMI", Color: notionapi.ColorGreen},
+				{Name: "Politico EU", Color: notionapi.ColorRed},
+				{Name: "Euractiv", Color: notionapi.ColorBlue},
+				{Name: "UN News", Color: notionapi.ColorBlue},
+				{Name: "UNFCCC", Color: notionapi.ColorBlue},
+				{Name: "Nature Communications", Color: notionapi.ColorGreen},
+				{Name: "OIES", Color: notionapi.ColorBrown},
+				{Name: "IOP Science (ERL)", Color: notionapi.ColorGreen},
+				{Name: "ScienceDirect", Color: notionapi.ColorOrange},
+				{Name: "arXiv", Color: notionapi.ColorRed},
+				{Name: "EU ETS", Color: notionapi.ColorBlue},
+				{Name: "UK ETS", Color: notionapi.ColorRed},
+				{Name: "CARB", Color: notionapi.ColorYellow},
+				{Name: "RGGI", Color: notionapi.ColorGreen},
+				{Name: "Australia CER", Color: notionapi.ColorOrange},
+				{Name: "Verra", Color: notionapi.ColorGreen},
+				{Name: "Gold Standard", Color: notionapi.ColorYellow},
+				{Name: "ACR", Color: notionapi.ColorBlue},
+				{Name: "Climate Action Reserve", Color: notionapi.ColorGreen},
+				{Name: "IISD ENB", Color: notionapi.ColorBlue},
+				{Name: "Climate Focus", Color: notionapi.ColorPurple},
+				{Name: "Puro.earth", Color: notionapi.ColorGray},
+				{Name: "Isometric", Color: notionapi.ColorGray},
+				{Name: "METI Shingikai", Color: notionapi.ColorRed},
+			}},
+		},
+	},
+	{
+		Version:     4,
+		Description: "extracted entities (entities.go)",
+		Properties: []notionPropertySpec{
+			{Name: "Registries", Type: notionapi.PropertyConfigTypeMultiSelect},
+			{Name: "Project IDs", Type: notionapi.PropertyConfigTypeMultiSelect},
+			{Name: "Methodologies", Type: notionapi.PropertyConfigTypeMultiSelect},
+			{Name: "Jurisdictions", Type: notionapi.PropertyConfigTypeMultiSelect},
+			{Name: "Article 6 Pairs", Type: notionapi.PropertyConfigTypeMultiSelect},
+			{Name: "ETS", Type: notionapi.PropertyConfigTypeMultiSelect},
+		},
+	},
+	{
+		Version:     5,
+		Description: "extracted prices and volumes (prices.go)",
+		Properties: []notionPropertySpec{
+			{Name: "Prices", Type: notionapi.PropertyConfigTypeRichText},
+		},
+	},
+	{
+		Version:     6,
+		Description: "extractive summary fallback (summarize.go)",
+		Properties: []notionPropertySpec{
+			{Name: "Extracted Summary", Type: notionapi.PropertyConfigTypeRichText},
+		},
+	},
+	{
+		Version:     7,
+		Description: "article language (language.go)",
+		Properties: []notionPropertySpec{
+			{Name: "Language", Type: notionapi.PropertyConfigTypeSelect, Options: []notionapi.Option{
+				{Name: LangJapanese, Color: notionapi.ColorRed},
+				{Name: LangEnglish, Color: notionapi.ColorBlue},
+			}},
+		},
+	},
+	{
+		Version:     8,
+		Description: "authors and topics (topics.go)",
+		Properties: []notionPropertySpec{
+			{Name: "Authors", Type: notionapi.PropertyConfigTypeRichText},
+			{Name: "Topics", Type: notionapi.PropertyConfigTypeMultiSelect, Options: []notionapi.Option{
+				{Name: "Compliance Markets", Color: notionapi.ColorBlue},
+				{Name: "Voluntary Markets", Color: notionapi.ColorGreen},
+				{Name: "Article 6", Color: notionapi.ColorPurple},
+				{Name: "Carbon Removal", Color: notionapi.ColorBrown},
+				{Name: "Nature-based", Color: notionapi.ColorGreen},
+				{Name: "CBAM & Trade", Color: notionapi.ColorOrange},
+				{Name: "Carbon Pricing", Color: notionapi.ColorYellow},
+				{Name: "Aviation & Shipping", Color: notionapi.ColorGray},
+			}},
+		},
+	},
+	{
+		Version:     9,
+		Description: "editorial status (editorial.go)",
+		Properties: []notionPropertySpec{
+			{Name: EditorialIncludeProperty, Type: notionapi.PropertyConfigTypeCheckbox},
+			{Name: EditorialPriorityProperty, Type: notionapi.PropertyConfigTypeSelect, Options: []notionapi.Option{
+				{Name: PriorityHigh, Color: notionapi.ColorRed},
+				{Name: PriorityNormal, Color: notionapi.ColorDefault},
+				{Name: PriorityLow, Color: notionapi.ColorGray},
+			}},
+			{Name: EditorialNoteProperty, Type: notionapi.PropertyConfigTypeRichText},
+		},
+	},
+}
+
+// NotionSchemaVersion は最新のスキーマバージョン
+func NotionSchemaVersion() int {
+	return notionMigrations[len(notionMigrations)-1].Version
+}
+
+// notionSchema は全マイグレーションを適用した最終スキーマを返す（名前順）
+func notionSchema() []notionPropertySpec {
+	merged := map[string]*notionPropertySpec{}
+	var names []string
+	for _, m := range notionMigrations {
+		for _, p := range m.Properties {
+			spec, ok := merged[p.Name]
+			if !ok {
+				spec = &notionPropertySpec{Name: p.Name, Type: p.Type}
+				merged[p.Name] = spec
+				names = append(names, p.Name)
+			}
+			spec.Options = mergeOptions(spec.Options, p.Options)
+		}
+	}
+	sort.Strings(names)
+	out := make([]notionPropertySpec, 0, len(names))
+	for _, name := range names {
+		out = append(out, *merged[name])
+	}
+	return out
+}
+
+// config はプロパティ定義をNotion APIのプロパティ設定に変換する
+func (s notionPropertySpec) config() notionapi.PropertyConfig {
+	options := s.Options
+	if options == nil {
+		// オプションなしのセレクトでも空配列を送る（nullはAPIエラー）
+		options = []notionapi.Option{}
+	}
+	switch s.Type {
+	case notionapi.PropertyConfigTypeTitle:
+		return notionapi.TitlePropertyConfig{Type: s.Type}
+	case notionapi.PropertyConfigTypeURL:
+		return notionapi.URLPropertyConfig{Type: s.Type}
+	case notionapi.PropertyConfigTypeNumber:
+		return notionapi.NumberPropertyConfig{Type: s.Type, Number: notionapi.NumberFormat{Format: notionapi.FormatNumber}}
+	case notionapi.PropertyConfigTypeDate:
+		return notionapi.DatePropertyConfig{Type: s.Type}
+	case notionapi.PropertyConfigTypeSelect:
+		return notionapi.SelectPropertyConfig{Type: s.Type, Select: notionapi.Select{Options: options}}
+	case notionapi.PropertyConfigTypeMultiSelect:
+		return notionapi.MultiSelectPropertyConfig{Type: s.Type, MultiSelect: notionapi.Select{Options: options}}
+	case notionapi.PropertyConfigTypeCheckbox:
+		return notionapi.CheckboxPropertyConfig{Type: s.Type}
+	}
+	return notionapi.RichTextPropertyConfig{Type: notionapi.PropertyConfigTypeRichText}
+}
+
+// notionSchemaPropertyConfigs は新規データベース作成用の全プロパティ設定を返す
+func notionSchemaPropertyConfigs() notionapi.PropertyConfigs {
+	configs := notionapi.PropertyConfigs{}
+	for _, spec := range notionSchema() {
+		configs[spec.Name] = spec.config()
+	}
+	return configs
+}
+
+// mergeOptions はオプションの和集合を返す（既存の順序・ID・色を保持し、不足分を末尾に追加）
+func mergeOptions(existing, wanted []notionapi.Option) []notionapi.Option {
+	out := append([]notionapi.Option{}, existing...)
+	have := map[string]bool{}
+	for _, o := range existing {
+		have[o.Name] = true
+	}
+	for _, o := range wanted {
+		if !have[o.Name] {
+			out = append(out, o)
+			have[o.Name] = true
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// ドリフト検出
+// =============================================================================
+
+// スキーマ差分の種類
+const (
+	SchemaAddProperty   = "add_property"   // プロパティが存在しない（マイグレーションで追加）
+	SchemaAddOptions    = "add_options"    // セレクトオプションが不足（マイグレーションで追加）
+	SchemaTypeMismatch  = "type_mismatch"  // 型が異なる（報告のみ、変更しない）
+	SchemaExtraProperty = "extra_property" // スキーマにないプロパティ（報告のみ）
+)
+
+// SchemaChange はスキーマ差分の1件
+type SchemaChange struct {
+	Kind     string
+	Property string
+	Detail   string
+}
+
+// SchemaPlan はデータベースとスキーマの差分とマイグレーション内容
+type SchemaPlan struct {
+	CurrentVersion int            // データベースに適用済みと判定したバージョン
+	TargetVersion  int            // 最新のスキーマバージョン
+	Changes        []SchemaChange // 差分（種類・プロパティ名順）
+	Updates        notionapi.PropertyConfigs
+	IncludedPages  int // "Include in digest" を追加した際にチェックを入れた既存ページ数（editorial.go）
+}
+
+// Applicable はマイグレーションで適用する変更があるかを返す
+func (p *SchemaPlan) Applicable() bool {
+	return len(p.Updates) > 0
+}
+
+// addsProperty はマイグレーションでプロパティ自体を追加するかを返す
+func (p *SchemaPlan) addsProperty(name string) bool {
+	for _, c := range p.Changes {
+		if c.Kind == SchemaAddProperty && c.Property == name {
+			return true
+		}
+	}
+	return false
+}
+
+// planNotionSchema は既存プロパティとスキーマを比較してマイグレーション計画を作成する
+func planNotionSchema(props notionapi.PropertyConfigs) *SchemaPlan {
+	plan := &SchemaPlan{
+		TargetVersion: NotionSchemaVersion(),
+		Updates:       notionapi.PropertyConfigs{},
+	}
+
+	// 適用済みバージョン: そのバージョンまでの全プロパティ・全セレクトオプションが存在する最大バージョン
+	for _, m := range notionMigrations {
+		if !migrationApplied(m, props) {
+			break
+		}
+		plan.CurrentVersion = m.Version
+	}
+
+	known := map[string]bool{}
+	for _, spec := range notionSchema() {
+		known[spec.Name] = true
+		existing, ok := props[spec.Name]
+		if !ok {
+			plan.Changes = append(plan.Changes, SchemaChange{Kind: SchemaAddProperty, Property: spec.Name, Detail: string(spec.Type)})
+			plan.Updates[spec.Name] = spec.config()
+			continue
+		}
+		if existing.GetType() != spec.Type {
+			plan.Changes = append(plan.Changes, SchemaChange{
+				Kind: SchemaTypeMismatch, Property: spec.Name,
+				Detail: fmt.Sprintf("expected %s, found %s (left unchanged)", spec.Type, existing.GetType()),
+			})
+			continue
+		}
+
+		// セレクトオプションの不足分を追加（既存オプションは保持）
+		current := selectOptions(existing)
+		merged := mergeOptions(current, spec.Options)
+		if len(merged) == len(current) {
+			continue
+		}
+		var added []string
+		for _, o := range merged[len(current):] {
+			added = append(added, o.Name)
+		}
+		plan.Changes = append(plan.Changes, SchemaChange{Kind: SchemaAddOptions, Property: spec.Name, Detail: strings.Join(added, ", ")})
+		updated := spec
+		updated.Options = merged
+		plan.Updates[spec.Name] = updated.config()
+	}
+
+	for name, cfg := range props {
+		if !known[name] {
+			plan.Changes = append(plan.Changes, SchemaChange{Kind: SchemaExtraProperty, Property: name, Detail: string(cfg.GetType())})
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		if plan.Changes[i].Kind != plan.Changes[j].Kind {
+			return plan.Changes[i].Kind < plan.Changes[j].Kind
+		}
+		return plan.Changes[i].Property < plan.Changes[j].Property
+	})
+	return plan
+}
+
+// migrationApplied はマイグレーションのプロパティとセレクトオプションがすべて存在するかを返す
+//
+// v3 のようにオプションだけを追加するマイグレーションは、プロパティの有無だけでは判定できない。
+func migrationApplied(m notionMigration, props notionapi.PropertyConfigs) bool {
+	for _, p := range m.Properties {
+		existing, ok := props[p.Name]
+		if !ok {
+			return false
+		}
+		have := map[string]bool{}
+		for _, o := range selectOptions(existing) {
+			have[o.Name] = true
+		}
+		for _, o := range p.Options {
+			if !have[o.Name] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// selectOptions はセレクト/マルチセレクトのプロパティ設定からオプションを取り出す
+func selectOptions(cfg notionapi.PropertyConfig) []notionapi.Option {
+	switch c := cfg.(type) {
+	case *notionapi.SelectPropertyConfig:
+		return c.Select.Options
+	case *notionapi.MultiSelectPropertyConfig:
+		return c.MultiSelect.Options
+	case notionapi.SelectPropertyConfig:
+		return c.Select.Options
+	case notionapi.MultiSelectPropertyConfig:
+		return c.MultiSelect.Options
+	}
+	return nil
+}
+
+// =============================================================================
+// マイグレーション実行
+// =============================================================================
+
+// PlanSchemaMigration はデータベースのスキーマを取得し、マイグレーション計画を返す
+func (nc *NotionClipper) PlanSchemaMigration(ctx context.Context) (*SchemaPlan, error) {
+	if nc.dbID == "" {
+		return nil, fmt.Errorf("database ID not set")
+	}
+	var db *notionapi.Database
+	err := notionRetry(ctx, "Database.Get", func() error {
+		var getErr error
+		db, getErr = nc.client.Database.Get(ctx, nc.dbID)
+		return getErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database schema: %w", err)
+	}
+	return planNotionSchema(db.Properties), nil
+}
+
+// MigrateSchema は不足しているプロパティとセレクトオプションをデータベースに追加する
+//
+// dryRun がtrueの場合は計画のみ返し、データベースを変更しない。
+// "Include in digest" を追加した場合は、既存ページが除外扱いにならないよう全ページにチェックを入れる。
+func (nc *NotionClipper) MigrateSchema(ctx context.Context, dryRun bool) (*SchemaPlan, error) {
+	plan, err := nc.PlanSchemaMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || !plan.Applicable() {
+		return plan, nil
+	}
+
+	err = notionRetry(ctx, "Database.Update", func() error {
+		_, updateErr := nc.client.Database.Update(ctx, nc.dbID, &notionapi.DatabaseUpdateRequest{
+			Properties: plan.Updates,
+		})
+		return updateErr
+	})
+	if err != nil {
+		return plan, fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+
+	if plan.addsProperty(EditorialIncludeProperty) {
+		plan.IncludedPages, err = nc.includeExistingPages(ctx)
+		if err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+// ensureSchema はクリップ前にスキーマのマイグレーションを一度だけ実行する
+//
+// クリップ処理は止めないが、失敗はプロパティの書き込み失敗の原因になるため警告として出力する。
+// 並列クリップ（notion_batch.go）から呼ばれても一度しか実行されない。
+func (nc *NotionClipper) ensureSchema(ctx context.Context) {
+	if nc.dbID == "" {
+		return
+	}
+	nc.schemaOnce.Do(func() { nc.migrateSchemaBeforeClip(ctx) })
+}
+
+// migrateSchemaBeforeClip はマイグレーションを実行する（失敗は警告、成功はデバッグログに出力）
+func (nc *NotionClipper) migrateSchemaBeforeClip(ctx context.Context) {
+	plan, err := nc.MigrateSchema(ctx, false)
+	if err != nil {
+		warnf("Notion schema migration failed (run -notionMigrate to retry): %v", err)
+		return
+	}
+	if plan.IncludedPages > 0 {
+		infof("Checked \"%s\" on %d existing pages", EditorialIncludeProperty, plan.IncludedPages)
+	}
+	if os.Getenv("DEBUG_SCRAPING") == "" {
+		return
+	}
+	if plan.Applicable() {
+		fmt.Fprintf(os.Stderr, "[DEBUG] Migrated database schema v%d → v%d (%d properties updated)\n",
+			plan.CurrentVersion, plan.TargetVersion, len(plan.Updates))
+	}
+}
+
+// =============================================================================
+// notion migrate ハンドラ
+// =============================================================================
+
+// HandleNotionMigrate はデータベーススキーマの差分を表示し、マイグレーションを実行する
+func HandleNotionMigrate(dryRun bool) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "🗂  Notion Schema Migration")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	clipper := createNotionClipper()
+	plan, err := clipper.MigrateSchema(context.Background(), dryRun)
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Schema version: v%d (latest: v%d)\n\n", plan.CurrentVersion, plan.TargetVersion)
+	for _, m := range notionMigrations {
+		mark := "✅"
+		if m.Version > plan.CurrentVersion {
+			mark = "⏳"
+		}
+		fmt.Fprintf(os.Stderr, "  %s v%d %s\n", mark, m.Version, m.Description)
+	}
+	fmt.Fprintln(os.Stderr, "")
+
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(os.Stderr, "✅ Database schema is up to date")
+		return
+	}
+
+	labels := map[string]string{
+		SchemaAddProperty:   "➕ add property",
+		SchemaAddOptions:    "➕ add options ",
+		SchemaTypeMismatch:  "⚠️  type drift  ",
+		SchemaExtraProperty: "ℹ️  not in schema",
+	}
+	for _, c := range plan.Changes {
+		fmt.Fprintf(os.Stderr, "  %s  %s (%s)\n", labels[c.Kind], c.Property, c.Detail)
+	}
+	fmt.Fprintln(os.Stderr, "")
+
+	switch {
+	case !plan.Applicable():
+		fmt.Fprintln(os.Stderr, "Nothing to migrate (drift above is reported only and left unchanged)")
+	case dryRun:
+		fmt.Fprintf(os.Stderr, "Dry run: %d properties would be updated\n", len(plan.Updates))
+		if plan.addsProperty(EditorialIncludeProperty) {
+			fmt.Fprintf(os.Stderr, "Dry run: \"%s\" would be checked on all existing pages\n", EditorialIncludeProperty)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "✅ Updated %d properties\n", len(plan.Updates))
+		if plan.IncludedPages > 0 {
+			fmt.Fprintf(os.Stderr, "✅ Checked \"%s\" on %d existing pages\n", EditorialIncludeProperty, plan.IncludedPages)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "========================================")
+}
@@ -0,0 +1,261 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"carbon-relay/internal/notionfake"
+)
+
+// fakeParentPage は代替サーバーに登録する親ページのID
+const fakeParentPage = "fake-parent-page"
+
+// newFakeNotion は代替サーバー（internal/notionfake）を起動し、データベース作成済みのクリッパーを返す
+func newFakeNotion(t *testing.T) (*notionfake.Server, *NotionClipper) {
+	t.Helper()
+
+	fake := notionfake.New()
+	fake.AddParentPage(fakeParentPage)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("NOTION_API_BASE_URL", srv.URL)
+	t.Setenv("NOTION_RATE_LIMIT", "1000")
+	t.Setenv("DEBUG_SCRAPING", "")
+
+	// HTMLの502等のリトライ待ちを短くする
+	policy := notionRetryPolicies[NotionErrTransient]
+	notionRetryPolicies[NotionErrTransient] = notionRetryPolicy{MaxRetries: policy.MaxRetries, BaseWait: time.Millisecond}
+	t.Cleanup(func() { notionRetryPolicies[NotionErrTransient] = policy })
+
+	nc, err := NewNotionClipper("fake-token", "")
+	if err != nil {
+		t.Fatalf("NewNotionClipper: %v", err)
+	}
+	result, err := nc.InitDatabase(context.Background(), fakeParentPage)
+	if err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	if !result.Created {
+		t.Fatalf("InitDatabase: expected a new database, got %+v", result)
+	}
+	return fake, nc
+}
+
+// testHeadlines はクリップ用の見出しを n 件作成する
+func testHeadlines(n int) []Headline {
+	headlines := make([]Headline, n)
+	for i := range headlines {
+		headlines[i] = Headline{
+			Source:      "Carbon Brief",
+			Title:       fmt.Sprintf("EU carbon price update %03d", i),
+			URL:         fmt.Sprintf("https://example.com/articles/%03d", i),
+			PublishedAt: "2026-02-04T09:00:00Z",
+			Excerpt:     "EU allowances traded at €70/t on Tuesday.",
+		}
+	}
+	return headlines
+}
+
+// blockTypes は子ブロックの種類を順に返す
+func blockTypes(blocks []map[string]any) []string {
+	var types []string
+	for _, b := range blocks {
+		types = append(types, fmt.Sprint(b["type"]))
+	}
+	return types
+}
+
+// propertyText はページのタイトル・テキストプロパティの文字列を返す
+func propertyText(page map[string]any, name string) string {
+	props, _ := page["properties"].(map[string]any)
+	prop, _ := props[name].(map[string]any)
+	typ, _ := prop["type"].(string)
+	items, _ := prop[typ].([]any)
+	var sb strings.Builder
+	for _, item := range items {
+		if rt, ok := item.(map[string]any); ok {
+			sb.WriteString(fmt.Sprint(rt["plain_text"]))
+		}
+	}
+	return sb.String()
+}
+
+func TestClipHeadlineCreatesPageWithBlocks(t *testing.T) {
+	fake, nc := newFakeNotion(t)
+
+	h := Headline{
+		Source:      "Carbon Brief",
+		Title:       "EU ETS prices rise",
+		URL:         "https://example.com/eu-ets",
+		PublishedAt: "2026-02-04T09:00:00Z",
+		Excerpt:     "## Market\n\nEU allowances traded at €70/t on Tuesday.\n\n- Auction volumes fell\n\n- Prices rose",
+		Topics:      []string{"Compliance Markets"},
+	}
+	if err := nc.ClipHeadline(context.Background(), h); err != nil {
+		t.Fatalf("ClipHeadline: %v", err)
+	}
+
+	pages := fake.Pages(string(nc.dbID))
+	if len(pages) != 1 {
+		t.Fatalf("pages = %d, want 1", len(pages))
+	}
+	page := pages[0]
+	if got := propertyText(page, "Title"); got != h.Title {
+		t.Errorf("Title = %q, want %q", got, h.Title)
+	}
+	if got := propertyText(page, "Article Summary 300"); !strings.Contains(got, "EU allowances traded") || strings.Contains(got, "## ") {
+		t.Errorf("Article Summary 300 = %q, want the plain excerpt", got)
+	}
+	if got := propertyText(page, "Extracted Summary"); got == "" {
+		t.Error("Extracted Summary is empty")
+	}
+
+	got := blockTypes(fake.Children(fmt.Sprint(page["id"])))
+	want := []string{"bookmark", "callout", "heading_2", "paragraph", "bulleted_list_item", "bulleted_list_item"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("blocks = %v, want %v", got, want)
+	}
+}
+
+func TestFetchRecentHeadlinesPaginates(t *testing.T) {
+	fake, nc := newFakeNotion(t)
+
+	// 1回のクエリは最大100件のため、2ページ以上になる件数をクリップする
+	const n = 105
+	result := nc.ClipHeadlines(context.Background(), testHeadlines(n), 4)
+	if result.Clipped != n || result.Failed != 0 {
+		t.Fatalf("ClipHeadlines: clipped %d, failed %d (%v)", result.Clipped, result.Failed, result.Errors)
+	}
+
+	headlines, err := nc.FetchRecentHeadlines(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("FetchRecentHeadlines: %v", err)
+	}
+	if len(headlines) != n {
+		t.Fatalf("headlines = %d, want %d", len(headlines), n)
+	}
+	seen := map[string]bool{}
+	for _, h := range headlines {
+		if seen[h.URL] {
+			t.Errorf("duplicate headline %s", h.URL)
+		}
+		seen[h.URL] = true
+	}
+
+	queries := 0
+	for _, r := range fake.Requests() {
+		if r == "POST /v1/databases/"+string(nc.dbID)+"/query" {
+			queries++
+		}
+	}
+	if queries < 2 {
+		t.Errorf("query requests = %d, want at least 2 (pagination)", queries)
+	}
+}
+
+func TestClipHeadlinesRecoversFromRateLimitAndBadGateway(t *testing.T) {
+	fake, nc := newFakeNotion(t)
+
+	// 次の2リクエストは429、最初のページ作成はロードバランサのHTMLの502
+	fake.Inject(notionfake.Fault{Status: http.StatusTooManyRequests, Count: 2})
+	fake.Inject(notionfake.Fault{Status: http.StatusBadGateway, HTML: true, Path: "/v1/pages", Count: 1})
+
+	const n = 3
+	result := nc.ClipHeadlines(context.Background(), testHeadlines(n), 1)
+	if result.Clipped != n || result.Failed != 0 {
+		t.Fatalf("ClipHeadlines: clipped %d, failed %d (%v)", result.Clipped, result.Failed, result.Errors)
+	}
+	if result.RateLimited != 2 {
+		t.Errorf("RateLimited = %d, want 2", result.RateLimited)
+	}
+	if pages := fake.Pages(string(nc.dbID)); len(pages) != n {
+		t.Errorf("pages = %d, want %d (no duplicates after retry)", len(pages), n)
+	}
+}
+
+func TestClipHeadlineReportsExhaustedRateLimit(t *testing.T) {
+	fake, nc := newFakeNotion(t)
+
+	// トランスポートの再送回数を超える429（最初の送信 + notionMax429Retries 回）
+	fake.Inject(notionfake.Fault{Status: http.StatusTooManyRequests, Path: "/v1/pages", Count: notionMax429Retries + 1})
+
+	err := nc.ClipHeadline(context.Background(), testHeadlines(1)[0])
+	if err == nil {
+		t.Fatal("ClipHeadline: expected an error after exhausting 429 retries")
+	}
+	if got := notionErrorCategory(err); got != NotionErrRateLimit {
+		t.Errorf("category = %q, want %q (%v)", got, NotionErrRateLimit, err)
+	}
+	if isNotionNetworkError(err) {
+		t.Errorf("exhausted 429 classified as an ambiguous network error: %v", err)
+	}
+	if pages := fake.Pages(string(nc.dbID)); len(pages) != 0 {
+		t.Errorf("pages = %d, want 0", len(pages))
+	}
+}
+
+func TestMigrateSchemaIncludesExistingPagesInDigest(t *testing.T) {
+	_, nc := newFakeNotion(t)
+	ctx := context.Background()
+
+	// "Include in digest" 追加前（v8）のデータベースと、その時点からあるページ
+	_, err := nc.client.Database.Update(ctx, nc.dbID, &notionapi.DatabaseUpdateRequest{
+		Properties: notionapi.PropertyConfigs{EditorialIncludeProperty: nil},
+	})
+	if err != nil {
+		t.Fatalf("Database.Update: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := nc.client.Page.Create(ctx, &notionapi.PageCreateRequest{
+			Parent: notionapi.Parent{Type: notionapi.ParentTypeDatabaseID, DatabaseID: nc.dbID},
+			Properties: notionapi.Properties{
+				"Title": notionapi.TitleProperty{Title: []notionapi.RichText{{Text: &notionapi.Text{Content: fmt.Sprintf("Old page %d", i)}}}},
+				"URL":   notionapi.URLProperty{URL: fmt.Sprintf("https://example.com/old/%d", i)},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Page.Create: %v", err)
+		}
+	}
+
+	plan, err := nc.MigrateSchema(ctx, false)
+	if err != nil {
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	if plan.IncludedPages != 2 {
+		t.Errorf("IncludedPages = %d, want 2", plan.IncludedPages)
+	}
+
+	headlines, err := nc.FetchRecentHeadlines(ctx, 7)
+	if err != nil {
+		t.Fatalf("FetchRecentHeadlines: %v", err)
+	}
+	if kept, excluded := applyEditorialStatus(headlines); excluded != 0 || len(kept) != 2 {
+		t.Fatalf("applyEditorialStatus: kept %d, excluded %d; want existing pages included", len(kept), excluded)
+	}
+
+	// 編集者がチェックを外した記事だけ除外される
+	_, err = nc.client.Page.Update(ctx, notionapi.PageID(headlines[0].ID), &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{
+			EditorialIncludeProperty: notionapi.CheckboxProperty{Type: notionapi.PropertyTypeCheckbox, Checkbox: false},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Page.Update: %v", err)
+	}
+	headlines, err = nc.FetchRecentHeadlines(ctx, 7)
+	if err != nil {
+		t.Fatalf("FetchRecentHeadlines: %v", err)
+	}
+	if kept, excluded := applyEditorialStatus(headlines); excluded != 1 || len(kept) != 1 {
+		t.Errorf("applyEditorialStatus: kept %d, excluded %d; want 1 and 1", len(kept), excluded)
+	}
+}
@@ -0,0 +1,528 @@
+// =============================================================================
+// prices.go - カーボン価格・取引量の抽出
+// =============================================================================
+//
+// このファイルは記事本文から価格・取引量の記述を抽出し、型付きデータとして保存します。
+// Carbon Herald、Sandbag、ICAP、RGGIのオークション記事などから手作業で
+// 転記していた数値を自動で取り出すためのものです。
+//
+// 【抽出例】
+//
+//	"EUAs settled at €68.40"          → EUA  price  68.40 EUR/t
+//	"clearing price $21.03"           → RGGI price  21.03 USD/t（文中にRGGIがある場合）
+//	"1.2 MtCO2e retired"              → -    volume 1.2 MtCO2e（1,200,000 t）
+//	"排出枠は1トンあたり2,500円"      → -    price  2500 JPY/t
+//
+// 【保存先】
+//   - Headline.Prices（JSON出力の "prices"）
+//   - Notionの "Prices" プロパティ（1行1件のテキスト、PriceMention.String() 形式）
+//
+// 【時系列出力】
+//
+//	-priceSeries フラグで、期間内の抽出結果をCSV/JSONの時系列として出力する。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PriceMention は記事中の価格・取引量の記述を表す
+type PriceMention struct {
+	Kind       string  `json:"kind"`                 // "price" または "volume"
+	Instrument string  `json:"instrument,omitempty"` // EUA, UKA, CCA, RGGI, ACCU, VCU, NZU, KAU
+	Value      float64 `json:"value"`                // 数値（"million"・"万" 等の規模語は反映済み）
+	Currency   string  `json:"currency,omitempty"`   // EUR, GBP, USD, AUD, NZD, JPY（価格のみ）
+	Unit       string  `json:"unit"`                 // 価格: "t"、取引量: "tCO2e" / "MtCO2e" / "allowances" 等
+	Tonnes     float64 `json:"tonnes,omitempty"`     // 取引量をトン換算した値（換算できる場合）
+	Date       string  `json:"date,omitempty"`       // 文中の日付、なければ公開日（YYYY-MM-DD）
+	Context    string  `json:"context,omitempty"`    // 抽出元の文（最大200文字）
+}
+
+// String はNotion保存用の1行表現を返す
+//
+//	EUA price 68.4 EUR/t 2026-03-01
+//	VCU volume 1200000 tCO2e 2026-03-01
+func (p PriceMention) String() string {
+	instrument := p.Instrument
+	if instrument == "" {
+		instrument = "-"
+	}
+	unit := p.Unit
+	if p.Kind == "price" {
+		unit = p.Currency + "/" + p.Unit
+	}
+	date := p.Date
+	if date == "" {
+		date = "-"
+	}
+	return fmt.Sprintf("%s %s %s %s %s", instrument, p.Kind,
+		strconv.FormatFloat(p.Value, 'f', -1, 64), unit, date)
+}
+
+// parsePriceMention は String() 形式の1行を PriceMention に戻す
+func parsePriceMention(line string) (PriceMention, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return PriceMention{}, false
+	}
+	value, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return PriceMention{}, false
+	}
+	p := PriceMention{Kind: fields[1], Value: value, Unit: fields[3]}
+	if fields[0] != "-" {
+		p.Instrument = fields[0]
+	}
+	if fields[4] != "-" {
+		p.Date = fields[4]
+	}
+	if p.Kind == "price" {
+		if cur, unit, ok := strings.Cut(fields[3], "/"); ok {
+			p.Currency, p.Unit = cur, unit
+		}
+	} else {
+		p.Tonnes = volumeTonnes(value, "", p.Unit)
+	}
+	return p, true
+}
+
+// =============================================================================
+// 検出パターン
+// =============================================================================
+
+// reNumber は "68.40", "1,200,000", "2.5" 等の数値表現
+const reNumber = `(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)`
+
+// rePriceSymbol は通貨記号/コード + 数値（"€68.40", "US$ 21.03", "EUR 70"）
+var rePriceSymbol = regexp.MustCompile(`(US\$|A\$|AU\$|NZ\$|€|£|\$|¥|\b(?:EUR|GBP|USD|AUD|NZD|JPY)\s?)\s?` + reNumber +
+	`(\s?(?:million|billion|bn|mn|m|k)\b)?(\s?(?i:/|per)\s?(?i:t|tonne|ton|tco2e?)\b)?`)
+
+// rePriceWord は数値 + 通貨名・通貨コード（"68.40 Euros", "70 EUR per Tonne", "2,500円/t-CO2"）
+var rePriceWord = regexp.MustCompile(`(?i)` + reNumber +
+	`\s?(euros?|pounds?|dollars?|(?:eur|gbp|usd|aud|nzd|jpy)\b|円)(\s?(?:/|per)\s?(?:t|tonne|ton|t-?co2e?)\b)?`)
+
+// reVolume は数値 + 規模 + 単位（"1.2 MtCO2e", "3 million tonnes of CO2", "500,000 VCUs", "120万トン"）
+var reVolume = regexp.MustCompile(`(?i)` + reNumber +
+	`\s?(million|billion|thousand|mn\b|bn\b|m\b|k\b|万|億)?\s?` +
+	`(mtco2e?|ktco2e?|tco2e?|mt\b|tonnes?(?: of (?:co2e?|carbon dioxide))?|tons?(?: of (?:co2e?|carbon dioxide))?|` +
+	`allowances|credits|vcus|accus|euas|ukas|ccas|トン)`)
+
+// reYearLike は年号と区別できない数値（区切りなしの 19xx/20xx）
+var reYearLike = regexp.MustCompile(`^(?:19|20)\d{2}$`)
+
+// priceContextWords は価格の記述であることを示す語（金額全般を誤検出しないため）
+var rePriceContext = regexp.MustCompile(`(?i)price|settled|clearing|auction|traded|trading|allowance|credit|per (?:t|tonne|ton)|/t\b|価格|取引|オークション|円/t`)
+
+// instrumentPatterns は取引対象（排出枠・クレジット種別）の検出パターン
+var instrumentPatterns = []namedPattern{
+	{"EUA", regexp.MustCompile(`(?i)\bEUAs?\b|EU (?:carbon )?allowances?|\bEU[\s-]?ETS\b`)},
+	{"UKA", regexp.MustCompile(`(?i)\bUKAs?\b|UK (?:carbon )?allowances?|\bUK[\s-]?ETS\b`)},
+	{"CCA", regexp.MustCompile(`\bCCAs?\b|(?i)california (?:carbon )?allowances?|\bCARB\b`)},
+	{"RGGI", regexp.MustCompile(`(?i)\bRGGI\b`)},
+	{"ACCU", regexp.MustCompile(`(?i)\bACCUs?\b|australian carbon credit units?`)},
+	{"VCU", regexp.MustCompile(`(?i)\bVCUs?\b|verified carbon units?`)},
+	{"NZU", regexp.MustCompile(`(?i)\bNZUs?\b|\bNZ[\s-]?ETS\b`)},
+	{"KAU", regexp.MustCompile(`(?i)\bKAUs?\b|\bK-?ETS\b`)},
+}
+
+// currencySymbols は通貨記号・語 → ISOコード
+var currencySymbols = map[string]string{
+	"€": "EUR", "£": "GBP", "$": "USD", "US$": "USD", "A$": "AUD", "AU$": "AUD", "NZ$": "NZD", "¥": "JPY",
+	"EUR": "EUR", "GBP": "GBP", "USD": "USD", "AUD": "AUD", "NZD": "NZD", "JPY": "JPY",
+	"euro": "EUR", "euros": "EUR", "pound": "GBP", "pounds": "GBP", "dollar": "USD", "dollars": "USD", "円": "JPY",
+}
+
+// currencyFromWord は通貨名・通貨コードを大文字小文字を問わず通貨コードに変換する
+func currencyFromWord(word string) string {
+	if c, ok := currencySymbols[strings.ToLower(word)]; ok {
+		return c
+	}
+	return currencySymbols[strings.ToUpper(word)]
+}
+
+// reDateEnglish は "12 March 2026" / "March 12, 2026" 形式の日付
+var reDateEnglish = regexp.MustCompile(`(?i)\b(\d{1,2} (?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{4}|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{1,2},? \d{4})\b`)
+
+// reDateISO は "2026-03-12" 形式の日付
+var reDateISO = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2})\b`)
+
+// =============================================================================
+// 抽出
+// =============================================================================
+
+// ExtractPrices はタイトルと本文から価格・取引量の記述を抽出する
+//
+// publishedAt は文中に日付がない場合の既定日付として使用する。
+func ExtractPrices(title, excerpt, publishedAt string) []PriceMention {
+	defaultDate := ""
+	if t, err := parsePublishedDate(publishedAt); err == nil {
+		defaultDate = t.Format("2006-01-02")
+	}
+
+	// 文中に取引対象がない場合はタイトルの取引対象を使う（"RGGI Auction 68" 等）
+	titleInstrument := nearestInstrument(title, len(title))
+
+	var out []PriceMention
+	seen := map[string]bool{}
+	for _, sentence := range splitSentences(title + "\n" + excerpt) {
+		date := sentenceDate(sentence)
+		if date == "" {
+			date = defaultDate
+		}
+		for _, p := range extractSentencePrices(sentence) {
+			p.Date = date
+			if p.Instrument == "" {
+				p.Instrument = titleInstrument
+			}
+			p.Context = truncateString(strings.TrimSpace(sentence), 200)
+			key := p.String()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// extractSentencePrices は1文から価格・取引量を抽出する
+func extractSentencePrices(sentence string) []PriceMention {
+	var out []PriceMention
+	hasPriceContext := rePriceContext.MatchString(sentence)
+
+	// 通貨記号 + 数値
+	for _, m := range rePriceSymbol.FindAllStringSubmatchIndex(sentence, -1) {
+		symbol := strings.TrimSpace(sentence[m[2]:m[3]])
+		scale := submatch(sentence, m, 3)
+		perTonne := submatch(sentence, m, 4)
+		// "$2 billion" のような金額は価格ではない
+		if strings.TrimSpace(scale) != "" {
+			continue
+		}
+		if perTonne == "" && !hasPriceContext {
+			continue
+		}
+		value, ok := parseNumber(sentence[m[4]:m[5]])
+		if !ok {
+			continue
+		}
+		out = append(out, PriceMention{
+			Kind:       "price",
+			Instrument: nearestInstrument(sentence, m[0]),
+			Value:      value,
+			Currency:   currencySymbols[symbol],
+			Unit:       "t",
+		})
+	}
+
+	// 数値 + 通貨名
+	for _, m := range rePriceWord.FindAllStringSubmatchIndex(sentence, -1) {
+		perTonne := submatch(sentence, m, 3)
+		if perTonne == "" && !hasPriceContext {
+			continue
+		}
+		value, ok := parseNumber(sentence[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		out = append(out, PriceMention{
+			Kind:       "price",
+			Instrument: nearestInstrument(sentence, m[0]),
+			Value:      value,
+			Currency:   currencyFromWord(sentence[m[4]:m[5]]),
+			Unit:       "t",
+		})
+	}
+
+	// 取引量
+	for _, m := range reVolume.FindAllStringSubmatchIndex(sentence, -1) {
+		value, ok := parseNumber(sentence[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		// "1トンあたり" は単価の記述
+		if rest := sentence[m[1]:]; strings.HasPrefix(rest, "あたり") || strings.HasPrefix(rest, "当たり") {
+			continue
+		}
+		scale := strings.ToLower(submatch(sentence, m, 2))
+		unit := normalizeVolumeUnit(sentence[m[6]:m[7]])
+		// 規模語のない "2024 allowances"、"2030 tonnes" 等は年号の可能性が高いためスキップ
+		if scale == "" && reYearLike.MatchString(sentence[m[2]:m[3]]) {
+			continue
+		}
+		// 単位なしの "tons" 等は規模語がない場合、年号などの誤検出を避けるためスキップ
+		if scale == "" && (unit == "t" || unit == "allowances" || unit == "credits") && value < 1000 && !hasPriceContext {
+			continue
+		}
+		out = append(out, PriceMention{
+			Kind:       "volume",
+			Instrument: nearestInstrument(sentence, m[0]),
+			Value:      scaleValue(value, scale),
+			Unit:       unit,
+			Tonnes:     volumeTonnes(value, scale, unit),
+		})
+	}
+	return out
+}
+
+// submatch はサブマッチ i の文字列を返す（マッチしなかった場合は空文字）
+func submatch(s string, m []int, i int) string {
+	if 2*i+1 >= len(m) || m[2*i] < 0 {
+		return ""
+	}
+	return s[m[2*i]:m[2*i+1]]
+}
+
+// nearestInstrument は位置 pos に最も近い取引対象名を返す（前方を優先）
+func nearestInstrument(sentence string, pos int) string {
+	best := ""
+	bestDist := -1
+	for _, p := range instrumentPatterns {
+		for _, loc := range p.re.FindAllStringIndex(sentence, -1) {
+			dist := pos - loc[1]
+			if dist < 0 {
+				// 後方の出現は距離を2倍にして前方を優先
+				dist = (loc[0] - pos) * 2
+			}
+			if bestDist < 0 || dist < bestDist {
+				best, bestDist = p.name, dist
+			}
+		}
+	}
+	return best
+}
+
+// parseNumber は "1,200.5" 形式の数値をパースする
+func parseNumber(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	return v, err == nil
+}
+
+// scaleValue は規模語（million、万 等）を数値に反映する
+func scaleValue(v float64, scale string) float64 {
+	switch scale {
+	case "thousand", "k":
+		return v * 1e3
+	case "万":
+		return v * 1e4
+	case "million", "mn", "m":
+		return v * 1e6
+	case "億":
+		return v * 1e8
+	case "billion", "bn":
+		return v * 1e9
+	}
+	return v
+}
+
+// normalizeVolumeUnit は取引量の単位を正規化する
+func normalizeVolumeUnit(u string) string {
+	u = strings.ToLower(u)
+	switch {
+	case strings.HasPrefix(u, "mtco2"):
+		return "MtCO2e"
+	case strings.HasPrefix(u, "ktco2"):
+		return "ktCO2e"
+	case strings.HasPrefix(u, "tco2"):
+		return "tCO2e"
+	case u == "mt":
+		return "Mt"
+	case strings.HasPrefix(u, "tonne"), strings.HasPrefix(u, "ton"), u == "トン":
+		return "t"
+	}
+	return u
+}
+
+// volumeTonnes は取引量をトン換算する（換算できない単位は0）
+func volumeTonnes(value float64, scale, unit string) float64 {
+	v := scaleValue(value, scale)
+	switch unit {
+	case "MtCO2e", "Mt":
+		return v * 1e6
+	case "ktCO2e":
+		return v * 1e3
+	case "tCO2e", "t", "allowances", "credits", "vcus", "accus", "euas", "ukas", "ccas":
+		// 排出枠・クレジットは1単位 = 1 tCO2e
+		return v
+	}
+	return 0
+}
+
+// sentenceDate は文中の日付を YYYY-MM-DD で返す（見つからない場合は空文字）
+func sentenceDate(sentence string) string {
+	if m := reDateISO.FindString(sentence); m != "" {
+		return m
+	}
+	if m := reDateEnglish.FindString(sentence); m != "" {
+		for _, layout := range []string{"2 January 2006", "2 Jan 2006", "January 2, 2006", "January 2 2006", "Jan 2, 2006", "Jan 2 2006"} {
+			if t, err := time.Parse(layout, m); err == nil {
+				return t.Format("2006-01-02")
+			}
+		}
+	}
+	if m := reJapaneseDateYMD.FindStringSubmatch(sentence); m != nil {
+		return fmt.Sprintf("%s-%02d-%02d", m[1], atoi(m[2]), atoi(m[3]))
+	}
+	return ""
+}
+
+// splitSentences はテキストを文に分割する（英語 ". " / 日本語 "。"）
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		end := false
+		switch r {
+		case '。', '！', '？', '\n':
+			end = true
+		case '.', '!', '?':
+			// 小数点（"68.40"）は文末ではない
+			end = i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\n'
+		}
+		if end {
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// =============================================================================
+// 時系列出力
+// =============================================================================
+
+// PriceSeriesPoint は時系列の1点（抽出元の記事情報付き）
+type PriceSeriesPoint struct {
+	PriceMention
+	Source string `json:"source"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// BuildPriceSeries は記事群から期間内の価格・取引量を日付順の時系列にまとめる
+//
+// from/to はゼロ値の場合は無制限。どちらかを指定した場合、日付がない・解釈できない記述は
+// 期間内か判定できないため除外する。instrument が空でない場合はその取引対象のみ。
+func BuildPriceSeries(headlines []NotionHeadline, from, to time.Time, instrument string) []PriceSeriesPoint {
+	bounded := !from.IsZero() || !to.IsZero()
+	var points []PriceSeriesPoint
+	for _, h := range headlines {
+		for _, p := range h.Prices {
+			if instrument != "" && !strings.EqualFold(p.Instrument, instrument) {
+				continue
+			}
+			if bounded {
+				d, err := time.Parse("2006-01-02", p.Date)
+				if err != nil {
+					continue
+				}
+				if (!from.IsZero() && d.Before(from)) || (!to.IsZero() && d.After(to)) {
+					continue
+				}
+			}
+			points = append(points, PriceSeriesPoint{PriceMention: p, Source: h.Source, Title: h.Title, URL: h.URL})
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].Date != points[j].Date {
+			return points[i].Date < points[j].Date
+		}
+		return points[i].Instrument < points[j].Instrument
+	})
+	return points
+}
+
+// WritePriceSeries は時系列をCSVまたはJSONで書き出す
+func WritePriceSeries(w io.Writer, points []PriceSeriesPoint, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(points)
+	case "csv", "":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"date", "instrument", "kind", "value", "currency", "unit", "tonnes", "source", "title", "url"})
+		for _, p := range points {
+			_ = cw.Write([]string{
+				p.Date, p.Instrument, p.Kind,
+				strconv.FormatFloat(p.Value, 'f', -1, 64),
+				p.Currency, p.Unit,
+				strconv.FormatFloat(p.Tonnes, 'f', -1, 64),
+				p.Source, p.Title, p.URL,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown price series format: %s (use csv or json)", format)
+}
+
+// HandlePriceSeries は -priceSeries モードのハンドラ
+//
+// headlinesFile が指定された場合はJSONファイル（カンマ区切りで複数可）から、
+// 指定されていない場合はストア（NotionDBまたはファイル、store.go）から期間内の記事を読み込む。
+func HandlePriceSeries(cfg *PriceSeriesConfig, storeCfg *StoreConfig, headlinesFile, outFile string) {
+	from, to, err := cfg.Range()
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+
+	var headlines []NotionHeadline
+	if headlinesFile != "" {
+		for _, path := range strings.Split(headlinesFile, ",") {
+			var hs []Headline
+			if err := readJSONFile(strings.TrimSpace(path), &hs); err != nil {
+				fatalf("reading headlines: %v", err)
+			}
+			for _, h := range hs {
+				nh := NotionHeadline{Title: h.Title, URL: h.URL, Source: h.Source, PublishedDate: h.PublishedAt}
+				nh.Prices = h.Prices
+				if nh.Prices == nil {
+					nh.Prices = ExtractPrices(h.Title, h.Excerpt, h.PublishedAt)
+				}
+				headlines = append(headlines, nh)
+			}
+		}
+	} else {
+		// 期間の開始日から現在までを取得（作成日ベース）
+		daysBack := 30
+		if !from.IsZero() {
+			daysBack = int(time.Since(from).Hours()/24) + 1
+		}
+		headlines = fetchStoredHeadlines(openHeadlineStore(storeCfg), daysBack)
+	}
+
+	points := BuildPriceSeries(headlines, from, to, cfg.Instrument)
+	fmt.Fprintf(os.Stderr, "Price series: %d points from %d headlines\n", len(points), len(headlines))
+
+	w := io.Writer(os.Stdout)
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			fatalf("creating output: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := WritePriceSeries(w, points, cfg.Format); err != nil {
+		fatalf("writing price series: %v", err)
+	}
+}
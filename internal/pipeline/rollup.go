@@ -0,0 +1,427 @@
+// =============================================================================
+// rollup.go - 週次・月次のまとめ（統計付きダイジェスト）
+// =============================================================================
+//
+// このファイルは一定期間にストアへ保存された記事を集計し、まとめメールを生成します。
+// 日次のダイジェスト（DaysBack=1）とは別に、経営層向けに週・月単位の動向を伝えるためのものです。
+//
+// 【期間】（-rollup / Lambdaのイベント {"rollup": "weekly"}）
+//
+//	weekly  - 終了日の前日までの7日間（月曜に実行すると前週の月〜日）
+//	monthly - 終了日の前日までの1か月間（1日に実行すると前月）
+//
+//   終了日は実行日（-rollupEnd / イベントの "rollupEnd" で YYYY-MM-DD を指定すると、その日の前日まで）。
+//   記事は作成日時（ストアに保存した日時）で期間に含めるかを判定する。
+//
+// 【集計内容】
+//
+//	ソース別・トピック別の記事数
+//	よく報じられた話題     - 似た見出しの記事をまとめたクラスタの大きい順（最大10件、2件以上のもの）
+//	レジストリの発表       - 認証団体（Verra・Gold Standard 等）のサイトから収集した記事
+//	価格                  - 抽出した価格（prices.go）を取引対象・通貨ごとに件数・最安・最高・最新で集計
+//
+// 【クラスタ】
+//   見出しの語（summaryTokens、日本語は文字バイグラム）が3語以上かつ短い方の60%以上重なる記事、
+//   または同じプロジェクトIDを含む記事を同じ話題とみなす。
+//   代表の記事はスコア（digestScore）の最も高いもの。
+//
+// 【テンプレート】
+//   templates/rollup.txt.tmpl / rollup.html.tmpl（EMAIL_TEMPLATE_DIR で差し替え可能、RollupView が渡される）
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// まとめの期間の種類
+const (
+	RollupWeekly  = "weekly"
+	RollupMonthly = "monthly"
+)
+
+// DigestTemplateRollup はまとめメールのテンプレート名
+const DigestTemplateRollup = "rollup"
+
+// rollupMaxStories は「よく報じられた話題」に載せる最大件数
+const rollupMaxStories = 10
+
+// registrySources はレジストリの発表として扱うソース（VCM認証団体・CDRレジストリ）
+var registrySources = []string{"Verra", "Gold Standard", "ACR", "Climate Action Reserve", "Puro.earth", "Isometric"}
+
+// RollupPeriod はまとめの期間 [From, To)
+type RollupPeriod struct {
+	Kind string // weekly / monthly
+	From time.Time
+	To   time.Time
+}
+
+// ParseRollupPeriod は期間の種類と終了日（YYYY-MM-DD、空の場合は now の日付）から期間を作る
+//
+// 終了日の0時を期間の終わり（含まない）とする。
+func ParseRollupPeriod(kind, end string, now time.Time) (RollupPeriod, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if end = strings.TrimSpace(end); end != "" {
+		d, err := time.ParseInLocation("2006-01-02", end, now.Location())
+		if err != nil {
+			return RollupPeriod{}, fmt.Errorf("invalid roll-up end date %q (use YYYY-MM-DD)", end)
+		}
+		to = d
+	}
+
+	p := RollupPeriod{Kind: kind, To: to}
+	switch kind {
+	case RollupWeekly:
+		p.From = to.AddDate(0, 0, -7)
+	case RollupMonthly:
+		p.From = to.AddDate(0, -1, 0)
+	default:
+		return RollupPeriod{}, fmt.Errorf("unsupported roll-up period %q (use weekly or monthly)", kind)
+	}
+	return p, nil
+}
+
+// Label は期間の表示（例: "2026-10-05 〜 2026-10-11"、終わりの日を含む）
+func (p RollupPeriod) Label() string {
+	return p.From.Format("2006-01-02") + " 〜 " + p.To.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// Title はまとめの名前（週次まとめ / 月次まとめ）
+func (p RollupPeriod) Title() string {
+	if p.Kind == RollupMonthly {
+		return "月次まとめ"
+	}
+	return "週次まとめ"
+}
+
+// Query は期間内に作成された記事を新しい順に返す検索条件
+func (p RollupPeriod) Query() HeadlineQuery {
+	return HeadlineQuery{CreatedOnOrAfter: p.From, CreatedBefore: p.To, Sort: HeadlineSortCreatedDesc}
+}
+
+// =============================================================================
+// 集計
+// =============================================================================
+
+// RollupView はまとめメールのテンプレートに渡すデータ
+type RollupView struct {
+	Heading    string // 見出し（例: "炭素関連 週次まとめ"）
+	Period     string // 期間（例: "2026-10-05 〜 2026-10-11"）
+	Generated  string // 生成日時
+	Total      int    // 記事数
+	Empty      bool   // 記事が0件の場合にtrue
+	Sources    []RollupCount
+	Topics     []RollupCount
+	Stories    []RollupStory   // よく報じられた話題（クラスタの大きい順）
+	Registries []RollupArticle // レジストリの発表
+	Prices     []RollupPrice   // 取引対象・通貨ごとの価格
+	Mentions   int             // 価格・取引量の記述の総数
+}
+
+// RollupCount はソース・トピックごとの記事数
+type RollupCount struct {
+	Label string
+	Count int
+}
+
+// RollupStory は同じ話題を扱った記事のまとまり
+type RollupStory struct {
+	RollupArticle          // 代表の記事
+	Size          int      // 記事数
+	OtherSources  []string // 代表以外のソース（重複なし）
+}
+
+// RollupArticle はまとめに載せる記事
+type RollupArticle struct {
+	Title   string
+	URL     string
+	Source  string
+	Date    string // 公開日（なければ作成日、YYYY-MM-DD）
+	Summary string
+}
+
+// RollupPrice は取引対象・通貨ごとの価格の集計
+type RollupPrice struct {
+	Instrument string
+	Currency   string
+	Unit       string
+	Count      int
+	Low        float64
+	High       float64
+	Latest     float64
+	LatestDate string
+}
+
+// BuildRollup は記事を集計してまとめのデータを作る
+func BuildRollup(headlines []NotionHeadline, period RollupPeriod) *RollupView {
+	view := &RollupView{
+		Heading:   "炭素関連 " + period.Title(),
+		Period:    period.Label(),
+		Generated: time.Now().Format("2006-01-02 15:04:05"),
+		Total:     len(headlines),
+		Empty:     len(headlines) == 0,
+	}
+
+	sources := map[string]int{}
+	topics := map[string]int{}
+	for _, h := range headlines {
+		sources[h.Source]++
+		if len(h.Topics) == 0 {
+			topics[noTopicLabel]++
+		}
+		for _, t := range h.Topics {
+			topics[t]++
+		}
+		if containsString(registrySources, h.Source) {
+			view.Registries = append(view.Registries, newRollupArticle(h))
+		}
+		view.Mentions += len(h.Prices)
+	}
+	view.Sources = sortedCounts(sources)
+	view.Topics = sortedCounts(topics)
+	view.Stories = topStories(headlines, period.To)
+	view.Prices = summarizePrices(BuildPriceSeries(headlines, period.From, period.To.AddDate(0, 0, -1), ""))
+	return view
+}
+
+// sortedCounts は件数の多い順（同数は名前順）に並べる
+func sortedCounts(counts map[string]int) []RollupCount {
+	out := make([]RollupCount, 0, len(counts))
+	for label, n := range counts {
+		out = append(out, RollupCount{Label: label, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Label < out[j].Label
+	})
+	return out
+}
+
+// newRollupArticle はヘッドラインをまとめ用の記事に変換する
+func newRollupArticle(h NotionHeadline) RollupArticle {
+	a := RollupArticle{Title: h.Title, URL: h.URL, Source: h.Source, Summary: h.ShortHeadline}
+	if isUnsummarized(h) || strings.Trim(a.Summary, "-−— ") == "" {
+		a.Summary = ""
+	}
+	if t := headlineTime(h); !t.IsZero() {
+		a.Date = t.Format("2006-01-02")
+	}
+	return a
+}
+
+// topStories は2件以上の記事からなるクラスタを大きい順に返す（最大 rollupMaxStories 件）
+func topStories(headlines []NotionHeadline, now time.Time) []RollupStory {
+	var stories []RollupStory
+	for _, cluster := range clusterHeadlines(headlines) {
+		if len(cluster) < 2 {
+			continue
+		}
+		members := make([]NotionHeadline, len(cluster))
+		for i, k := range cluster {
+			members[i] = headlines[k]
+		}
+		rep := members[rankByScore(members, now)[0]]
+		story := RollupStory{RollupArticle: newRollupArticle(rep), Size: len(members)}
+		for _, h := range members {
+			if h.Source != rep.Source && !containsString(story.OtherSources, h.Source) {
+				story.OtherSources = append(story.OtherSources, h.Source)
+			}
+		}
+		sort.Strings(story.OtherSources)
+		stories = append(stories, story)
+	}
+
+	sort.SliceStable(stories, func(i, j int) bool {
+		if stories[i].Size != stories[j].Size {
+			return stories[i].Size > stories[j].Size
+		}
+		return len(stories[i].OtherSources) > len(stories[j].OtherSources)
+	})
+	if len(stories) > rollupMaxStories {
+		stories = stories[:rollupMaxStories]
+	}
+	return stories
+}
+
+// clusterHeadlines は同じ話題の記事をまとめ、インデックスのクラスタを返す（ファイル冒頭の【クラスタ】参照）
+func clusterHeadlines(headlines []NotionHeadline) [][]int {
+	n := len(headlines)
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	tokens := make([]map[string]bool, n)
+	for i, h := range headlines {
+		tokens[i] = summaryTokens(h.Title)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if similarTitles(tokens[i], tokens[j]) || sharesProjectID(headlines[i], headlines[j]) {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	var roots []int
+	for i := 0; i < n; i++ {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], i)
+	}
+	clusters := make([][]int, len(roots))
+	for i, r := range roots {
+		clusters[i] = groups[r]
+	}
+	return clusters
+}
+
+// similarTitles は見出しの語が3語以上、かつ短い方の60%以上重なるかを返す
+func similarTitles(a, b map[string]bool) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	overlap := 0
+	for t := range a {
+		if b[t] {
+			overlap++
+		}
+	}
+	shorter := math.Min(float64(len(a)), float64(len(b)))
+	return overlap >= 3 && float64(overlap) >= 0.6*shorter
+}
+
+// sharesProjectID は2つの記事が同じプロジェクトIDを含むかを返す
+func sharesProjectID(a, b NotionHeadline) bool {
+	if a.Entities == nil || b.Entities == nil {
+		return false
+	}
+	for _, id := range a.Entities.ProjectIDs {
+		if containsString(b.Entities.ProjectIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizePrices は価格を取引対象・通貨ごとに集計する（取引対象が不明な価格・取引量は除く）
+func summarizePrices(points []PriceSeriesPoint) []RollupPrice {
+	byKey := map[string]*RollupPrice{}
+	var keys []string
+	for _, p := range points {
+		if p.Kind != "price" || p.Instrument == "" {
+			continue
+		}
+		key := p.Instrument + " " + p.Currency
+		s, ok := byKey[key]
+		if !ok {
+			s = &RollupPrice{Instrument: p.Instrument, Currency: p.Currency, Unit: p.Unit, Low: p.Value, High: p.Value}
+			byKey[key] = s
+			keys = append(keys, key)
+		}
+		s.Count++
+		s.Low = math.Min(s.Low, p.Value)
+		s.High = math.Max(s.High, p.Value)
+		// points は日付順のため、後のものほど新しい
+		s.Latest, s.LatestDate = p.Value, p.Date
+	}
+	sort.Strings(keys)
+	out := make([]RollupPrice, len(keys))
+	for i, key := range keys {
+		out[i] = *byKey[key]
+	}
+	return out
+}
+
+// =============================================================================
+// 送信
+// =============================================================================
+
+// SendRollup はまとめメールを送信する
+//
+// 編集者が除外した記事と、受信者の言語設定（EMAIL_LANGUAGE）と異なる記事は集計に含めない。
+func (es *EmailSender) SendRollup(ctx context.Context, headlines []NotionHeadline, period RollupPeriod) error {
+	digest, err := es.renderRollup(headlines, period)
+	if err != nil {
+		return err
+	}
+	return es.SendWithRetry(ctx, es.BuildMultipartMessage(digest.Subject, digest.Text, digest.HTML))
+}
+
+// renderRollup はまとめメールの件名と本文を生成する
+func (es *EmailSender) renderRollup(headlines []NotionHeadline, period RollupPeriod) (*renderedDigest, error) {
+	headlines, excluded := applyEditorialStatus(headlines)
+	kept := headlines[:0]
+	for _, h := range headlines {
+		if es.config.Language == "" || h.Language == es.config.Language {
+			kept = append(kept, h)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Roll-up %s: %d articles (skipped: %d excluded by editor, %d other language)\n",
+		period.Label(), len(kept), excluded, len(headlines)-len(kept))
+	applyExtractedSummaries(kept)
+
+	view := BuildRollup(kept, period)
+	subject := fmt.Sprintf("%s - %s (%d 記事)", view.Heading, view.Period, view.Total)
+	text, html, err := renderDigest(es.config.TemplateDir, DigestTemplateRollup, view)
+	if err != nil {
+		return nil, err
+	}
+	return &renderedDigest{Subject: subject, Text: text, HTML: html}, nil
+}
+
+// HandleRollupSend は -rollup モードのハンドラ（EMAIL_TO にまとめメールを1通送信する）
+func HandleRollupSend(cfg *EmailModeConfig, storeCfg *StoreConfig) {
+	fmt.Fprintln(os.Stderr, "\n========================================")
+	fmt.Fprintln(os.Stderr, "📊 Sending Roll-up Digest")
+	fmt.Fprintln(os.Stderr, "========================================")
+
+	period, err := ParseRollupPeriod(cfg.Rollup, cfg.RollupEnd, time.Now())
+	if err != nil {
+		fatalf("ERROR: %v", err)
+	}
+
+	store := openHeadlineStore(storeCfg)
+	headlines, err := store.QueryHeadlines(context.Background(), period.Query())
+	if err != nil {
+		fatalf("ERROR fetching headlines from %s: %v", store.Name(), err)
+	}
+	fmt.Fprintf(os.Stderr, "Fetched %d headlines from %s (%s)\n", len(headlines), store.Name(), period.Label())
+
+	sender, from, to := createEmailSender()
+	if err := sender.SetLanguage(cfg.Language); err != nil {
+		fatalf("ERROR: %v", err)
+	}
+	sender.SetTemplateDir(cfg.TemplateDir)
+	if err := sender.SendRollup(context.Background(), headlines, period); err != nil {
+		fatalf("ERROR sending email: %v", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "✅ Roll-up digest email sent successfully")
+	fmt.Fprintf(os.Stderr, "   From: %s\n", from)
+	fmt.Fprintf(os.Stderr, "   To: %s\n", to)
+	fmt.Fprintln(os.Stderr, "========================================")
+}
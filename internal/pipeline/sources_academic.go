@@ -69,20 +69,24 @@ type arXivLink struct {
 // carbonKeywordsArXiv は arXiv論文の関連性を確認するためのキーワードリスト
 // 物理学論文での誤検知を避けるため複合フレーズを使用
 // （例: "emission" 単体では "positron emission", "light emission" 等にマッチしてしまう）
+// 照合は単語境界で行うため、派生語まで拾う語は末尾 "*" で前方一致にしている（filter.go 参照）。
 var carbonKeywordsArXiv = []string{
 	// 気候変動に特化した複合用語
 	"carbon emission", "carbon dioxide", "co2 emission", "greenhouse gas",
 	"carbon pricing", "carbon tax", "carbon market", "carbon credit",
 	"emissions trading", "cap and trade", "carbon trading",
 	"climate change", "climate policy", "global warming",
-	"decarbonization", "decarbonisation", "net-zero", "net zero", "carbon neutral",
+	"decarboni*", "net-zero", "net zero", "carbon neutral*",
 	"renewable energy", "clean energy", "energy transition",
 	"carbon capture", "carbon storage", "carbon sequestration",
-	"carbon footprint", "carbon intensity",
+	"carbon footprint", "carbon intensit*",
 	// 国際協定
 	"paris agreement", "kyoto protocol",
 }
 
+// carbonFilterArXiv は carbonKeywordsArXiv から作成したフィルタ（記事ごとに作り直さない）
+var carbonFilterArXiv = NewKeywordFilter(carbonKeywordsArXiv)
+
 // collectHeadlinesArXiv は arXiv APIを使用してカーボン関連論文を取得する
 //
 // APIドキュメント: https://info.arxiv.org/help/api/index.html
@@ -161,7 +165,7 @@ func collectHeadlinesArXiv(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 		summaryClean = strings.Join(strings.Fields(summaryClean), " ")
 
 		// キーワードフィルタを適用して論文が実際にカーボン/気候関連か確認
-		if !matchesKeywords(title, summaryClean, carbonKeywordsArXiv) {
+		if !carbonFilterArXiv.Match(title, summaryClean) {
 			continue
 		}
 
@@ -206,6 +210,7 @@ func collectHeadlinesArXiv(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     authors,
 
 		})
 	}
@@ -225,12 +230,14 @@ func collectHeadlinesArXiv(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 // =============================================================================
 
 // carbonKeywordsNature は Nature Communications記事のフィルタリング用キーワードリスト
+// 照合は単語境界で行うため、"CO2e"・"offsetting" 等の派生語は個別に列挙している。
+// "carbon*" のような前方一致は "carbonated" 等に一致するため使わない（"-" は境界なので "low-carbon" は "carbon" で拾える）。
 var carbonKeywordsNature = []string{
-	"carbon", "emission", "greenhouse", "climate change", "net zero",
-	"decarbonization", "decarbonisation", "carbon dioxide", "CO2",
+	"carbon", "low-carbon", "carbon-neutral", "emission", "greenhouse", "climate change", "net zero",
+	"decarboni*", "carbon dioxide", "CO2", "CO2e", "tCO2", "tCO2e", "MtCO2", "MtCO2e",
 	"carbon pricing", "carbon tax", "cap and trade", "emissions trading",
-	"carbon market", "carbon credit", "offset", "sequestration",
-	"carbon capture", "CCS", "CCUS", "negative emissions",
+	"carbon market", "carbon credit", "offset", "offsetting", "sequestrat*",
+	"carbon capture", "CCS", "CCUS", "negative emissions", "hydrocarbon",
 }
 
 // collectHeadlinesNatureComms は Nature Communications RSSから気候関連記事を取得する
@@ -296,6 +303,7 @@ func collectHeadlinesNatureComms(limit int, cfg HeadlineSourceConfig) ([]Headlin
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -612,16 +620,23 @@ func parseOIESDate(text string) string {
 // carbonKeywordsAcademic は学術ジャーナル記事のフィルタリング用キーワードリスト。
 // カーボン/気候トピックへの関連性を確認する。IOP Science (ERL)、
 // Nature Ecology & Evolution、ScienceDirectソースで共有。
+//
+// 照合は単語境界で行い複数形も許容するため（filter.go 参照）、"CO2e"・"tCO2e"・"offsetting" 等の
+// 派生語は個別に列挙している。"carbon*"・"emission*"・"CO2*" のような前方一致は "carbonated" 等の
+// 無関係な語に一致するため使わない（"low-carbon"・"carbon-neutral" は "-" が境界なので "carbon" で一致する）。
 var carbonKeywordsAcademic = []string{
-	"carbon", "emission", "greenhouse", "climate change", "net zero",
-	"decarbonization", "decarbonisation", "carbon dioxide", "CO2",
+	"carbon", "low-carbon", "carbon-neutral", "emission", "greenhouse", "climate change", "net zero",
+	"decarboni*", "carbon dioxide", "CO2", "CO2e", "tCO2", "tCO2e", "MtCO2", "MtCO2e",
 	"carbon pricing", "carbon tax", "cap and trade", "emissions trading",
-	"carbon market", "carbon credit", "offset", "sequestration",
-	"carbon capture", "CCS", "CCUS", "negative emissions",
+	"carbon market", "carbon credit", "offset", "offsetting", "sequestrat*",
+	"carbon capture", "CCS", "CCUS", "negative emissions", "hydrocarbon",
 	"global warming", "climate policy", "paris agreement",
 	"renewable energy", "energy transition", "fossil fuel",
 }
 
+// carbonFilterAcademic は carbonKeywordsAcademic から作成したフィルタ（記事ごとに作り直さない）
+var carbonFilterAcademic = NewKeywordFilter(carbonKeywordsAcademic)
+
 // =============================================================================
 // IOP Science (Environmental Research Letters) ソース
 // =============================================================================
@@ -658,7 +673,7 @@ func collectHeadlinesIOPScience(limit int, cfg HeadlineSourceConfig) ([]Headline
 		excerpt := extractRSSExcerpt(item)
 
 		// キーワードフィルタ - ERLは幅広い環境科学をカバー
-		if !matchesKeywords(title, excerpt, carbonKeywordsAcademic) {
+		if !carbonFilterAcademic.Match(title, excerpt) {
 			continue
 		}
 
@@ -678,6 +693,7 @@ func collectHeadlinesIOPScience(limit int, cfg HeadlineSourceConfig) ([]Headline
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -761,7 +777,7 @@ func collectHeadlinesNatureEcoEvo(limit int, cfg HeadlineSourceConfig) ([]Headli
 		excerpt := extractRSSExcerpt(item)
 
 		// キーワードフィルタ
-		if !matchesKeywords(title, excerpt, carbonKeywordsAcademic) {
+		if !carbonFilterAcademic.Match(title, excerpt) {
 			continue
 		}
 
@@ -781,6 +797,7 @@ func collectHeadlinesNatureEcoEvo(limit int, cfg HeadlineSourceConfig) ([]Headli
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -828,7 +845,7 @@ func collectHeadlinesScienceDirect(limit int, cfg HeadlineSourceConfig) ([]Headl
 		excerpt := extractRSSExcerpt(item)
 
 		// キーワードフィルタ
-		if !matchesKeywords(title, excerpt, carbonKeywordsAcademic) {
+		if !carbonFilterAcademic.Match(title, excerpt) {
 			continue
 		}
 
@@ -858,6 +875,7 @@ func collectHeadlinesScienceDirect(limit int, cfg HeadlineSourceConfig) ([]Headl
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
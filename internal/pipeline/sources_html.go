@@ -741,6 +741,7 @@ func collectHeadlinesVerra(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -1812,6 +1813,7 @@ func collectHeadlinesPuroEarth(limit int, cfg HeadlineSourceConfig) ([]Headline,
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -38,15 +38,18 @@ import (
 //   - 温室効果ガス: CO2、温室効果ガス、GHG
 //   - 市場/取引: 排出量取引、ETS、カーボンプライシング、カーボンクレジット
 //   - 気候変動: 気候変動、クライメート
-//   - 英語キーワード: carbon, climate（英語混在記事用）
+//   - 英語キーワード: carbon, climate（英語混在記事用。"carbonated" に一致しないよう前方一致は使わず、"CO2e" 等は個別に列挙）
 var carbonKeywordsJapan = []string{
-	"カーボン", "炭素", "脱炭素", "CO2", "温室効果ガス", "GHG",
+	"カーボン", "炭素", "脱炭素", "CO2", "CO2e", "温室効果ガス", "GHG",
 	"気候変動", "クライメート", "排出量取引", "ETS", "カーボンプライシング",
-	"カーボンクレジット", "クレジット市場", "carbon", "climate",
-	"JCM", "二国間クレジット", "カーボンニュートラル", "地球温暖化", "パリ協定", "COP",
+	"カーボンクレジット", "クレジット市場", "carbon", "low-carbon", "carbon-neutral", "climate",
+	"JCM", "二国間クレジット", "カーボンニュートラル", "地球温暖化", "パリ協定", "COP", "COP2*", "COP3*",
 	"サステナビリティ", "エネルギー転換", "再生可能エネルギー", "グリーン",
 }
 
+// carbonFilterJapan は carbonKeywordsJapan から作成したフィルタ（記事ごとに作り直さない）
+var carbonFilterJapan = NewKeywordFilter(carbonKeywordsJapan)
+
 // collectHeadlinesJRI は JRI（日本総合研究所）の RSSフィードから見出しを収集
 //
 // JRI は日本のシンクタンクで、カーボンニュートラルや気候変動に関する
@@ -130,7 +133,7 @@ func collectHeadlinesJRI(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 						continue
 					}
 					// 短いナビゲーション/ラベル行をスキップ
-					if len([]rune(line)) < 20 {
+					if isNoiseLine(line) {
 						continue
 					}
 					contentLines = append(contentLines, line)
@@ -147,7 +150,7 @@ func collectHeadlinesJRI(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 		}
 
 		// キーワードフィルタ: カーボン/気候変動関連記事のみ収集
-		if !matchesKeywords(title, excerpt, carbonKeywordsJapan) {
+		if !carbonFilterJapan.Match(title, excerpt) {
 			continue
 		}
 
@@ -157,6 +160,7 @@ func collectHeadlinesJRI(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 			URL:         item.Link,
 			PublishedAt: publishedAt,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -193,11 +197,12 @@ func collectHeadlinesEnvMinistry(limit int, cfg HeadlineSourceConfig) ([]Headlin
 
 	// カーボン/気候変動関連記事のフィルタリング用キーワード
 	carbonKeywords := []string{
-		"カーボン", "炭素", "脱炭素", "CO2", "温室効果ガス", "GHG",
+		"カーボン", "炭素", "脱炭素", "CO2", "CO2e", "温室効果ガス", "GHG",
 		"気候変動", "クライメート", "排出量取引", "ETS", "カーボンプライシング",
 		"カーボンクレジット", "クレジット市場", "JCM", "二国間クレジット",
 		"カーボンニュートラル", "地球温暖化", "パリ協定", "COP",
 	}
+	carbonFilter := NewKeywordFilter(carbonKeywords)
 
 	out := make([]Headline, 0, limit)
 	currentDate := ""
@@ -237,7 +242,7 @@ func collectHeadlinesEnvMinistry(limit int, cfg HeadlineSourceConfig) ([]Headlin
 		}
 
 		// タイトルにカーボン関連キーワードが含まれるか確認
-		if !matchesKeywords(title, "", carbonKeywords) {
+		if !carbonFilter.Match(title, "") {
 			return
 		}
 
@@ -304,8 +309,9 @@ func collectHeadlinesJPX(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 	// カーボンクレジット関連記事のフィルタリング用キーワード
 	carbonKeywords := []string{
 		"カーボン", "炭素", "クレジット", "排出", "GX", "グリーン",
-		"脱炭素", "CO2", "温室効果ガス", "取引", "市場", "環境",
+		"脱炭素", "CO2", "CO2e", "温室効果ガス", "取引", "市場", "環境",
 	}
+	carbonFilter := NewKeywordFilter(carbonKeywords)
 
 	out := make([]Headline, 0, limit)
 
@@ -315,17 +321,10 @@ func collectHeadlinesJPX(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 		}
 
 		// タイトルまたはリンクにカーボン関連キーワードが含まれるか確認
-		titleLower := strings.ToLower(item.Title)
 		linkLower := strings.ToLower(item.Link)
-		containsKeyword := false
-		for _, kw := range carbonKeywords {
-			if strings.Contains(titleLower, strings.ToLower(kw)) ||
-				strings.Contains(linkLower, "carbon") ||
-				strings.Contains(linkLower, "クレジット") {
-				containsKeyword = true
-				break
-			}
-		}
+		containsKeyword := carbonFilter.Match(item.Title, "") ||
+			strings.Contains(linkLower, "carbon") ||
+			strings.Contains(linkLower, "クレジット")
 
 		if !containsKeyword {
 			continue
@@ -363,6 +362,7 @@ func collectHeadlinesJPX(limit int, cfg HeadlineSourceConfig) ([]Headline, error
 			URL:         item.Link,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -434,6 +434,7 @@ func collectHeadlinesMETI(limit int, cfg HeadlineSourceConfig) ([]Headline, erro
 		"水素", "アンモニア", "原子力", "再生可能",
 		"排出", "温暖化", "気候", "蓄電", "電池",
 	}
+	energyFilter := NewKeywordFilter(energyKeywords)
 
 	// 日本語日付フォーマット用の正規表現（パッケージレベルで定義済み）
 	dateRe := reJapaneseDateYMD
@@ -476,14 +477,7 @@ func collectHeadlinesMETI(limit int, cfg HeadlineSourceConfig) ([]Headline, erro
 		}
 
 		// キーワードフィルタをチェック
-		hasKeyword := false
-		titleLower := strings.ToLower(title)
-		for _, kw := range energyKeywords {
-			if strings.Contains(titleLower, strings.ToLower(kw)) {
-				hasKeyword = true
-				break
-			}
-		}
+		hasKeyword := energyFilter.Match(title, "")
 
 		// フィルタロジックを適用:
 		// - パス一致 -> 収集（キーワード不問）
@@ -61,8 +61,8 @@ func extractTextFromPDF(pdfURL string, client *http.Client, userAgent string) (s
 		return "", fmt.Errorf("failed to parse PDF: %w", err)
 	}
 
-	// 全ページからテキストを抽出する
-	var textBuilder strings.Builder
+	// 全ページからテキストを抽出する（ページごとに空白を正規化し、改ページ記号で区切る）
+	var pages []string
 	numPages := pdfReader.NumPage()
 	for i := 1; i <= numPages; i++ {
 		page := pdfReader.Page(i)
@@ -73,17 +73,13 @@ func extractTextFromPDF(pdfURL string, client *http.Client, userAgent string) (s
 		if err != nil {
 			continue
 		}
-		textBuilder.WriteString(text)
-		textBuilder.WriteString("\n")
+		if text = normalizeWhitespace(text); text != "" {
+			pages = append(pages, text)
+		}
 	}
 
-	// 抽出テキストを整形する
-	result := textBuilder.String()
-	result = strings.TrimSpace(result)
-	// 空白を正規化する
-	result = strings.Join(strings.Fields(result), " ")
-
-	return result, nil
+	// Notionのページ本文ではPDFのページごとに分割して表示する（structure.go）
+	return strings.Join(pages, excerptPageBreak), nil
 }
 
 // =============================================================================
@@ -219,15 +215,9 @@ func collectHeadlinesEUETS(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 					for _, sel := range contentSelectors {
 						contentElem := articleDoc.Find(sel)
 						if contentElem.Length() > 0 {
-							var paragraphs []string
-							contentElem.Find("p").Each(func(_ int, p *goquery.Selection) {
-								text := strings.TrimSpace(p.Text())
-								if len(text) > 30 {
-									paragraphs = append(paragraphs, text)
-								}
-							})
-							if len(paragraphs) > 0 {
-								excerpt = strings.Join(paragraphs, "\n\n")
+							// 見出し・箇条書きの構造を残して抽出（structure.go）
+							if text := structuredTextFromSelection(contentElem, 30); text != "" {
+								excerpt = text
 								break
 							}
 						}
@@ -373,15 +363,8 @@ func collectHeadlinesCARB(limit int, cfg HeadlineSourceConfig) ([]Headline, erro
 					// メイン要素からコンテンツを抽出
 					mainContent := articleDoc.Find("main#main-content, main, article, .content")
 					if mainContent.Length() > 0 {
-						// 全段落テキストを取得
-						var paragraphs []string
-						mainContent.Find("p").Each(func(_ int, p *goquery.Selection) {
-							text := strings.TrimSpace(p.Text())
-							if len(text) > 20 {
-								paragraphs = append(paragraphs, text)
-							}
-						})
-						excerpt = strings.Join(paragraphs, "\n\n")
+						// 見出し・箇条書きの構造を残して抽出（structure.go）
+						excerpt = structuredTextFromSelection(mainContent.First(), 20)
 					}
 				}
 			}
@@ -946,15 +929,9 @@ func collectHeadlinesUKETSHTML(limit int, cfg HeadlineSourceConfig) ([]Headline,
 					for _, sel := range contentSelectors {
 						contentElem := articleDoc.Find(sel)
 						if contentElem.Length() > 0 {
-							var paragraphs []string
-							contentElem.Find("p").Each(func(_ int, p *goquery.Selection) {
-								text := strings.TrimSpace(p.Text())
-								if len(text) > 30 {
-									paragraphs = append(paragraphs, text)
-								}
-							})
-							if len(paragraphs) > 0 {
-								excerpt = strings.Join(paragraphs, "\n\n")
+							// 見出し・箇条書きの構造を残して抽出（structure.go）
+							if text := structuredTextFromSelection(contentElem, 30); text != "" {
+								excerpt = text
 								break
 							}
 						}
@@ -86,6 +86,7 @@ func collectHeadlinesPoliticoEU(limit int, cfg HeadlineSourceConfig) ([]Headline
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -98,16 +99,21 @@ func collectHeadlinesPoliticoEU(limit int, cfg HeadlineSourceConfig) ([]Headline
 // RSSアイテムのカテゴリもこのキーワードで照合される。
 // 注意: "ets" は "Markets"、"bets"、"Metsola" 等の部分文字列に一致するため、
 // 単体キーワードとしては使用せず、具体的な形式（"eu ets" 等）を使用する。
+// 照合は単語境界で行うため（filter.go 参照）、"CO2e" 等の派生語は個別に列挙し、"environmental" 等の
+// 無関係な語と紛れない語だけ末尾 "*" で前方一致にしている（"carbon*" は "carbonated" に一致するため使わない）。
 var carbonKeywordsEuractiv = []string{
-	"carbon", "emission", "climate", "co2", "greenhouse",
-	"net zero", "net-zero", "decarbonisation", "decarbonization",
+	"carbon", "low-carbon", "carbon-neutral", "emission", "climate", "co2", "co2e", "tco2e", "greenhouse",
+	"net zero", "net-zero", "decarboni*",
 	"green deal", "fit for 55", "cbam", "carbon border",
-	"renewable", "energy transition", "paris agreement",
+	"renewable*", "energy transition", "paris agreement",
 	"methane", "carbon market", "carbon price", "carbon tax",
-	"energy", "environment", "sustainability",
+	"energy", "energies", "environment*", "sustainab*",
 	"eu ets", "ets2", "emissions trading", "uk ets",
 }
 
+// carbonFilterEuractiv は carbonKeywordsEuractiv から作成したフィルタ（記事ごとに作り直さない）
+var carbonFilterEuractiv = NewKeywordFilter(carbonKeywordsEuractiv)
+
 // reEuractiveSpaces は Euractiv 記事テキストの空白を正規化する正規表現
 var reEuractiveSpaces = regexp.MustCompile(`\s+`)
 
@@ -154,7 +160,7 @@ func collectHeadlinesEuractiv(limit int, cfg HeadlineSourceConfig) ([]Headline,
 		catStr := strings.Join(item.Categories, " ")
 
 		// タイトル+説明+カテゴリでキーワードフィルタリング
-		if !matchesKeywords(title, rssExcerpt+" "+catStr, carbonKeywordsEuractiv) {
+		if !carbonFilterEuractiv.Match(title, rssExcerpt+" "+catStr) {
 			continue
 		}
 
@@ -182,6 +188,7 @@ func collectHeadlinesEuractiv(limit int, cfg HeadlineSourceConfig) ([]Headline,
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -282,6 +289,7 @@ func collectHeadlinesUKETS(limit int, cfg HeadlineSourceConfig) ([]Headline, err
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -339,6 +347,7 @@ func collectHeadlinesUNNews(limit int, cfg HeadlineSourceConfig) ([]Headline, er
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -393,6 +402,7 @@ func collectHeadlinesCarbonBrief(limit int, cfg HeadlineSourceConfig) ([]Headlin
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
@@ -449,6 +459,7 @@ func collectHeadlinesCarbonMarketWatch(limit int, cfg HeadlineSourceConfig) ([]H
 			URL:         articleURL,
 			PublishedAt: dateStr,
 			Excerpt:     excerpt,
+			Authors:     feedAuthors(item),
 
 		})
 	}
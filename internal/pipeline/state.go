@@ -0,0 +1,93 @@
+// =============================================================================
+// state.go - 実行をまたいで残す状態ファイル
+// =============================================================================
+//
+// このファイルはアラートの抑制（alerts.go）やエラー通知のインシデント（error_notify.go）のように、
+// 前回の実行結果を覚えておく必要がある機能のための小さなJSONファイルの読み書きを提供します。
+//
+// 【保存先】（STATE_DIR、または機能ごとの *_STATE_FILE）
+//
+//	CLI    - カレントディレクトリ（デフォルト）
+//	Lambda - デフォルトなし。/tmp はウォームなコンテナでしか残らず、コールドスタートのたびに
+//	         通知済みの記録が消えて同じ通知が繰り返されるため、EFS 等をマウントした
+//	         ディレクトリの指定を必須とする（未設定の場合は StatePath がエラーを返す）
+//
+// 【書き込み】
+//   ファイルストア（store_file.go）と同じく一時ファイルに書き出してから rename するため、
+//   書き込み中に失敗しても前回の状態は壊れない。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StatePath は状態ファイルのパスを返す
+//
+// 環境変数 fileEnv（ALERT_STATE_FILE 等）が設定されていればそのまま使い、
+// 未設定の場合は STATE_DIR（未設定なら defaultDir）の下の name。
+// defaultDir が空（Lambda）で、どちらも設定されていない場合はエラーを返す。
+func StatePath(fileEnv, defaultDir, name string) (string, error) {
+	if explicit := strings.TrimSpace(os.Getenv(fileEnv)); explicit != "" {
+		return explicit, nil
+	}
+	dir := strings.TrimSpace(os.Getenv("STATE_DIR"))
+	if dir == "" {
+		dir = defaultDir
+	}
+	if dir == "" {
+		return "", fmt.Errorf("%s or STATE_DIR must be set to durable storage (e.g. an EFS mount); state is not kept between runs otherwise", fileEnv)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// loadState は状態ファイルを読み込む（ファイルがない場合は out を変更せずに nil を返す）
+func loadState(path string, out any) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	return nil
+}
+
+// saveState は状態を一時ファイルに書き出してから置き換える
+func saveState(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	return nil
+}
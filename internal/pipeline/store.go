@@ -0,0 +1,144 @@
+// =============================================================================
+// store.go - 見出しストア（保存先の切り替え）
+// =============================================================================
+//
+// このファイルは見出しの保存・期間検索・要約の更新を行うストアのインターフェースと、
+// 環境変数・フラグからストアを選ぶ処理を提供します。
+//
+// 【背景】
+//   収集Lambdaは必ずNotionにクリップし、メールLambdaは必ずNotionから読んでいたため、
+//   Notionを使わないチームは 収集 → 保存 → メール の流れを動かせなかった。
+//
+// 【バックエンド】
+//
+//	notion - Notionデータベース（NotionClipper、notion.go）。デフォルト
+//	file   - ローカルのJSONファイル（FileStore、store_file.go）
+//
+// 【環境変数】
+//
+//	STORE_BACKEND - notion / file（デフォルト: notion）
+//	STORE_PATH    - file の保存先（デフォルト: headlines_store.json）
+//	NOTION_TOKEN / NOTION_DATABASE_ID - notion の場合に必須
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// ストアのバックエンド
+const (
+	StoreBackendNotion = "notion"
+	StoreBackendFile   = "file"
+)
+
+// DefaultStorePath はファイルストアのデフォルトの保存先
+const DefaultStorePath = "headlines_store.json"
+
+// HeadlineStore は見出しの保存先
+//
+// 保存結果は NotionClipResult で返す（エラー通知メール・Lambdaのログで共通に扱うため）。
+type HeadlineStore interface {
+	// Name はログ表示用のストア名
+	Name() string
+
+	// SaveHeadlines は見出しを保存する（失敗した記事は結果の Failures に記録）
+	SaveHeadlines(ctx context.Context, headlines []Headline) *NotionClipResult
+
+	// QueryHeadlines は条件（notion_query.go）に合う見出しを返す
+	QueryHeadlines(ctx context.Context, q HeadlineQuery) ([]NotionHeadline, error)
+
+	// UpdateSummary は記事の要約（Article Summary 300）を更新する
+	UpdateSummary(ctx context.Context, id, summary string) error
+}
+
+// StoreConfig はストアの選択（STORE_BACKEND / STORE_PATH または -store / -storePath）
+type StoreConfig struct {
+	// Backend は notion / file（空の場合は notion）
+	Backend string
+
+	// Path はファイルストアの保存先（空の場合は DefaultStorePath）
+	Path string
+}
+
+// StoreConfigFromEnv は環境変数からストアの設定を読み込む（Lambda用）
+func StoreConfigFromEnv() StoreConfig {
+	return StoreConfig{
+		Backend: os.Getenv("STORE_BACKEND"),
+		Path:    os.Getenv("STORE_PATH"),
+	}
+}
+
+// OpenHeadlineStore は設定に応じたストアを作成する
+//
+// notion の場合は NOTION_TOKEN と NOTION_DATABASE_ID が必要。
+func OpenHeadlineStore(cfg StoreConfig) (HeadlineStore, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case StoreBackendNotion, "":
+		token := os.Getenv("NOTION_TOKEN")
+		dbID := os.Getenv("NOTION_DATABASE_ID")
+		if token == "" {
+			return nil, fmt.Errorf("NOTION_TOKEN is required")
+		}
+		if dbID == "" {
+			return nil, fmt.Errorf("NOTION_DATABASE_ID is required")
+		}
+		clipper, err := NewNotionClipper(token, dbID)
+		if err != nil {
+			return nil, err
+		}
+		return clipper, nil
+	case StoreBackendFile:
+		path := cfg.Path
+		if path == "" {
+			path = DefaultStorePath
+		}
+		return NewFileStore(path), nil
+	}
+	return nil, fmt.Errorf("unknown store backend: %s (use %s or %s)", cfg.Backend, StoreBackendNotion, StoreBackendFile)
+}
+
+// RecentHeadlinesQuery は過去daysBack日以内に作成された見出しを新しい順に返す条件
+func RecentHeadlinesQuery(daysBack int) HeadlineQuery {
+	return HeadlineQuery{
+		CreatedOnOrAfter: time.Now().AddDate(0, 0, -daysBack),
+		Sort:             HeadlineSortCreatedDesc,
+	}
+}
+
+// =============================================================================
+// Notionバックエンド
+// =============================================================================
+
+// Name はストア名を返す
+func (nc *NotionClipper) Name() string {
+	return "Notion"
+}
+
+// SaveHeadlines は見出しを並列でNotionにクリップする（ClipHeadlines、notion_batch.go）
+func (nc *NotionClipper) SaveHeadlines(ctx context.Context, headlines []Headline) *NotionClipResult {
+	return nc.ClipHeadlines(ctx, headlines, 0)
+}
+
+// UpdateSummary はページの "Article Summary 300" を更新する
+func (nc *NotionClipper) UpdateSummary(ctx context.Context, id, summary string) error {
+	req := &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{
+			"Article Summary 300": notionapi.RichTextProperty{
+				Type:     notionapi.PropertyTypeRichText,
+				RichText: splitIntoRichTextBlocks(summary),
+			},
+		},
+	}
+	return notionRetry(ctx, "Page.Update", func() error {
+		_, err := nc.client.Page.Update(ctx, notionapi.PageID(id), req)
+		return err
+	})
+}
@@ -0,0 +1,293 @@
+// =============================================================================
+// store_file.go - ローカルファイルの見出しストア
+// =============================================================================
+//
+// このファイルはNotionを使わずに見出しを1つのJSONファイルに保存するストアを提供します。
+// Notionのないチームでも 収集 → 保存 → メール を実行でき、結合テストでも使えます。
+//
+// 【保存形式】
+//
+//	{
+//	  "version": 1,
+//	  "nextId": 3,
+//	  "headlines": [ NotionHeadline（types.go のJSONタグ）, ... ]
+//	}
+//
+// 【Notionとの対応】
+//   ClipHeadline がNotionに書き込む値と同じものを保存する
+//   （Type・Language・Extracted Summary、Article Summary 300 には本文のプレーンテキスト）。
+//   Notion AIによる要約はないため、メールでは抽出型要約（summarize.go）が使われる。
+//   要約を差し替える場合は UpdateSummary を使う。
+//
+// 【書き込み】
+//   一時ファイルに書き出してから rename するため、書き込み中に失敗しても元のファイルは壊れない。
+//   同じファイルを複数プロセスから同時に更新することは想定しない。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fileStoreVersion はファイルストアの保存形式のバージョン
+const fileStoreVersion = 1
+
+// fileStoreData はファイルストアの保存内容
+type fileStoreData struct {
+	Version   int              `json:"version"`
+	NextID    int              `json:"nextId"`
+	Headlines []NotionHeadline `json:"headlines"`
+}
+
+// FileStore はローカルのJSONファイルに見出しを保存するストア
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore は path に保存するファイルストアを作成する（ファイルは最初の保存時に作成）
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Name はストア名を返す
+func (fs *FileStore) Name() string {
+	return "file store " + fs.path
+}
+
+// SaveHeadlines は見出しをファイルに追加する
+func (fs *FileStore) SaveHeadlines(ctx context.Context, headlines []Headline) *NotionClipResult {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	start := time.Now()
+	result := &NotionClipResult{}
+	data, err := fs.load()
+	if err != nil {
+		for _, h := range headlines {
+			result.addFailure(h.Title, err)
+		}
+		return result
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	added := 0
+	for _, h := range headlines {
+		if ctx.Err() != nil {
+			break
+		}
+		data.NextID++
+		record := storedHeadline(h)
+		record.ID = strconv.Itoa(data.NextID)
+		record.CreatedAt = now
+		data.Headlines = append(data.Headlines, record)
+		added++
+	}
+
+	if err := fs.write(data); err != nil {
+		for _, h := range headlines[:added] {
+			result.addFailure(h.Title, err)
+		}
+	} else {
+		result.Clipped = added
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// QueryHeadlines は条件に合う見出しを返す（条件の意味は notion_query.go と同じ）
+func (fs *FileStore) QueryHeadlines(ctx context.Context, q HeadlineQuery) ([]NotionHeadline, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, err := q.Sorts(); err != nil {
+		return nil, err
+	}
+	data, err := fs.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []NotionHeadline
+	for _, h := range data.Headlines {
+		if q.matches(h) {
+			matched = append(matched, h)
+		}
+	}
+
+	desc := q.Sort == HeadlineSortCreatedDesc || q.Sort == HeadlineSortPublishedDesc || q.Sort == ""
+	byPublished := q.Sort == HeadlineSortPublishedDesc || q.Sort == HeadlineSortPublishedAsc
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i].CreatedAt, matched[j].CreatedAt
+		if byPublished {
+			a, b = matched[i].PublishedDate, matched[j].PublishedDate
+		}
+		if desc {
+			return a > b
+		}
+		return a < b
+	})
+
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+// UpdateSummary は記事の要約（Article Summary 300）を更新する
+func (fs *FileStore) UpdateSummary(ctx context.Context, id, summary string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := fs.load()
+	if err != nil {
+		return err
+	}
+	for i := range data.Headlines {
+		if data.Headlines[i].ID == id {
+			data.Headlines[i].ShortHeadline = summary
+			return fs.write(data)
+		}
+	}
+	return fmt.Errorf("headline %s not found in %s", id, fs.path)
+}
+
+// load はファイルを読み込む（ファイルがない場合は空のストア）
+func (fs *FileStore) load() (*fileStoreData, error) {
+	data := &fileStoreData{Version: fileStoreVersion}
+	b, err := os.ReadFile(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %w", fs.path, err)
+	}
+	if data.Version > fileStoreVersion {
+		return nil, fmt.Errorf("store %s has version %d (this build supports up to %d)", fs.path, data.Version, fileStoreVersion)
+	}
+	return data, nil
+}
+
+// write は一時ファイルに書き出してから置き換える
+func (fs *FileStore) write(data *fileStoreData) error {
+	data.Version = fileStoreVersion
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	return nil
+}
+
+// storedHeadline は見出しを ClipHeadline がNotionに書き込む値と同じ形に変換する
+func storedHeadline(h Headline) NotionHeadline {
+	typeName := "News"
+	if academicSources[h.Source] {
+		typeName = "Academic"
+	}
+	lang := headlineLanguage(h)
+
+	summary := h.Summary
+	if summary == "" {
+		summary = SummarizeExtractive(h.Excerpt, SummaryMaxRunesFor(lang))
+	}
+
+	published := ""
+	if h.PublishedAt != "" {
+		if t, err := parsePublishedDate(h.PublishedAt); err == nil {
+			published = t.Format(time.RFC3339)
+		}
+	}
+
+	return NotionHeadline{
+		Title:            h.Title,
+		URL:              h.URL,
+		Source:           h.Source,
+		Type:             typeName,
+		ShortHeadline:    plainExcerpt(h.Excerpt),
+		ExtractedSummary: summary,
+		Language:         lang,
+		PublishedDate:    published,
+		Entities:         h.Entities,
+		Prices:           h.Prices,
+		Authors:          h.Authors,
+		Topics:           h.Topics,
+	}
+}
+
+// matches は見出しが検索条件に合うかを返す（Filter のローカル版）
+func (q HeadlineQuery) matches(h NotionHeadline) bool {
+	if !inTimeRange(h.CreatedAt, q.CreatedOnOrAfter, q.CreatedBefore) {
+		return false
+	}
+	if (!q.PublishedOnOrAfter.IsZero() || !q.PublishedBefore.IsZero()) &&
+		!inTimeRange(h.PublishedDate, q.PublishedOnOrAfter, q.PublishedBefore) {
+		return false
+	}
+	if len(q.Types) > 0 && !containsString(q.Types, h.Type) {
+		return false
+	}
+	if len(q.Sources) > 0 && !containsString(q.Sources, h.Source) {
+		return false
+	}
+	return true
+}
+
+// inTimeRange はRFC3339の日時が [onOrAfter, before) に含まれるかを返す（ゼロ値の側は無制限）
+//
+// 日時が空・解釈できない場合は、範囲の指定がなければ含める。
+func inTimeRange(value string, onOrAfter, before time.Time) bool {
+	if onOrAfter.IsZero() && before.IsZero() {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	if !onOrAfter.IsZero() && t.Before(onOrAfter) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
+
+// containsString はスライスに値が含まれるかを返す
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
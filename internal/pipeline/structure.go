@@ -0,0 +1,125 @@
+// =============================================================================
+// structure.go - 本文の構造（見出し・リスト・PDFページ）
+// =============================================================================
+//
+// このファイルはHTML・PDFから抽出した本文の構造を、Excerpt（プレーンテキスト）の中に
+// 行頭の記号で残すための規約と変換関数を提供します。
+//
+// 【背景】
+//   以前は全ての段落を平文にしてから Excerpt に入れていたため、Notionのページ本文は
+//   段落ブロックが並ぶだけで、見出しや箇条書きの区切りが失われていた。
+//
+// 【Excerpt の構造記号】
+//
+//	"## 見出し"      → Notionの見出しブロック（"### " は小見出し）
+//	"- 項目"         → 箇条書きブロック
+//	"\f"（改ページ）  → PDFのページ区切り（ページごとに見出しを付けて分割）
+//	それ以外の段落   → 段落ブロック（段落同士は空行で区切る）
+//
+//	要約・メール等でプレーンテキストが必要な場合は plainExcerpt で記号を除去する。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	excerptHeadingPrefix    = "## "
+	excerptSubheadingPrefix = "### "
+	excerptBulletPrefix     = "- "
+	excerptPageBreak        = "\f"
+)
+
+// structuredTextFromSelection は要素内の見出し・段落・リスト項目を文書順に構造記号付きで返す
+//
+// minParagraphRunes 未満の段落（日付・キャプション等）は除外する。
+// リスト項目内の段落は項目として1回だけ出力する。
+func structuredTextFromSelection(sel *goquery.Selection, minParagraphRunes int) string {
+	var parts []string
+	sel.Find("h2, h3, h4, p, li").Each(func(_ int, s *goquery.Selection) {
+		text := normalizeWhitespace(s.Text())
+		if text == "" {
+			return
+		}
+		switch goquery.NodeName(s) {
+		case "h2":
+			parts = append(parts, excerptHeadingPrefix+text)
+		case "h3", "h4":
+			parts = append(parts, excerptSubheadingPrefix+text)
+		case "li":
+			// ナビゲーション等の入れ子リストは親の項目に含まれるため除外
+			if s.Find("li").Length() > 0 || utf8.RuneCountInString(text) < 10 {
+				return
+			}
+			parts = append(parts, excerptBulletPrefix+text)
+		case "p":
+			if s.ParentsFiltered("li").Length() > 0 || utf8.RuneCountInString(text) < minParagraphRunes {
+				return
+			}
+			parts = append(parts, text)
+		}
+	})
+	return dropTrailingHeadings(parts)
+}
+
+// dropTrailingHeadings は本文を伴わない末尾の見出し（"Related articles" 等）を除いて連結する
+func dropTrailingHeadings(parts []string) string {
+	for len(parts) > 0 && isExcerptHeading(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// htmlToStructuredText はHTML断片（WordPressの content.rendered 等）を構造記号付きテキストに変換する
+//
+// 見出し・段落・リストが1つも見つからない場合はタグを除去した平文を返す。
+func htmlToStructuredText(htmlStr string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
+	if err == nil {
+		doc.Find("script, style").Remove()
+		if text := structuredTextFromSelection(doc.Selection, 1); text != "" {
+			return reShortcodes.ReplaceAllString(text, "")
+		}
+	}
+	return strings.TrimSpace(cleanHTMLTags(htmlStr))
+}
+
+// isExcerptHeading は構造記号付きの見出し行かを返す
+func isExcerptHeading(line string) bool {
+	return strings.HasPrefix(line, excerptHeadingPrefix) || strings.HasPrefix(line, excerptSubheadingPrefix)
+}
+
+// plainExcerpt は構造記号を除去したプレーンテキストを返す（見出しは文として残す）
+func plainExcerpt(excerpt string) string {
+	if !strings.Contains(excerpt, excerptHeadingPrefix) && !strings.Contains(excerpt, excerptBulletPrefix) &&
+		!strings.Contains(excerpt, excerptPageBreak) {
+		return excerpt
+	}
+	excerpt = strings.ReplaceAll(excerpt, excerptPageBreak, "\n\n")
+	lines := strings.Split(excerpt, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, excerptSubheadingPrefix):
+			lines[i] = strings.TrimPrefix(line, excerptSubheadingPrefix)
+		case strings.HasPrefix(line, excerptHeadingPrefix):
+			lines[i] = strings.TrimPrefix(line, excerptHeadingPrefix)
+		case strings.HasPrefix(line, excerptBulletPrefix):
+			lines[i] = strings.TrimPrefix(line, excerptBulletPrefix)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitPDFPages はPDF由来の本文をページごとに分割する（改ページがない場合は1要素）
+func splitPDFPages(excerpt string) []string {
+	var pages []string
+	for _, page := range strings.Split(excerpt, excerptPageBreak) {
+		pages = append(pages, strings.TrimSpace(page))
+	}
+	return pages
+}
@@ -0,0 +1,421 @@
+// =============================================================================
+// subscribers.go - 購読者ごとのダイジェスト配信
+// =============================================================================
+//
+// このファイルは購読者リストのファイルを読み込み、購読者ごとに絞り込んだダイジェストを
+// 1人1通ずつ送信する機能を提供します。
+//
+// 【背景】
+//   EMAIL_TO はカンマ区切りの1つのリストで、全員が同じメールを1つのToヘッダーで受け取るため、
+//   互いのアドレスが見えてしまう。政策チームはコンプライアンス市場（ETS）の記事だけ、
+//   研究チームはAcademicの記事だけを読みたい、といった要望にも応えられなかった。
+//
+// 【購読者リスト】（EMAIL_SUBSCRIBERS_FILE / -emailSubscribers）
+//
+//	{
+//	  "subscribers": [
+//	    {
+//	      "email": "policy@example.com",
+//	      "name": "政策チーム",
+//	      "filters": {"topics": ["Compliance Markets"], "language": "ja"},
+//	      "format": "short",
+//	      "body": "html",
+//	      "schedule": "weekdays"
+//	    },
+//	    {
+//	      "email": "research@example.com",
+//	      "filters": {"types": ["Academic"]},
+//	      "format": "full",
+//	      "body": "text",
+//	      "schedule": "weekly:mon"
+//	    }
+//	  ]
+//	}
+//
+//	filters.sources  - ソース名（"Carbon Pulse" 等、Notionの Source と同じ表記）
+//	filters.types    - News / Academic
+//	filters.topics   - トピック（topics.go の名前）。いずれかを含む記事
+//	filters.language - ja / en / all
+//	  条件を省略した項目は絞り込まない。大文字・小文字は区別しない。
+//	format   - short（50文字ダイジェスト、デフォルト）/ full（見出しサマリー）
+//	body     - html（HTML + プレーンテキスト、デフォルト）/ text（プレーンテキストのみ）
+//	schedule - daily（デフォルト）/ weekdays（月〜金）/ weekly:<曜日>（mon〜sun）/ monthly:<日>（1〜28）
+//
+// 【スケジュール】
+//   送信ステップは毎日実行し、その日が対象の購読者にだけ送る（曜日・日付はプロセスのタイムゾーン）。
+//   対象期間は daily・weekdays が -emailDaysBack（月曜の weekdays は週末の分を含める）、
+//   weekly が7日間、monthly が1か月間。
+//
+// 【BCC相当の配送】
+//   1通ごとに To ヘッダーとSMTPの宛先（RCPT TO）をその購読者1人だけにするため、
+//   ほかの購読者のアドレスは見えない。1人への送信に失敗しても残りの購読者には送る。
+//
+// =============================================================================
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 購読者のダイジェストの形式
+const (
+	SubscriberFormatShort = "short"
+	SubscriberFormatFull  = "full"
+)
+
+// 購読者のメール本文の形式
+const (
+	SubscriberBodyHTML = "html"
+	SubscriberBodyText = "text"
+)
+
+// 購読者の配信スケジュール
+const (
+	ScheduleDaily    = "daily"
+	ScheduleWeekdays = "weekdays"
+	ScheduleWeekly   = "weekly"
+	ScheduleMonthly  = "monthly"
+)
+
+// SubscriberFilters は購読者ごとの記事の絞り込み条件
+type SubscriberFilters struct {
+	Sources  []string `json:"sources,omitempty"`
+	Types    []string `json:"types,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
+	Language string   `json:"language,omitempty"`
+}
+
+// Subscriber は購読者リストの1エントリ
+type Subscriber struct {
+	Email    string            `json:"email"`
+	Name     string            `json:"name,omitempty"`
+	Filters  SubscriberFilters `json:"filters"`
+	Format   string            `json:"format,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Schedule string            `json:"schedule,omitempty"`
+
+	schedule subscriberSchedule
+}
+
+// subscriberSchedule は解釈済みの配信スケジュール
+type subscriberSchedule struct {
+	kind    string
+	weekday time.Weekday // weekly の曜日
+	day     int          // monthly の日
+}
+
+// subscriberFile は購読者リストのファイルの形式
+type subscriberFile struct {
+	Subscribers []Subscriber `json:"subscribers"`
+}
+
+// weekdayNames は weekly:<曜日> に使える曜日名
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// LoadSubscribers は購読者リストのファイルを読み込み、検証する
+func LoadSubscribers(path string) ([]Subscriber, error) {
+	var file subscriberFile
+	if err := readJSONFile(path, &file); err != nil {
+		return nil, fmt.Errorf("reading subscribers: %w", err)
+	}
+	if len(file.Subscribers) == 0 {
+		return nil, fmt.Errorf("subscribers file %s has no subscribers", path)
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Subscribers {
+		sub := &file.Subscribers[i]
+		if err := sub.normalize(); err != nil {
+			return nil, fmt.Errorf("subscriber %d (%s): %w", i+1, sub.Email, err)
+		}
+		key := strings.ToLower(sub.Email)
+		if seen[key] {
+			return nil, fmt.Errorf("subscriber %s is listed more than once", sub.Email)
+		}
+		seen[key] = true
+	}
+	return file.Subscribers, nil
+}
+
+// normalize はデフォルト値を補い、設定を検証する
+func (s *Subscriber) normalize() error {
+	addr, err := mail.ParseAddress(strings.TrimSpace(s.Email))
+	if err != nil {
+		return fmt.Errorf("invalid email: %w", err)
+	}
+	s.Email = addr.Address
+	if s.Name == "" {
+		s.Name = addr.Name
+	}
+
+	s.Format = strings.ToLower(strings.TrimSpace(s.Format))
+	switch s.Format {
+	case "":
+		s.Format = SubscriberFormatShort
+	case SubscriberFormatShort, SubscriberFormatFull:
+	default:
+		return fmt.Errorf("unsupported format %q (use short or full)", s.Format)
+	}
+
+	s.Body = strings.ToLower(strings.TrimSpace(s.Body))
+	switch s.Body {
+	case "":
+		s.Body = SubscriberBodyHTML
+	case SubscriberBodyHTML, SubscriberBodyText:
+	default:
+		return fmt.Errorf("unsupported body %q (use html or text)", s.Body)
+	}
+
+	lang := strings.ToLower(strings.TrimSpace(s.Filters.Language))
+	switch lang {
+	case "", "all":
+		s.Filters.Language = ""
+	case LangJapanese, LangEnglish:
+		s.Filters.Language = lang
+	default:
+		return fmt.Errorf("unsupported language %q (use ja, en or all)", lang)
+	}
+
+	for _, t := range s.Filters.Types {
+		if !strings.EqualFold(t, "News") && !strings.EqualFold(t, "Academic") {
+			return fmt.Errorf("unsupported type %q (use News or Academic)", t)
+		}
+	}
+	for _, topic := range s.Filters.Topics {
+		if !knownTopic(topic) {
+			return fmt.Errorf("unknown topic %q (see topics.go)", topic)
+		}
+	}
+
+	sched, err := parseSubscriberSchedule(s.Schedule)
+	if err != nil {
+		return err
+	}
+	s.schedule = sched
+	return nil
+}
+
+// knownTopic はトピック名が topics.go の規則にあるかを返す
+func knownTopic(name string) bool {
+	for _, r := range topicRules {
+		if strings.EqualFold(r.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSubscriberSchedule はスケジュールを解釈する（空の場合は daily）
+func parseSubscriberSchedule(s string) (subscriberSchedule, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	kind, arg, _ := strings.Cut(s, ":")
+	switch kind {
+	case "", ScheduleDaily:
+		return subscriberSchedule{kind: ScheduleDaily}, nil
+	case ScheduleWeekdays:
+		return subscriberSchedule{kind: ScheduleWeekdays}, nil
+	case ScheduleWeekly:
+		if arg == "" {
+			arg = "mon"
+		}
+		wd, ok := weekdayNames[arg[:min(3, len(arg))]]
+		if !ok {
+			return subscriberSchedule{}, fmt.Errorf("invalid schedule %q (use weekly:mon ... weekly:sun)", s)
+		}
+		return subscriberSchedule{kind: ScheduleWeekly, weekday: wd}, nil
+	case ScheduleMonthly:
+		day := 1
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 || n > 28 {
+				return subscriberSchedule{}, fmt.Errorf("invalid schedule %q (use monthly:1 ... monthly:28)", s)
+			}
+			day = n
+		}
+		return subscriberSchedule{kind: ScheduleMonthly, day: day}, nil
+	}
+	return subscriberSchedule{}, fmt.Errorf("unsupported schedule %q (use daily, weekdays, weekly:<day> or monthly:<day>)", s)
+}
+
+// Due は now が配信日かを返す
+func (s *Subscriber) Due(now time.Time) bool {
+	switch s.schedule.kind {
+	case ScheduleWeekdays:
+		return now.Weekday() != time.Saturday && now.Weekday() != time.Sunday
+	case ScheduleWeekly:
+		return now.Weekday() == s.schedule.weekday
+	case ScheduleMonthly:
+		return now.Day() == s.schedule.day
+	}
+	return true
+}
+
+// Since は now に送るダイジェストの対象期間の開始日時を返す
+func (s *Subscriber) Since(now time.Time, daysBack int) time.Time {
+	switch s.schedule.kind {
+	case ScheduleWeekdays:
+		if now.Weekday() == time.Monday {
+			return now.AddDate(0, 0, -daysBack-2)
+		}
+	case ScheduleWeekly:
+		return now.AddDate(0, 0, -7)
+	case ScheduleMonthly:
+		return now.AddDate(0, -1, 0)
+	}
+	return now.AddDate(0, 0, -daysBack)
+}
+
+// Matches は記事が購読者の絞り込み条件に合うかを返す
+func (s *Subscriber) Matches(h NotionHeadline) bool {
+	f := s.Filters
+	if f.Language != "" && h.Language != f.Language {
+		return false
+	}
+	if len(f.Sources) > 0 && !containsFold(f.Sources, h.Source) {
+		return false
+	}
+	if len(f.Types) > 0 && !containsFold(f.Types, h.Type) {
+		return false
+	}
+	if len(f.Topics) > 0 {
+		found := false
+		for _, t := range h.Topics {
+			if containsFold(f.Topics, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold は大文字・小文字を区別せずにスライスに値が含まれるかを返す
+func containsFold(values []string, v string) bool {
+	for _, s := range values {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscribersSince は now に配信する購読者の対象期間のうち最も古い開始日時を返す
+//
+// ストアからはこの日時以降の記事をまとめて取得し、購読者ごとに絞り込む。
+// 配信する購読者がいない場合はゼロ値。
+func SubscribersSince(subs []Subscriber, now time.Time, daysBack int) time.Time {
+	var since time.Time
+	for i := range subs {
+		if !subs[i].Due(now) {
+			continue
+		}
+		if t := subs[i].Since(now, daysBack); since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// =============================================================================
+// 送信
+// =============================================================================
+
+// SubscriberSendResult は購読者ごとの送信結果
+type SubscriberSendResult struct {
+	Sent    int               // 送信した購読者数
+	Skipped int               // 配信日でなかった購読者数
+	Failed  map[string]string // 送信に失敗した購読者（アドレス → エラー）
+}
+
+// Err は失敗があった場合にまとめたエラーを返す
+func (r *SubscriberSendResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	addrs := make([]string, 0, len(r.Failed))
+	for addr := range r.Failed {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	parts := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		parts = append(parts, addr+": "+r.Failed[addr])
+	}
+	return fmt.Errorf("failed to send to %d subscriber(s): %s", len(r.Failed), strings.Join(parts, "; "))
+}
+
+// SendSubscriberDigests は配信日の購読者それぞれに絞り込んだダイジェストを1通ずつ送信する
+//
+// headlines は SubscribersSince 以降の記事。購読者ごとに対象期間と絞り込み条件を適用し、
+// 購読者の形式（short / full、html / text）で送る。記事が0件でも送信する
+// （SendShortHeadlinesDigest と同じ）。
+func (es *EmailSender) SendSubscriberDigests(ctx context.Context, headlines []NotionHeadline, subs []Subscriber, now time.Time, daysBack int) *SubscriberSendResult {
+	result := &SubscriberSendResult{Failed: map[string]string{}}
+
+	for i := range subs {
+		sub := &subs[i]
+		if !sub.Due(now) {
+			result.Skipped++
+			continue
+		}
+
+		since := sub.Since(now, daysBack)
+		var selected []NotionHeadline
+		for _, h := range headlines {
+			if h.CreatedAt != "" && !inTimeRange(h.CreatedAt, since, time.Time{}) {
+				continue
+			}
+			if sub.Matches(h) {
+				selected = append(selected, h)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "→ %s (%s, %s): %d articles\n", sub.Email, sub.Format, sub.schedule.kind, len(selected))
+
+		if err := es.sendToSubscriber(ctx, sub, selected); err != nil {
+			warnf("Failed to send digest to %s: %v", sub.Email, err)
+			result.Failed[sub.Email] = err.Error()
+			continue
+		}
+		result.Sent++
+	}
+	return result
+}
+
+// sendToSubscriber は購読者1人だけを宛先にしたダイジェストを送信する
+func (es *EmailSender) sendToSubscriber(ctx context.Context, sub *Subscriber, headlines []NotionHeadline) error {
+	// 宛先・表示名だけを差し替えた送信者（言語は購読者の条件で絞り込み済み）
+	personal := *es
+	personal.config.To = []string{sub.Email}
+	personal.config.ToName = sub.Name
+	personal.config.Language = ""
+
+	var digest *renderedDigest
+	var err error
+	if sub.Format == SubscriberFormatFull {
+		digest, err = personal.renderFullDigest(headlines)
+	} else {
+		digest, err = personal.renderShortDigest(headlines)
+	}
+	if err != nil {
+		return err
+	}
+
+	html := digest.HTML
+	if sub.Body == SubscriberBodyText {
+		html = ""
+	}
+	return personal.SendWithRetry(ctx, personal.buildMessage(digest.Subject, digest.Text, html, true))
+}
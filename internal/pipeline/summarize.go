@@ -0,0 +1,273 @@
+// =============================================================================
+// summarize.go - ローカル抽出型要約
+// =============================================================================
+//
+// このファイルはNotion AIを使わずに、記事本文から300文字以内（日本語は150文字以内）の要約を作成します。
+//
+// 【背景】
+//   - メールダイジェストはNotion AIが "Article Summary 300" を埋めることを前提にしている
+//   - クリップが遅れた記事やNotion AIが失敗した記事は要約が空（または本文のまま）で、
+//     読者に届かなかった
+//
+// 【アルゴリズム（TextRank）】
+//  1. 本文を文に分割（英語 ". " / 日本語 "。"）
+//  2. 各文を語の集合に変換（英語: 小文字化した単語、日本語: 文字バイグラム）
+//  3. 文同士の語の重なりをエッジの重みとしてPageRankを計算
+//  4. スコアの高い文から300文字に収まるだけ選び、元の順序で連結
+//
+// 【使用箇所】
+//   - EnrichHeadlines: Headline.Summary に設定
+//   - ClipHeadline: Notionの "Extracted Summary" プロパティに保存
+//   - SendShortHeadlinesDigest: Article Summary 300 が未生成の場合の代替
+//     （"-"（Notion AIが無関係と判定）の場合は代替しない）
+//
+// =============================================================================
+package pipeline
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SummaryMaxRunes は要約の最大文字数（Article Summary 300 に合わせる）
+const SummaryMaxRunes = 300
+
+// ExtractedSummaryLabel はメール上で機械抽出の要約であることを示すラベル
+const ExtractedSummaryLabel = "[自動要約] "
+
+// summaryStopwords は英語の語の集合から除外する頻出語
+var summaryStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "with": true, "this": true, "from": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "had": true, "its": true,
+	"will": true, "would": true, "could": true, "should": true, "which": true, "their": true,
+	"they": true, "been": true, "also": true, "said": true, "into": true, "than": true, "more": true,
+	"but": true, "not": true, "can": true, "all": true, "our": true, "who": true, "about": true,
+}
+
+// SummarizeExtractive は本文から maxRunes 文字以内の抽出型要約を作成する
+//
+// 本文が maxRunes 以内の場合は空白を正規化してそのまま返す。
+func SummarizeExtractive(text string, maxRunes int) string {
+	text = normalizeWhitespace(text)
+	if text == "" {
+		return ""
+	}
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	sentences := splitSentences(text)
+	if len(sentences) == 0 {
+		return truncateString(text, maxRunes)
+	}
+
+	scores := textRankScores(sentences)
+
+	// スコア順に並べ、文字数に収まる文を選ぶ
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	selected := map[int]bool{}
+	total := 0
+	for _, i := range order {
+		n := utf8.RuneCountInString(sentences[i]) + 1 // 区切りの空白分
+		if total+n > maxRunes {
+			continue
+		}
+		selected[i] = true
+		total += n
+	}
+
+	// 1文も収まらない場合は最高スコアの文を切り詰める
+	if len(selected) == 0 {
+		return truncateString(sentences[order[0]], maxRunes)
+	}
+
+	var sb strings.Builder
+	for i, s := range sentences {
+		if !selected[i] {
+			continue
+		}
+		// 日本語の文同士は空白なしで連結
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "。") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// textRankScores は各文のTextRankスコアを返す
+func textRankScores(sentences []string) []float64 {
+	n := len(sentences)
+	tokens := make([]map[string]bool, n)
+	for i, s := range sentences {
+		tokens[i] = summaryTokens(s)
+	}
+
+	// 類似度行列（Mihalcea & Tarau 2004 の正規化）
+	weights := make([][]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if len(tokens[i]) < 2 || len(tokens[j]) < 2 {
+				continue
+			}
+			overlap := 0
+			for t := range tokens[i] {
+				if tokens[j][t] {
+					overlap++
+				}
+			}
+			if overlap == 0 {
+				continue
+			}
+			w := float64(overlap) / (math.Log(float64(len(tokens[i]))) + math.Log(float64(len(tokens[j]))))
+			weights[i][j], weights[j][i] = w, w
+		}
+	}
+
+	outSum := make([]float64, n)
+	for i := range weights {
+		for _, w := range weights[i] {
+			outSum[i] += w
+		}
+	}
+
+	const damping = 0.85
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iter := 0; iter < 30; iter++ {
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 && outSum[j] > 0 {
+					sum += weights[j][i] / outSum[j] * scores[j]
+				}
+			}
+			next[i] = (1 - damping) + damping*sum
+		}
+		scores = next
+	}
+
+	// ニュース記事は冒頭文（リード）が要点であることが多いため加点
+	scores[0] += 0.15
+	return scores
+}
+
+// summaryTokens は文を語の集合に変換する
+//
+// 英語は3文字以上の単語（ストップワード除外）、日本語・中国語は文字バイグラムを使う。
+func summaryTokens(sentence string) map[string]bool {
+	tokens := map[string]bool{}
+	var word []rune
+	var prevCJK rune
+	flush := func() {
+		if len(word) >= 3 {
+			w := string(word)
+			if !summaryStopwords[w] {
+				tokens[w] = true
+			}
+		}
+		word = word[:0]
+	}
+	for _, r := range strings.ToLower(sentence) {
+		switch {
+		case isCJKRune(r):
+			flush()
+			if prevCJK != 0 {
+				tokens[string([]rune{prevCJK, r})] = true
+			}
+			prevCJK = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevCJK = 0
+	}
+	flush()
+	return tokens
+}
+
+// isUnsummarized はArticle Summary 300がNotion AIで要約されていないかを判定する
+//
+// クリップ時は本文（Extracted Summary の抽出元）をそのまま書き込むため、Notion AIが処理していない場合は
+// 空か、クリップ時の値（Extracted Summary と同じ値、または Extracted Summary の各文を含む本文）のまま残る。
+// 短い本文を要約済みと誤判定しないよう、文字数ではなく値の一致で判定する。
+// Extracted Summary がない古いページは比較できないため、300文字を超える値のみ本文とみなす。
+// "-"（無関係と判定）は要約済みとして扱う。
+func isUnsummarized(h NotionHeadline) bool {
+	s := normalizeWhitespace(h.ShortHeadline)
+	if s == "" {
+		return true
+	}
+	extracted := normalizeWhitespace(h.ExtractedSummary)
+	if extracted == "" {
+		return utf8.RuneCountInString(s) > SummaryMaxRunes
+	}
+	if s == extracted {
+		return true
+	}
+	return containsExtract(s, extracted)
+}
+
+// containsExtract は text が抽出型要約 extracted の抽出元（全文が含まれる本文）かを判定する
+//
+// 抽出型要約は本文の文を元の順序で連結したもの（1文も収まらない場合は切り詰め）のため、
+// 各文がすべて text に含まれていれば text は抽出元の本文とみなせる。
+func containsExtract(text, extracted string) bool {
+	sentences := splitSentences(extracted)
+	if len(sentences) == 0 {
+		return false
+	}
+	pos := 0
+	for _, sentence := range sentences {
+		sentence = strings.TrimSuffix(sentence, "...")
+		i := strings.Index(text[pos:], sentence)
+		if sentence == "" || i < 0 {
+			return false
+		}
+		pos += i + len(sentence)
+	}
+	return true
+}
+
+// applyExtractedSummaries はNotion AIの要約がない記事に抽出型要約を設定する
+//
+// "Extracted Summary" プロパティがあればそれを使い、なければ Article Summary 300 に
+// 残っている本文から要約を作る。置き換えた記事は SummaryExtracted が true になる。
+func applyExtractedSummaries(headlines []NotionHeadline) int {
+	replaced := 0
+	for i := range headlines {
+		h := &headlines[i]
+		if !isUnsummarized(*h) {
+			continue
+		}
+		summary := h.ExtractedSummary
+		if summary == "" {
+			summary = SummarizeExtractive(h.ShortHeadline, SummaryMaxRunesFor(h.Language))
+		}
+		if summary == "" {
+			continue
+		}
+		h.ShortHeadline = summary
+		h.SummaryExtracted = true
+		replaced++
+	}
+	return replaced
+}
@@ -0,0 +1,53 @@
+// =============================================================================
+// topics.go - 記事のトピック分類
+// =============================================================================
+//
+// このファイルは記事をトピック（コンプライアンス市場・ボランタリー市場・6条 等）に分類します。
+// 分類にはキーワードフィルタエンジン（filter.go）の式を使うため、同じ文法で規則を追加できます。
+//
+// 【使用箇所】
+//   - EnrichHeadlines: Headline.Topics に設定
+//   - ClipHeadline:    Notionの "Topics" マルチセレクトとページ冒頭のコールアウトに表示
+//
+// =============================================================================
+package pipeline
+
+// topicRule はトピック名と判定に使うフィルタ式の組
+type topicRule struct {
+	Name string
+	Expr *FilterExpr
+}
+
+// mustParseFilter はパッケージ初期化時の固定式をパースする（文法エラーはプログラムの誤り）
+func mustParseFilter(expr string) *FilterExpr {
+	f, err := ParseFilter(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// topicRules はトピックの判定規則（表示順）
+//
+// トピックを追加した場合は notion_schema.go に "Topics" のオプションを追加するマイグレーションも追加する。
+var topicRules = []topicRule{
+	{"Compliance Markets", mustParseFilter(`ETS OR "emissions trading" OR "cap-and-trade" OR "cap and trade" OR EUA OR UKA OR RGGI OR 排出量取引 OR GX-ETS`)},
+	{"Voluntary Markets", mustParseFilter(`"voluntary carbon" OR VCM OR Verra OR "Gold Standard" OR ICVCM OR VCMI OR "carbon offset" OR ボランタリー OR J-クレジット`)},
+	{"Article 6", mustParseFilter(`"Article 6" OR ITMO OR "corresponding adjustment" OR JCM OR 二国間クレジット OR 6条`)},
+	{"Carbon Removal", mustParseFilter(`"carbon removal" OR CDR OR "direct air capture" OR DAC OR DACCS OR BECCS OR biochar OR "enhanced weathering" OR 炭素除去`)},
+	{"Nature-based", mustParseFilter(`REDD OR forest* OR mangrove* OR "blue carbon" OR "nature-based" OR 森林 OR ブルーカーボン`)},
+	{"CBAM & Trade", mustParseFilter(`CBAM OR "border adjustment" OR "carbon border" OR 国境炭素`)},
+	{"Carbon Pricing", mustParseFilter(`"carbon price" OR "carbon pricing" OR "carbon tax" OR 炭素税 OR カーボンプライシング OR 炭素価格`)},
+	{"Aviation & Shipping", mustParseFilter(`CORSIA OR aviation OR airline* OR shipping OR IMO OR maritime OR 航空 OR 海運`)},
+}
+
+// ClassifyTopics はタイトルと本文に一致するトピックを規則の順に返す
+func ClassifyTopics(title, excerpt string) []string {
+	var topics []string
+	for _, r := range topicRules {
+		if r.Expr.Match(title, excerpt) {
+			topics = append(topics, r.Name)
+		}
+	}
+	return topics
+}
@@ -24,38 +24,62 @@ package pipeline
 //
 // 【フィールドの説明】
 //   Source:      記事のソース名（例: "Carbon Herald", "Carbon Brief"）
+//   SourceID:    ソース識別子（-sources で指定する文字列、例: "carbonherald"。収集時に設定）
 //   Title:       記事のタイトル
 //   URL:         記事のURL
 //   PublishedAt: 公開日時（RFC3339形式、例: "2026-01-05T12:00:00Z"）
 //   Excerpt:     記事の要約・本文テキスト
+//   Entities:    抽出したエンティティ（レジストリ、プロジェクトID等。entities.go）
+//   Prices:      抽出した価格・取引量（prices.go）
+//   Summary:     抽出型要約（300文字以内、summarize.go）
+//   Language:    記事の言語（"ja" / "en"、language.go）
 //
 type Headline struct {
-	Source      string `json:"source"`                // ソース名
-	Title       string `json:"title"`                 // 記事タイトル
-	URL         string `json:"url"`                   // 記事URL
-	PublishedAt string `json:"publishedAt,omitempty"` // 公開日時（RFC3339形式）
-	Excerpt     string `json:"excerpt,omitempty"`     // 要約テキスト
+	Source      string         `json:"source"`                // ソース名
+	SourceID    string         `json:"sourceId,omitempty"`    // ソース識別子（収集時に設定）
+	Title       string         `json:"title"`                 // 記事タイトル
+	URL         string         `json:"url"`                   // 記事URL
+	PublishedAt string         `json:"publishedAt,omitempty"` // 公開日時（RFC3339形式）
+	Excerpt     string         `json:"excerpt,omitempty"`     // 要約テキスト
+	Entities    *Entities      `json:"entities,omitempty"`    // 抽出エンティティ
+	Prices      []PriceMention `json:"prices,omitempty"`      // 価格・取引量
+	Summary     string         `json:"summary,omitempty"`     // 抽出型要約
+	Language    string         `json:"language,omitempty"`    // 言語（ja/en）
+	Authors     []string       `json:"authors,omitempty"`     // 著者（RSSフィード等から取得できた場合）
+	Topics      []string       `json:"topics,omitempty"`      // トピック（topics.go）
 }
 
 // -----------------------------------------------------------------------------
 // NotionHeadline - Notionデータベースから取得した見出し
 // -----------------------------------------------------------------------------
 //
-// Notionデータベースに保存された記事情報を表します。
-// メール送信機能でNotionから記事を取得する際に使用されます。
+// ストア（Notionデータベースまたはローカルファイル、store.go）に保存された記事情報を表します。
+// メール送信機能でストアから記事を取得する際に使用されます。
+// JSONタグはファイルストア（store_file.go）の保存形式です。
 //
 // 【使用場面】
 //   - email.goでNotionから最近の記事を取得してメール本文を生成
 //   - SendShortHeadlinesDigest()でArticle Summary 300メールを送信
 //
 type NotionHeadline struct {
-	Title         string // 記事タイトル
-	URL           string // 記事URL
-	Source        string // ソース名
-	Type          string // 記事タイプ（Academic/News）
-	ShortHeadline string // Article Summary 300（短い要約、Notion AIで生成）
-	PublishedDate string // Published Date（記事の公開日、RFC3339形式）
-	CreatedAt     string // 作成日時（RFC3339形式）
+	ID               string         `json:"id"`                         // ストア上のID（NotionのページID、ファイルストアの連番）
+	Title            string         `json:"title"`                      // 記事タイトル
+	URL              string         `json:"url"`                        // 記事URL
+	Source           string         `json:"source"`                     // ソース名
+	Type             string         `json:"type"`                       // 記事タイプ（Academic/News）
+	ShortHeadline    string         `json:"shortHeadline,omitempty"`    // Article Summary 300（短い要約、Notion AIで生成）
+	ExtractedSummary string         `json:"extractedSummary,omitempty"` // Extracted Summary（クリップ時の抽出型要約）
+	SummaryExtracted bool           `json:"-"`                          // ShortHeadlineを抽出型要約で代替した場合true
+	Language         string         `json:"language,omitempty"`         // 言語（ja/en、Notionの "Language" セレクト）
+	PublishedDate    string         `json:"publishedDate,omitempty"`    // Published Date（記事の公開日、RFC3339形式）
+	CreatedAt        string         `json:"createdAt"`                  // 作成日時（RFC3339形式）
+	Entities         *Entities      `json:"entities,omitempty"`         // 抽出エンティティ（Notionのマルチセレクトから復元）
+	Prices           []PriceMention `json:"prices,omitempty"`           // 価格・取引量（Notionの "Prices" テキストから復元）
+	Authors          []string       `json:"authors,omitempty"`          // 著者（Notionの "Authors" テキストから復元）
+	Topics           []string       `json:"topics,omitempty"`           // トピック（Notionの "Topics" マルチセレクト）
+	Excluded         bool           `json:"excluded,omitempty"`         // 編集者が "Include in digest" のチェックを外した場合true（editorial.go）
+	Priority         string         `json:"priority,omitempty"`         // 編集者が付けた重要度（High/Normal/Low、未設定は空）
+	EditorNote       string         `json:"editorNote,omitempty"`       // 編集者のメモ（Notionの "Editor note" テキスト）
 }
 
 // -----------------------------------------------------------------------------
@@ -299,3 +299,20 @@ func truncateString(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen-3]) + "..."
 }
+
+// chunkRunes は文字列を size 文字ごとに分割する
+//
+// バイト単位で分割するとマルチバイト文字（日本語）の途中で切れて
+// 不正なUTF-8になるため、rune単位で分割する
+func chunkRunes(s string, size int) []string {
+	runes := []rune(s)
+	var chunks []string
+	for i := 0; i < len(runes); i += size {
+		end := i + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[i:end]))
+	}
+	return chunks
+}
@@ -0,0 +1,110 @@
+#!/bin/bash
+# Notion結合テスト（Notion API代替サーバー使用）
+#
+# cmd/notion-fake を起動し、実際のNotionトークンなしで以下を端から端まで確認する:
+#   1. -notionInit        データベース作成（2回目は同じデータベースを再利用すること）
+#   2. -notionClip        ページ作成・本文ブロック追加
+#                         （429とHTMLの502を注入し、再送で全件クリップできること）
+#   3. -listShortHeadlines FetchRecentHeadlines（作成日時フィルタ・ページング）で全件取得できること
+#   4. -notionExport      ページ本文のブロックを含めてエクスポートできること
+#
+# 使い方: ./scripts/notion_e2e.sh
+# 必要なもの: go, curl, jq
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+PORT="${NOTION_FAKE_PORT:-18787}"
+BASE_URL="http://127.0.0.1:$PORT"
+FIXTURE="$ROOT/scripts/testdata/notion_e2e_headlines.json"
+WORK="$(mktemp -d)"
+FAKE_PID=""
+DB_ID=""
+
+cleanup() {
+    if [ -n "$FAKE_PID" ]; then
+        kill "$FAKE_PID" 2>/dev/null || true
+    fi
+    if [ -z "${KEEP_WORK:-}" ]; then
+        rm -rf "$WORK"
+    else
+        echo "📁 作業ディレクトリ: $WORK"
+    fi
+}
+trap cleanup EXIT
+
+fail() {
+    echo "❌ $1"
+    echo "--- pipeline log ---"
+    cat "$WORK"/*.log 2>/dev/null || true
+    exit 1
+}
+
+# パイプラインは作業ディレクトリの .env を読むため、環境変数を引き継がずに実行する
+run_pipeline() {
+    (cd "$WORK" && env -i PATH="$PATH" HOME="$HOME" \
+        NOTION_TOKEN=fake-token NOTION_API_BASE_URL="$BASE_URL" NOTION_RATE_LIMIT=50 \
+        NOTION_DATABASE_ID="$DB_ID" \
+        "$WORK/pipeline" "$@")
+}
+
+echo "========================================="
+echo "🧪 Notion結合テスト（代替サーバー）"
+echo "========================================="
+
+echo "🔨 ビルド中..."
+(cd "$ROOT" && go build -o "$WORK/pipeline" ./cmd/pipeline && go build -o "$WORK/notion-fake" ./cmd/notion-fake)
+
+EXPECTED=$(jq 'length' "$FIXTURE")
+
+# 最初の2リクエストは429、最初のページ作成1回はHTMLの502
+"$WORK/notion-fake" -addr "127.0.0.1:$PORT" -parentPage fake-parent-page -inject429 2 -injectHTML 1 2>"$WORK/fake.log" &
+FAKE_PID=$!
+for _ in $(seq 1 50); do
+    curl -sf "$BASE_URL/_fake/requests" >/dev/null 2>&1 && break
+    sleep 0.1
+done
+curl -sf "$BASE_URL/_fake/requests" >/dev/null || fail "代替サーバーが起動しませんでした"
+
+# 1. データベース作成（IDは stdout）
+#    2回目は NOTION_DATABASE_ID なしで実行し、親ページの既存データベースを再利用すること
+echo "🗂  データベース作成..."
+DB_ID=$(run_pipeline -notionInit -notionPageID fake-parent-page 2>"$WORK/init.log") || fail "データベース作成が失敗しました"
+[ -n "$DB_ID" ] || fail "データベースIDが出力されていません"
+SECOND_ID=$(DB_ID="" run_pipeline -notionInit -notionPageID fake-parent-page \
+    -notionInitWrite "$WORK/notion.env" 2>"$WORK/init2.log") || fail "2回目のデータベース作成が失敗しました"
+[ "$SECOND_ID" = "$DB_ID" ] || fail "2回目に別のデータベースが作成されました: $SECOND_ID（期待値: $DB_ID）"
+grep -qx "NOTION_DATABASE_ID=$DB_ID" "$WORK/notion.env" || fail "-notionInitWrite のファイルにIDが書き込まれていません"
+DATABASES=$(curl -sf "$BASE_URL/_fake/requests" | jq '[.[] | select(. == "POST /v1/databases")] | length')
+[ "$DATABASES" -eq 1 ] || fail "データベース作成リクエスト: $DATABASES（期待値: 1）"
+[ ! -e "$WORK/.env" ] || fail ".env が書き換えられました"
+echo "   ✅ $DB_ID（再実行でも同じID）"
+
+# 2. クリップ（NOTION_DATABASE_ID を指定）
+echo "📎 クリップ（$EXPECTED 件）..."
+run_pipeline -headlines "$FIXTURE" -notionClip \
+    -out "$WORK/headlines.json" 2>"$WORK/clip.log" || fail "クリップが失敗しました"
+
+CLIPPED=$(curl -sf "$BASE_URL/_fake/pages" | jq 'length')
+[ "$CLIPPED" -eq "$EXPECTED" ] || fail "作成されたページ数: $CLIPPED（期待値: $EXPECTED）"
+grep -q "rate limited (429)" "$WORK/init.log" "$WORK/clip.log" || fail "注入した429が再送されていません"
+BLOCKS=$(curl -sf "$BASE_URL/_fake/requests" | jq '[.[] | select(startswith("PATCH") and endswith("/children"))] | length')
+[ "$BLOCKS" -ge "$EXPECTED" ] || fail "本文ブロックの追加リクエスト: $BLOCKS（期待値: $EXPECTED 以上）"
+echo "   ✅ $CLIPPED ページ作成（429・502から回復）"
+
+# 3. 取得（FetchRecentHeadlines）
+echo "📋 取得..."
+run_pipeline -listShortHeadlines 2>"$WORK/list.log" || fail "取得が失敗しました"
+grep -q "Fetched $EXPECTED headlines" "$WORK/list.log" || fail "取得件数が一致しません"
+echo "   ✅ $EXPECTED 件取得"
+
+# 4. エクスポート（本文ブロック付き）
+echo "📤 エクスポート..."
+run_pipeline -notionExport -out "$WORK/export.jsonl" 2>"$WORK/export.log" || fail "エクスポートが失敗しました"
+EXPORTED=$(wc -l <"$WORK/export.jsonl" | tr -d ' ')
+[ "$EXPORTED" -eq "$EXPECTED" ] || fail "エクスポート件数: $EXPORTED（期待値: $EXPECTED）"
+jq -e 'select(.blocks | length > 0)' "$WORK/export.jsonl" >/dev/null || fail "本文ブロックがエクスポートされていません"
+echo "   ✅ $EXPORTED 件エクスポート"
+
+echo ""
+echo "✅ Notion結合テスト成功"